@@ -0,0 +1,150 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+
+	"github.com/encratite/commons"
+	"github.com/fatih/color"
+)
+
+type accountKey struct {
+	exchange string
+	market string
+	account string
+}
+
+func runAccount(arguments []string) {
+	flags := flag.NewFlagSet("account", flag.ExitOnError)
+	strategyFilter := flags.String("strategy", "", "Only include accounts used by strategies whose names match this filter")
+	flags.Parse(arguments)
+	loadConfiguration()
+	keys, strategies := getAccounts(*strategyFilter)
+	if len(keys) == 0 {
+		commons.Fatalf("No strategies with order configurations found")
+	}
+	for _, key := range keys {
+		err := strategies[key][0].printAccount(key)
+		if err != nil {
+			fmt.Printf("\tError: %v\n", err)
+		}
+	}
+	printOpenPositions(*strategyFilter)
+}
+
+// Groups the strategies with order configurations by account, preserving the order of the configuration file
+func getAccounts(filter string) ([]accountKey, map[accountKey][]*Strategy) {
+	keys := []accountKey{}
+	strategies := map[accountKey][]*Strategy{}
+	for i := range configuration.Strategies {
+		strategy := &configuration.Strategies[i]
+		if !strategy.matchesFilter(filter) || strategy.Order == nil {
+			continue
+		}
+		key := strategy.getAccountKey()
+		_, exists := strategies[key]
+		if !exists {
+			keys = append(keys, key)
+		}
+		strategies[key] = append(strategies[key], strategy)
+	}
+	return keys, strategies
+}
+
+// Strategies trading on the same exchange, market and account share balances and open orders
+func (s *Strategy) getAccountKey() accountKey {
+	exchange := s.Order.Exchange
+	if exchange == "" {
+		exchange = exchangeBinance
+	}
+	market := s.Order.Market
+	if market == "" {
+		market = marketSpot
+	}
+	account := s.Order.Account
+	if account == "" {
+		account = defaultAccount
+	}
+	return accountKey{
+		exchange: exchange,
+		market: market,
+		account: account,
+	}
+}
+
+func (s *Strategy) printAccount(key accountKey) error {
+	exchange := key.exchange
+	if isTestnet(key.exchange) {
+		exchange += " testnet"
+	}
+	fmt.Printf("\n%s %s (%s account):\n", exchange, key.market, key.account)
+	executor, err := s.newExecutor()
+	if err != nil {
+		return err
+	}
+	balances, err := executor.GetBalances()
+	if err != nil {
+		return err
+	}
+	sort.Slice(balances, func (i, j int) bool {
+		return balances[i].Asset < balances[j].Asset
+	})
+	fmt.Printf("\tBalances: %d\n", len(balances))
+	for _, balance := range balances {
+		fmt.Printf("\t\t%s: %s free, %s locked\n", balance.Asset, formatDecimal(balance.Free), formatDecimal(balance.Locked))
+	}
+	orders, err := executor.GetOpenOrders("")
+	if err != nil {
+		return fmt.Errorf("failed to retrieve open orders: %v", err)
+	}
+	fmt.Printf("\tOpen orders: %d\n", len(orders))
+	for _, order := range orders {
+		side := "sell"
+		if order.Buy {
+			side = "buy"
+		}
+		fmt.Printf("\t\t%s: %s %s %s", order.Symbol, side, order.Type, formatDecimal(order.Quantity))
+		if order.Price > 0 {
+			fmt.Printf(" at %.4f", order.Price)
+		}
+		fmt.Printf(" (%s, order %s)\n", order.Status, order.ID)
+	}
+	return nil
+}
+
+// Unrealized P&L is based on the last traded price on Binance spot
+func printOpenPositions(filter string) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	state := getState()
+	names := []string{}
+	for name := range state.positions {
+		strategy := findStrategy(name)
+		if strategy == nil || strategy.matchesFilter(filter) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	fmt.Printf("\nOpen positions: %d\n", len(names))
+	total := 0.0
+	for _, name := range names {
+		p := state.positions[name]
+		fmt.Printf("\t%s: %s %s at %.4f since %s UTC", p.Strategy, p.Currency, formatDecimal(p.Quantity), p.EntryPrice, commons.GetTimeString(p.EntryTime))
+		price, err := getLastPrice(p.Currency)
+		if err != nil {
+			fmt.Printf(", %s\n", red(fmt.Sprintf("unable to determine unrealized P&L: %v", err)))
+			continue
+		}
+		pnl := p.getPnL(price)
+		total += pnl
+		output := fmt.Sprintf("%+.2f", pnl)
+		if pnl >= 0 {
+			output = green(output)
+		} else {
+			output = red(output)
+		}
+		fmt.Printf(", last price %.4f, unrealized P&L %s\n", price, output)
+	}
+	fmt.Printf("\tTotal unrealized P&L: %+.2f\n\n", total)
+}
\ No newline at end of file
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	maxCandleMove = 0.5
+)
+
+type candleAnomaly struct {
+	timestamp time.Time
+	reason string
+}
+
+// Removes candles with invalid prices as well as spikes that are immediately reversed by the following candle
+func filterRecords(records []ohlcRecord) ([]ohlcRecord, []candleAnomaly) {
+	output := []ohlcRecord{}
+	anomalies := []candleAnomaly{}
+	for i, record := range records {
+		reason := ""
+		if record.open <= 0 || record.high <= 0 || record.low <= 0 || record.close <= 0 {
+			reason = "zero price"
+		} else if record.high < record.low {
+			reason = "high below low"
+		} else if record.open > record.high || record.open < record.low || record.close > record.high || record.close < record.low {
+			reason = "open or close outside of range"
+		} else if len(output) > 0 {
+			previous := output[len(output) - 1].close
+			if math.Abs(record.close / previous - 1.0) > maxCandleMove {
+				reverted := true
+				if i + 1 < len(records) {
+					next := records[i + 1].close
+					reverted = math.Abs(next / previous - 1.0) <= maxCandleMove
+				}
+				if reverted {
+					reason = fmt.Sprintf("%+.0f%% move", (record.close / previous - 1.0) * percent)
+				}
+			}
+		}
+		if reason != "" {
+			anomaly := candleAnomaly{
+				timestamp: record.timestamp,
+				reason: reason,
+			}
+			anomalies = append(anomalies, anomaly)
+			continue
+		}
+		output = append(output, record)
+	}
+	return output, anomalies
+}
+
+func formatAnomalies(anomalies []candleAnomaly) string {
+	descriptions := []string{}
+	for _, anomaly := range anomalies {
+		description := fmt.Sprintf("%s UTC (%s)", commons.GetTimeString(anomaly.timestamp), anomaly.reason)
+		descriptions = append(descriptions, description)
+	}
+	return strings.Join(descriptions, ", ")
+}
\ No newline at end of file
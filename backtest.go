@@ -0,0 +1,453 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	candleInterval = 5 * time.Minute
+	exitStopLoss = "stop-loss"
+	exitTakeProfit = "take-profit"
+	exitBreakEven = "break-even"
+	exitHold = "hold"
+)
+
+type backtestTrade struct {
+	entryTime time.Time
+	exitTime time.Time
+	entryPrice float64
+	exitPrice float64
+	exitReason string
+	returns float64
+}
+
+type backtestResult struct {
+	strategy *Strategy
+	from time.Time
+	to time.Time
+	records []ohlcRecord
+	trades []backtestTrade
+	suppressed int
+	gapped int
+	anomalies []candleAnomaly
+	gaps []candleGap
+}
+
+func runBacktest(arguments []string) {
+	flags := flag.NewFlagSet("backtest", flag.ExitOnError)
+	strategyFilter := flags.String("strategy", "", "Restrict backtests to strategies whose names match this filter")
+	fromString := flags.String("from", "", "Start date of the backtest (YYYY-MM-DD)")
+	toString := flags.String("to", "", "End date of the backtest (YYYY-MM-DD), defaults to today")
+	portfolio := flags.Bool("portfolio", false, "Backtest all strategies together as a portfolio with shared cash")
+	addBacktestFlags(flags)
+	reportPath := flags.String("report", "", "Write a self-contained HTML report of the backtest to this path")
+	flags.Parse(arguments)
+	from, to := parseDateRange(*fromString, *toString)
+	loadConfiguration()
+	fmt.Printf("\n")
+	results := backtestStrategies(*strategyFilter, from, to)
+	for _, result := range results {
+		result.print()
+	}
+	if *portfolio {
+		portfolioResult := backtestPortfolio(results, from, to)
+		portfolioResult.print()
+	}
+	if *reportPath != "" {
+		writeReport(*reportPath, results, from, to)
+	}
+}
+
+func backtestStrategies(filter string, from time.Time, to time.Time) []backtestResult {
+	strategies := []*Strategy{}
+	for i := range configuration.Strategies {
+		strategy := &configuration.Strategies[i]
+		if !strategy.matchesFilter(filter) {
+			continue
+		}
+		strategies = append(strategies, strategy)
+	}
+	results := make([]backtestResult, len(strategies))
+	indexes := make(chan int)
+	var wait sync.WaitGroup
+	for range max(backtestWorkers, 1) {
+		wait.Add(1)
+		go func () {
+			defer wait.Done()
+			for i := range indexes {
+				results[i] = strategies[i].backtest(from, to)
+			}
+		}()
+	}
+	for i := range strategies {
+		indexes <- i
+	}
+	close(indexes)
+	wait.Wait()
+	return results
+}
+
+func parseDateRange(fromString string, toString string) (time.Time, time.Time) {
+	if fromString == "" {
+		commons.Fatalf("Missing start date")
+	}
+	from, err := time.Parse(time.DateOnly, fromString)
+	if err != nil {
+		commons.Fatalf("Invalid start date: %s", fromString)
+	}
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if toString != "" {
+		to, err = time.Parse(time.DateOnly, toString)
+		if err != nil {
+			commons.Fatalf("Invalid end date: %s", toString)
+		}
+	}
+	if !from.Before(to) {
+		commons.Fatalf("Start date must precede end date")
+	}
+	return from, to
+}
+
+func (s *Strategy) backtest(from time.Time, to time.Time) backtestResult {
+	if s.HoldHours <= 0 {
+		commons.Fatalf("Missing holding period for strategy %s", s.Name)
+	}
+	offset := time.Duration(s.getLookbackHours()) * time.Hour
+	hold := time.Duration(s.HoldHours) * time.Hour
+	records := loadHistoricalRecords(s.Currency, from.Add(-offset - time.Hour), to.Add(hold + time.Hour))
+	s.loadHistoricalReferences(from.Add(-offset - time.Hour), to.Add(time.Hour))
+	s.loadHistoricalTimeframes(from, to.Add(time.Hour))
+	s.loadHistoricalPercentile(from, to.Add(time.Hour))
+	s.gaps = findGaps(records)
+	records, anomalies := filterRecords(records)
+	result := s.backtestRecords(records, from, to)
+	result.anomalies = anomalies
+	result.gaps = s.gaps
+	return result
+}
+
+func (s *Strategy) backtestRecords(records []ohlcRecord, from time.Time, to time.Time) backtestResult {
+	return s.backtestShifted(records, from, to, 0)
+}
+
+func (s *Strategy) backtestShifted(records []ohlcRecord, from time.Time, to time.Time, shift time.Duration) backtestResult {
+	result := backtestResult{
+		strategy: s,
+		from: from,
+		to: to,
+		records: records,
+	}
+	positionExit := time.Time{}
+	pnl := newDailyPnL()
+	for _, day := range s.getLocalDays(from, to) {
+		for _, entryTime := range s.getEntryTimes(day) {
+			if entryTime.Before(from) || !entryTime.Before(to) {
+				continue
+			}
+			entryTime = entryTime.Add(shift)
+			if entryTime.Before(positionExit) {
+				continue
+			}
+			if !s.AllowGaps && len(s.getMomentumGaps(entryTime)) > 0 {
+				result.gapped++
+				continue
+			}
+			trade, ok := s.simulateTrade(records, entryTime)
+			if !ok {
+				continue
+			}
+			if s.isMuted(pnl, entryTime) {
+				result.suppressed++
+				continue
+			}
+			result.trades = append(result.trades, trade)
+			pnl.add(trade.exitTime, trade.returns)
+			positionExit = trade.exitTime
+		}
+	}
+	return result
+}
+
+func (s *Strategy) getMomentumMatch(momentum float64) bool {
+	match := true
+	if s.GreaterThan != nil {
+		match = match && momentum > *s.GreaterThan
+	}
+	if s.LessThan != nil {
+		match = match && momentum < *s.LessThan
+	}
+	return match
+}
+
+func (s *Strategy) getPriceMatch(price float64) bool {
+	match := true
+	if s.PriceAbove != nil {
+		match = match && price > *s.PriceAbove
+	}
+	if s.PriceBelow != nil {
+		match = match && price < *s.PriceBelow
+	}
+	return match
+}
+
+func (s *Strategy) simulateTrade(records []ohlcRecord, entryTime time.Time) (backtestTrade, bool) {
+	entryIndex := findRecord(records, entryTime)
+	anchorIndex := findRecord(records, s.getAnchorTime(entryTime))
+	if entryIndex <= 0 || entryIndex >= len(records) || anchorIndex >= entryIndex {
+		return backtestTrade{}, false
+	}
+	momentum := s.getMomentum(records, anchorIndex, entryIndex - 1)
+	_, consensusMatch := s.getConsensus(records, entryIndex - 1, entryTime, momentum)
+	expressionMatch, err := s.getExpressionMatch(records, entryIndex - 1, entryTime)
+	if err != nil || !expressionMatch {
+		return backtestTrade{}, false
+	}
+	if !s.getPriceMatch(records[entryIndex - 1].close) || !consensusMatch || !s.getMomentumMatch(momentum) || !s.getLookbackMatch(records, entryIndex - 1, entryTime, nil) || !s.getTimeframeMatch(entryTime, nil) || !s.getIndicatorMatch(records, entryIndex - 1, entryTime, nil) || !s.getConditionMatch(records, entryIndex - 1, entryTime) {
+		return backtestTrade{}, false
+	}
+	entryPrice := s.getSlippageFill(s.getEntryFill(records[entryIndex]), true)
+	exitTime := entryTime.Add(time.Duration(s.HoldHours) * time.Hour)
+	stopPrice := math.NaN()
+	if s.StopLoss != nil {
+		stopPrice = s.getExitPrice(entryPrice, -*s.StopLoss)
+	}
+	takeProfitPrice := math.NaN()
+	if s.TakeProfit != nil {
+		takeProfitPrice = s.getExitPrice(entryPrice, *s.TakeProfit)
+	}
+	stopReason := exitStopLoss
+	trade := backtestTrade{
+		entryTime: records[entryIndex].timestamp,
+		entryPrice: entryPrice,
+	}
+	for i := entryIndex; i < len(records); i++ {
+		record := records[i]
+		if !record.timestamp.Before(exitTime) {
+			trade.exitTime = record.timestamp
+			trade.exitPrice = record.open
+			trade.exitReason = exitHold
+			break
+		}
+		// When both the stop and the take-profit are within the range of the same candle, assume the stop was hit first
+		if s.hitStop(record, stopPrice) {
+			trade.exitTime = record.timestamp
+			trade.exitPrice = s.getStopFill(record, stopPrice)
+			trade.exitReason = stopReason
+			break
+		}
+		if s.hitTakeProfit(record, takeProfitPrice) {
+			trade.exitTime = record.timestamp
+			trade.exitPrice = takeProfitPrice
+			trade.exitReason = exitTakeProfit
+			break
+		}
+		// The stop only moves to break-even after the candle that triggered it has closed
+		if stopReason != exitBreakEven && s.breakEvenTriggered(entryPrice, record) {
+			_, stopPrice = s.getBreakEven(entryPrice)
+			stopReason = exitBreakEven
+		}
+	}
+	if trade.exitReason == "" {
+		return backtestTrade{}, false
+	}
+	if trade.exitReason != exitTakeProfit {
+		trade.exitPrice = s.getSlippageFill(trade.exitPrice, false)
+	}
+	trade.returns = s.getReturns(trade.entryPrice, trade.exitPrice)
+	return trade, true
+}
+
+// Entries are filled at the open plus a fraction of the distance to the adverse extreme of the candle
+func (s *Strategy) getEntryFill(record ohlcRecord) float64 {
+	if s.Up {
+		return record.open + configuration.AdverseFill * (record.high - record.open)
+	} else {
+		return record.open - configuration.AdverseFill * (record.open - record.low)
+	}
+}
+
+func findRecord(records []ohlcRecord, timestamp time.Time) int {
+	return sort.Search(len(records), func (i int) bool {
+		return !records[i].timestamp.Before(timestamp)
+	})
+}
+
+func (s *Strategy) getExitPrice(entry float64, change float64) float64 {
+	if s.Up {
+		return entry * (1.0 + change / percent)
+	} else {
+		return entry * (1.0 - change / percent)
+	}
+}
+
+func (s *Strategy) getReturns(entry float64, exit float64) float64 {
+	fees := 2.0 * configuration.Fees / percent
+	if s.Up {
+		return exit / entry - 1.0 - fees
+	} else {
+		return 1.0 - exit / entry - fees
+	}
+}
+
+func (s *Strategy) hitStop(record ohlcRecord, stopPrice float64) bool {
+	if math.IsNaN(stopPrice) {
+		return false
+	}
+	if s.Up {
+		return record.low <= stopPrice
+	} else {
+		return record.high >= stopPrice
+	}
+}
+
+func (s *Strategy) getStopFill(record ohlcRecord, stopPrice float64) float64 {
+	// Price gapped through the stop, so the fill can't be any better than the open
+	if s.Up {
+		return math.Min(stopPrice, record.open)
+	} else {
+		return math.Max(stopPrice, record.open)
+	}
+}
+
+func (s *Strategy) hitTakeProfit(record ohlcRecord, takeProfitPrice float64) bool {
+	if math.IsNaN(takeProfitPrice) {
+		return false
+	}
+	if s.Up {
+		return record.high >= takeProfitPrice
+	} else {
+		return record.low <= takeProfitPrice
+	}
+}
+
+func (r *backtestResult) getEquityCurve() []float64 {
+	equity := 1.0
+	curve := []float64{equity}
+	for _, trade := range r.trades {
+		equity *= 1.0 + trade.returns
+		curve = append(curve, equity)
+	}
+	return curve
+}
+
+func (r *backtestResult) totalReturn() float64 {
+	curve := r.getEquityCurve()
+	return curve[len(curve) - 1] - 1.0
+}
+
+func (r *backtestResult) maxDrawdown() float64 {
+	return getMaxDrawdown(r.getEquityCurve())
+}
+
+func getMaxDrawdown(curve []float64) float64 {
+	peak := 0.0
+	maxDrawdown := 0.0
+	for _, equity := range curve {
+		peak = math.Max(peak, equity)
+		drawdown := equity / peak - 1.0
+		maxDrawdown = math.Min(maxDrawdown, drawdown)
+	}
+	return maxDrawdown
+}
+
+func (r *backtestResult) getReturns() []float64 {
+	returns := []float64{}
+	for _, trade := range r.trades {
+		returns = append(returns, trade.returns)
+	}
+	return returns
+}
+
+func (r *backtestResult) meanReturn() float64 {
+	return getMean(r.getReturns())
+}
+
+func (r *backtestResult) winRate() float64 {
+	if len(r.trades) == 0 {
+		return math.NaN()
+	}
+	wins := 0
+	for _, trade := range r.trades {
+		if trade.returns > 0 {
+			wins++
+		}
+	}
+	return float64(wins) / float64(len(r.trades))
+}
+
+// Annualized based on the number of trades per year in the backtest period
+func (r *backtestResult) sharpeRatio() float64 {
+	returns := r.getReturns()
+	if len(returns) < 2 {
+		return math.NaN()
+	}
+	deviation := getStandardDeviation(returns)
+	if deviation == 0 {
+		return math.NaN()
+	}
+	years := r.to.Sub(r.from).Hours() / (24.0 * 365.0)
+	tradesPerYear := float64(len(returns)) / years
+	return getMean(returns) / deviation * math.Sqrt(tradesPerYear)
+}
+
+func (r *backtestResult) getExitCounts() string {
+	reasons := []string{exitStopLoss, exitTakeProfit, exitBreakEven, exitHold}
+	counts := []string{}
+	for _, reason := range reasons {
+		count := 0
+		for _, trade := range r.trades {
+			if trade.exitReason == reason {
+				count++
+			}
+		}
+		if count > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", count, reason))
+		}
+	}
+	return strings.Join(counts, ", ")
+}
+
+func (r *backtestResult) print() {
+	fmt.Printf("%s:\n", r.strategy.Name)
+	fmt.Printf("\tCurrency: %s\n", r.strategy.Currency)
+	fmt.Printf("\tPeriod: %s - %s\n", r.from.Format(time.DateOnly), r.to.Format(time.DateOnly))
+	if len(r.anomalies) > 0 {
+		fmt.Printf("\tFiltered candles: %d\n", len(r.anomalies))
+	}
+	if len(r.gaps) > 0 {
+		missing := 0
+		for _, gap := range r.gaps {
+			missing += gap.getMissing()
+		}
+		fmt.Printf("\tMissing candles: %d in %d gaps\n", missing, len(r.gaps))
+	}
+	if r.gapped > 0 {
+		fmt.Printf("\tEntries skipped due to gaps: %d\n", r.gapped)
+	}
+	fmt.Printf("\tTrades: %d\n", len(r.trades))
+	if r.suppressed > 0 {
+		fmt.Printf("\tSuppressed signals: %d\n", r.suppressed)
+	}
+	if len(r.trades) > 0 {
+		fmt.Printf("\tWin rate: %.2f%%\n", r.winRate() * percent)
+		fmt.Printf("\tMean return: %+.2f%%\n", r.meanReturn() * percent)
+		if len(r.trades) >= 2 {
+			bootstrap := getBootstrap(r.getReturns())
+			bootstrap.print()
+		}
+		fmt.Printf("\tTotal return: %+.2f%%\n", r.totalReturn() * percent)
+		fmt.Printf("\tMax drawdown: %.2f%%\n", r.maxDrawdown() * percent)
+		fmt.Printf("\tSharpe ratio: %.2f\n", r.sharpeRatio())
+		fmt.Printf("\tExits: %s\n", r.getExitCounts())
+	}
+	fmt.Printf("\n")
+}
\ No newline at end of file
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+// Candles of a day starting at midnight UTC: flat at 100, rising to 101 in the hour before the entry at noon, followed by the candles of the test and a flat price afterwards
+func getBacktestRecords(day time.Time, after []ohlcRecord) []ohlcRecord {
+	entryTime := day.Add(12 * time.Hour)
+	records := []ohlcRecord{}
+	price := 100.0
+	for timestamp := day; timestamp.Before(day.AddDate(0, 0, 1)); timestamp = timestamp.Add(candleInterval) {
+		record := ohlcRecord{
+			timestamp: timestamp,
+			open: price,
+			high: price,
+			low: price,
+			close: price,
+		}
+		if !timestamp.Before(entryTime.Add(-time.Hour)) && timestamp.Before(entryTime) {
+			rising := timestamp.Sub(entryTime.Add(-time.Hour)) / candleInterval + 1
+			record.close = 100 + float64(rising) / float64(candlesPerHour)
+			record.high = record.close
+		} else if i := int(timestamp.Sub(entryTime) / candleInterval); i >= 0 && i < len(after) {
+			record = after[i]
+			record.timestamp = timestamp
+		}
+		price = record.close
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestBacktest(t *testing.T) {
+	day := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	onePercent := 1.0
+	twoPercent := 2.0
+	zero := 0.0
+	fivePercent := 5.0
+	tests := []struct {
+		name string
+		greaterThan *float64
+		stopLoss *float64
+		takeProfit *float64
+		breakEven *float64
+		times []time.Duration
+		after []ohlcRecord
+		trades int
+		exitReason string
+		exitMinutes int
+		exitPrice float64
+		returns float64
+	}{
+		{
+			name: "hold",
+			after: []ohlcRecord{{open: 101, high: 101.6, low: 100.9, close: 101.5}},
+			trades: 1,
+			exitReason: exitHold,
+			exitMinutes: 120,
+			exitPrice: 101.5,
+			returns: 101.5 / 101 - 1 - 0.002,
+		},
+		{
+			name: "momentum mismatch",
+			greaterThan: &fivePercent,
+			trades: 0,
+		},
+		{
+			name: "overlapping entry skipped",
+			times: []time.Duration{12 * time.Hour, 13 * time.Hour},
+			trades: 1,
+			exitReason: exitHold,
+			exitMinutes: 120,
+			exitPrice: 101,
+			returns: -0.002,
+		},
+		{
+			name: "stop loss",
+			stopLoss: &onePercent,
+			after: []ohlcRecord{
+				{open: 101, high: 101.2, low: 100.5, close: 100.6},
+				{open: 100.6, high: 100.7, low: 99.5, close: 99.8},
+			},
+			trades: 1,
+			exitReason: exitStopLoss,
+			exitMinutes: 5,
+			exitPrice: 99.99,
+			returns: 99.99 / 101 - 1 - 0.002,
+		},
+		{
+			name: "stop loss gapped through",
+			stopLoss: &onePercent,
+			after: []ohlcRecord{
+				{open: 101, high: 101.2, low: 100.5, close: 100.6},
+				{open: 99, high: 99.2, low: 98.8, close: 99},
+			},
+			trades: 1,
+			exitReason: exitStopLoss,
+			exitMinutes: 5,
+			exitPrice: 99,
+			returns: 99.0 / 101 - 1 - 0.002,
+		},
+		{
+			name: "take profit",
+			stopLoss: &onePercent,
+			takeProfit: &twoPercent,
+			after: []ohlcRecord{
+				{open: 101, high: 101.5, low: 100.8, close: 101.4},
+				{open: 101.4, high: 103.5, low: 101.3, close: 103.2},
+			},
+			trades: 1,
+			exitReason: exitTakeProfit,
+			exitMinutes: 5,
+			exitPrice: 103.02,
+			returns: 103.02 / 101 - 1 - 0.002,
+		},
+		{
+			name: "stop before take profit within the same candle",
+			stopLoss: &onePercent,
+			takeProfit: &twoPercent,
+			after: []ohlcRecord{{open: 101, high: 103.5, low: 99.5, close: 101}},
+			trades: 1,
+			exitReason: exitStopLoss,
+			exitMinutes: 0,
+			exitPrice: 99.99,
+			returns: 99.99 / 101 - 1 - 0.002,
+		},
+		{
+			name: "break-even",
+			stopLoss: &onePercent,
+			breakEven: &onePercent,
+			after: []ohlcRecord{
+				{open: 101, high: 102.5, low: 101.3, close: 102.2},
+				{open: 102.2, high: 102.3, low: 101, close: 101.1},
+			},
+			trades: 1,
+			exitReason: exitBreakEven,
+			exitMinutes: 5,
+			exitPrice: 101.202,
+			returns: 0,
+		},
+		{
+			name: "break-even only after the triggering candle",
+			stopLoss: &onePercent,
+			breakEven: &onePercent,
+			after: []ohlcRecord{
+				{open: 101, high: 102.5, low: 100.5, close: 102},
+			},
+			trades: 1,
+			exitReason: exitHold,
+			exitMinutes: 120,
+			exitPrice: 102,
+			returns: 102.0 / 101 - 1 - 0.002,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func (t *testing.T) {
+			setupEventLog(t)
+			configuration = &Configuration{
+				Fees: 0.1,
+			}
+			greaterThan := test.greaterThan
+			if greaterThan == nil {
+				greaterThan = &zero
+			}
+			times := test.times
+			if times == nil {
+				times = []time.Duration{12 * time.Hour}
+			}
+			s := &Strategy{
+				Name: "backtest",
+				Currency: "BTCUSDT",
+				Offset: MomentumOffset{Duration: time.Hour},
+				GreaterThan: greaterThan,
+				Weekdays: []commons.SerializableWeekday{{Weekday: time.Monday}},
+				Up: true,
+				StopLoss: test.stopLoss,
+				TakeProfit: test.takeProfit,
+				BreakEven: test.breakEven,
+				HoldHours: 2,
+			}
+			for _, timeOfDay := range times {
+				s.Times = append(s.Times, commons.SerializableDuration{Duration: timeOfDay})
+			}
+			records := getBacktestRecords(day, test.after)
+			result := s.backtestRecords(records, day, day.AddDate(0, 0, 1))
+			if len(result.trades) != test.trades {
+				t.Fatalf("expected %d trades, got %d", test.trades, len(result.trades))
+			}
+			if test.trades == 0 {
+				return
+			}
+			trade := result.trades[0]
+			entryTime := day.Add(12 * time.Hour)
+			if !trade.entryTime.Equal(entryTime) || trade.entryPrice != 101 {
+				t.Errorf("unexpected entry at %.4f (%s)", trade.entryPrice, trade.entryTime)
+			}
+			exitTime := entryTime.Add(time.Duration(test.exitMinutes) * time.Minute)
+			if trade.exitReason != test.exitReason || !trade.exitTime.Equal(exitTime) {
+				t.Errorf("expected exit %s at %s, got %s at %s", test.exitReason, exitTime, trade.exitReason, trade.exitTime)
+			}
+			if math.Abs(trade.exitPrice - test.exitPrice) > 1e-9 {
+				t.Errorf("expected exit price %.4f, got %.4f", test.exitPrice, trade.exitPrice)
+			}
+			if math.Abs(trade.returns - test.returns) > 1e-9 {
+				t.Errorf("expected returns %.6f, got %.6f", test.returns, trade.returns)
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type binanceAccount struct {
+	Balances []binanceBalance `json:"balances"`
+}
+
+type binanceBalance struct {
+	Asset string `json:"asset"`
+	Free string `json:"free"`
+	Locked string `json:"locked"`
+}
+
+type binanceFuturesBalance struct {
+	Asset string `json:"asset"`
+	Balance string `json:"balance"`
+	AvailableBalance string `json:"availableBalance"`
+}
+
+func (c *binanceClient) getAvailableBalance(asset string) (float64, error) {
+	available := ""
+	if c.futures {
+		var balances []binanceFuturesBalance
+		err := c.signedRequest(http.MethodGet, "/fapi/v2/balance", url.Values{}, &balances)
+		if err != nil {
+			return 0, fmt.Errorf("failed to retrieve futures balance: %v", err)
+		}
+		for _, balance := range balances {
+			if balance.Asset == asset {
+				available = balance.AvailableBalance
+			}
+		}
+	} else {
+		var account binanceAccount
+		err := c.signedRequest(http.MethodGet, "/api/v3/account", url.Values{}, &account)
+		if err != nil {
+			return 0, fmt.Errorf("failed to retrieve account balance: %v", err)
+		}
+		for _, balance := range account.Balances {
+			if balance.Asset == asset {
+				available = balance.Free
+			}
+		}
+	}
+	if available == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseFloat(available, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid balance for %s: %s", asset, available)
+	}
+	return value, nil
+}
+
+// Only assets with a non-zero balance are returned
+func (c *binanceClient) getBalances() ([]Balance, error) {
+	output := []Balance{}
+	parse := func (value string) float64 {
+		parsed, _ := strconv.ParseFloat(value, 64)
+		return parsed
+	}
+	if c.futures {
+		var balances []binanceFuturesBalance
+		err := c.signedRequest(http.MethodGet, "/fapi/v2/balance", url.Values{}, &balances)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve futures balance: %v", err)
+		}
+		for _, balance := range balances {
+			total := parse(balance.Balance)
+			available := parse(balance.AvailableBalance)
+			if total != 0 {
+				output = append(output, Balance{
+					Asset: balance.Asset,
+					Free: available,
+					Locked: total - available,
+				})
+			}
+		}
+	} else {
+		var account binanceAccount
+		err := c.signedRequest(http.MethodGet, "/api/v3/account", url.Values{}, &account)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve account balance: %v", err)
+		}
+		for _, balance := range account.Balances {
+			free := parse(balance.Free)
+			locked := parse(balance.Locked)
+			if free != 0 || locked != 0 {
+				output = append(output, Balance{
+					Asset: balance.Asset,
+					Free: free,
+					Locked: locked,
+				})
+			}
+		}
+	}
+	return output, nil
+}
+
+// On futures the percentage refers to the margin, the notional value of the position also depends on the leverage
+func (s *Strategy) getBalanceNotional(executor Executor, filters symbolFilters) (float64, error) {
+	balance, err := executor.GetBalance(filters.quoteAsset)
+	if err != nil {
+		return 0, err
+	}
+	notional := balance * *s.Order.BalancePercentage / percent
+	if s.Order.isFutures() && s.Order.Leverage > 0 {
+		notional *= float64(s.Order.Leverage)
+	}
+	return notional, nil
+}
\ No newline at end of file
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestBalancePercentage(t *testing.T) {
+	tests := []struct {
+		name string
+		market string
+		leverage int
+		balance float64
+		percentage float64
+		quantity float64
+		valid bool
+	}{
+		{"spot", marketSpot, 0, 1000, 10, 0.0033, false},
+		{"spot above the minimum notional", marketSpot, 0, 1000, 50, 0.0166, true},
+		{"futures without leverage", marketFutures, 0, 1000, 50, 0.0166, true},
+		{"futures with leverage", marketFutures, 3, 1000, 10, 0.01, true},
+		{"empty balance", marketSpot, 0, 0, 10, 0, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func (t *testing.T) {
+			percentage := test.percentage
+			s := &Strategy{
+				Name: "balance",
+				Currency: "BTCUSDT",
+				Up: true,
+				Order: &OrderConfiguration{
+					Type: orderLimit,
+					Market: test.market,
+					Leverage: test.leverage,
+					BalancePercentage: &percentage,
+				},
+			}
+			filters := symbolFilters{
+				quoteAsset: "USDT",
+				stepSize: "0.0001",
+				minQuantity: 0.0001,
+				minNotional: 100,
+			}
+			executor := &fakeExecutor{
+				filters: filters,
+				balances: map[string]float64{"USDT": test.balance},
+			}
+			request := OrderRequest{
+				Symbol: s.Currency,
+				Price: 30000,
+			}
+			err := s.setOrderQuantity(executor, filters, &request)
+			if (err == nil) != test.valid {
+				t.Errorf("expected valid to be %t, got %v", test.valid, err)
+			}
+			if !isClose(request.Quantity, test.quantity) {
+				t.Errorf("expected a quantity of %s, got %s", formatDecimal(test.quantity), formatDecimal(request.Quantity))
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -0,0 +1,501 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	binanceSpotURL = "https://api.binance.com"
+	binanceFuturesURL = "https://fapi.binance.com"
+	binanceSpotTestnetURL = "https://testnet.binance.vision"
+	binanceFuturesTestnetURL = "https://testnet.binancefuture.com"
+	binanceReceiveWindow = "5000"
+	binanceUnknownOrder = -2011
+)
+
+type binanceClient struct {
+	baseURL string
+	futures bool
+	apiKey string
+	apiSecret string
+	dryRunRequests []string
+}
+
+type binanceError struct {
+	Code int `json:"code"`
+	Message string `json:"msg"`
+}
+
+type binanceOrderListResponse struct {
+	OrderListID int64 `json:"orderListId"`
+	Orders []binanceOrderResponse `json:"orderReports"`
+}
+
+type binanceOrderResponse struct {
+	Symbol string `json:"symbol"`
+	OrderID int64 `json:"orderId"`
+	ClientOrderID string `json:"clientOrderId"`
+	Status string `json:"status"`
+	Type string `json:"type"`
+	Side string `json:"side"`
+	Price string `json:"price"`
+	OriginalQuantity string `json:"origQty"`
+	ExecutedQuantity string `json:"executedQty"`
+	CumulativeQuoteQuantity string `json:"cummulativeQuoteQty"`
+	CumulativeQuote string `json:"cumQuote"`
+	AveragePrice string `json:"avgPrice"`
+}
+
+// Testnet accounts have separate API keys, which are stored under their own exchange name
+func newBinanceClient(futures bool, account string) (*binanceClient, error) {
+	baseURL := binanceSpotURL
+	if futures {
+		baseURL = binanceFuturesURL
+	}
+	exchange := exchangeBinance
+	if isTestnet(exchangeBinance) {
+		baseURL = binanceSpotTestnetURL
+		if futures {
+			baseURL = binanceFuturesTestnetURL
+		}
+		exchange = exchangeBinanceTestnet
+	}
+	c, err := getCredential(exchange, account)
+	if err != nil {
+		return nil, err
+	}
+	client := &binanceClient{
+		baseURL: baseURL,
+		futures: futures,
+		apiKey: c.APIKey,
+		apiSecret: c.APISecret,
+	}
+	return client, nil
+}
+
+// In dry-run mode requests that would modify the account are recorded instead of being sent
+func (c *binanceClient) signedRequest(method string, path string, parameters url.Values, output any) error {
+	if dryRun && method != http.MethodGet {
+		request := fmt.Sprintf("%s %s%s %s", method, c.baseURL, path, parameters.Encode())
+		c.dryRunRequests = append(c.dryRunRequests, request)
+		return nil
+	}
+	parameters.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli(), 10))
+	parameters.Set("recvWindow", binanceReceiveWindow)
+	query := parameters.Encode()
+	mac := hmac.New(sha256.New, []byte(c.apiSecret))
+	mac.Write([]byte(query))
+	signature := hex.EncodeToString(mac.Sum(nil))
+	requestURL := fmt.Sprintf("%s%s?%s&signature=%s", c.baseURL, path, query, signature)
+	return c.request(method, path, requestURL, true, output)
+}
+
+func (c *binanceClient) publicRequest(path string, parameters url.Values, output any) error {
+	requestURL := fmt.Sprintf("%s%s?%s", c.baseURL, path, parameters.Encode())
+	return c.request(http.MethodGet, path, requestURL, false, output)
+}
+
+func (c *binanceClient) request(method string, path string, requestURL string, signed bool, output any) error {
+	request, err := http.NewRequest(method, requestURL, nil)
+	if err != nil {
+		return err
+	}
+	if signed {
+		request.Header.Set("X-MBX-APIKEY", c.apiKey)
+	}
+	start := time.Now()
+	response, err := http.DefaultClient.Do(request)
+	recordFetch(providerBinance, time.Since(start), err)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		var apiError binanceError
+		err = json.Unmarshal(body, &apiError)
+		if err != nil || apiError.Message == "" {
+			return fmt.Errorf("%s %s failed with status %d", method, path, response.StatusCode)
+		}
+		return fmt.Errorf("%s %s failed: %w", method, path, &apiError)
+	}
+	if output == nil {
+		return nil
+	}
+	return json.Unmarshal(body, output)
+}
+
+func (e *binanceError) Error() string {
+	return fmt.Sprintf("%s (%d)", e.Message, e.Code)
+}
+
+func (c *binanceClient) placeOrder(parameters url.Values) (binanceOrderResponse, error) {
+	var response binanceOrderResponse
+	parameters.Set("newOrderRespType", "RESULT")
+	path := "/api/v3/order"
+	if c.futures {
+		path = "/fapi/v1/order"
+	}
+	err := c.signedRequest(http.MethodPost, path, parameters, &response)
+	return response, err
+}
+
+func (c *binanceClient) placeOrderList(parameters url.Values) (binanceOrderListResponse, error) {
+	var response binanceOrderListResponse
+	parameters.Set("newOrderRespType", "RESULT")
+	err := c.signedRequest(http.MethodPost, "/api/v3/orderList/oco", parameters, &response)
+	return response, err
+}
+
+func (c *binanceClient) getOrder(symbol string, orderID string) (binanceOrderResponse, error) {
+	var response binanceOrderResponse
+	parameters := url.Values{}
+	parameters.Set("symbol", symbol)
+	parameters.Set("orderId", orderID)
+	path := "/api/v3/order"
+	if c.futures {
+		path = "/fapi/v1/order"
+	}
+	err := c.signedRequest(http.MethodGet, path, parameters, &response)
+	return response, err
+}
+
+func (c *binanceClient) cancelOrder(symbol string, orderID string) error {
+	parameters := url.Values{}
+	parameters.Set("symbol", symbol)
+	parameters.Set("orderId", orderID)
+	path := "/api/v3/order"
+	if c.futures {
+		path = "/fapi/v1/order"
+	}
+	return c.signedRequest(http.MethodDelete, path, parameters, nil)
+}
+
+func (r *binanceOrderResponse) getExecutedQuantity() float64 {
+	quantity, _ := strconv.ParseFloat(r.ExecutedQuantity, 64)
+	return quantity
+}
+
+// Futures responses include the average price directly and use a different name for the quote quantity
+func (r *binanceOrderResponse) getAveragePrice() float64 {
+	averagePrice, _ := strconv.ParseFloat(r.AveragePrice, 64)
+	if averagePrice > 0 {
+		return averagePrice
+	}
+	quantity := r.getExecutedQuantity()
+	quoteString := r.CumulativeQuoteQuantity
+	if quoteString == "" {
+		quoteString = r.CumulativeQuote
+	}
+	quoteQuantity, _ := strconv.ParseFloat(quoteString, 64)
+	if quantity == 0 {
+		return 0
+	}
+	return quoteQuantity / quantity
+}
+
+func formatDecimal(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+type binanceExecutor struct {
+	client *binanceClient
+	futures bool
+}
+
+func newBinanceExecutor(order *OrderConfiguration) (*binanceExecutor, error) {
+	client, err := newBinanceClient(order.isFutures(), order.Account)
+	if err != nil {
+		return nil, err
+	}
+	executor := &binanceExecutor{
+		client: client,
+		futures: order.isFutures(),
+	}
+	return executor, nil
+}
+
+func (e *binanceExecutor) Configure(symbol string, order *OrderConfiguration) error {
+	if !e.futures {
+		return nil
+	}
+	return e.client.configureFutures(symbol, order)
+}
+
+func (e *binanceExecutor) GetSymbolFilters(symbol string) (symbolFilters, error) {
+	return e.client.getSymbolFilters(symbol)
+}
+
+func (e *binanceExecutor) GetBalance(asset string) (float64, error) {
+	return e.client.getAvailableBalance(asset)
+}
+
+func (e *binanceExecutor) GetBalances() ([]Balance, error) {
+	return e.client.getBalances()
+}
+
+// An empty symbol retrieves the open orders of all symbols
+func (e *binanceExecutor) GetOpenOrders(symbol string) ([]Order, error) {
+	var responses []binanceOrderResponse
+	parameters := url.Values{}
+	if symbol != "" {
+		parameters.Set("symbol", symbol)
+	}
+	path := "/api/v3/openOrders"
+	if e.futures {
+		path = "/fapi/v1/openOrders"
+	}
+	err := e.client.signedRequest(http.MethodGet, path, parameters, &responses)
+	if err != nil {
+		return nil, err
+	}
+	orders := []Order{}
+	for _, response := range responses {
+		orders = append(orders, response.getOrder())
+	}
+	return orders, nil
+}
+
+func (e *binanceExecutor) GetOrder(symbol string, orderID string) (Order, error) {
+	response, err := e.client.getOrder(symbol, orderID)
+	return response.getOrder(), err
+}
+
+func (e *binanceExecutor) FindOrder(symbol string, clientOrderID string) (*Order, error) {
+	response, err := e.client.findOrder(symbol, clientOrderID)
+	if response == nil || err != nil {
+		return nil, err
+	}
+	order := response.getOrder()
+	return &order, nil
+}
+
+func (e *binanceExecutor) PlaceOrder(request OrderRequest) (Order, error) {
+	parameters, err := e.getOrderParameters(request)
+	if err != nil {
+		return Order{}, err
+	}
+	response, err := e.client.placeOrder(parameters)
+	return response.getOrder(), err
+}
+
+// The take-profit leg is a limit maker order on the profitable side and the stop-loss leg triggers a market order
+func (e *binanceExecutor) PlaceOCO(stopLoss OrderRequest, takeProfit OrderRequest) ([]Order, error) {
+	if e.futures {
+		return nil, fmt.Errorf("OCO orders are not supported on futures")
+	}
+	filters, err := e.client.getSymbolFilters(stopLoss.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	parameters := url.Values{}
+	parameters.Set("symbol", stopLoss.Symbol)
+	parameters.Set("side", getBinanceSide(stopLoss.Buy))
+	parameters.Set("quantity", filters.formatQuantity(stopLoss.Quantity))
+	if stopLoss.Buy {
+		parameters.Set("aboveType", "STOP_LOSS")
+		parameters.Set("aboveStopPrice", filters.formatPrice(stopLoss.StopPrice))
+		parameters.Set("aboveClientOrderId", stopLoss.ClientOrderID)
+		parameters.Set("belowType", "LIMIT_MAKER")
+		parameters.Set("belowPrice", filters.formatPrice(takeProfit.StopPrice))
+		parameters.Set("belowClientOrderId", takeProfit.ClientOrderID)
+	} else {
+		parameters.Set("aboveType", "LIMIT_MAKER")
+		parameters.Set("abovePrice", filters.formatPrice(takeProfit.StopPrice))
+		parameters.Set("aboveClientOrderId", takeProfit.ClientOrderID)
+		parameters.Set("belowType", "STOP_LOSS")
+		parameters.Set("belowStopPrice", filters.formatPrice(stopLoss.StopPrice))
+		parameters.Set("belowClientOrderId", stopLoss.ClientOrderID)
+	}
+	response, err := e.client.placeOrderList(parameters)
+	if err != nil {
+		return nil, err
+	}
+	orders := []Order{}
+	for _, report := range response.Orders {
+		orders = append(orders, report.getOrder())
+	}
+	return orders, nil
+}
+
+func (e *binanceExecutor) CancelOrder(symbol string, orderID string) error {
+	err := e.client.cancelOrder(symbol, orderID)
+	var apiError *binanceError
+	if errors.As(err, &apiError) && apiError.Code == binanceUnknownOrder {
+		return nil
+	}
+	return err
+}
+
+// Futures exits are reduce-only and never exceed the position on the exchange, so they can't flip it to the opposite side, spot exits never exceed the free balance
+func (e *binanceExecutor) ClosePosition(p *position, clientOrderID string) (Order, error) {
+	request := OrderRequest{
+		Symbol: p.Currency,
+		ClientOrderID: clientOrderID,
+		Buy: !p.Up,
+		Type: orderMarket,
+		Quantity: p.Quantity,
+		ReduceOnly: e.futures,
+	}
+	if e.futures {
+		exchangePosition, err := e.client.getPosition(p.Currency)
+		if err != nil {
+			return Order{}, err
+		}
+		amount := exchangePosition.Amount
+		if amount == 0 || (amount > 0) != p.Up {
+			return Order{}, fmt.Errorf("expected a %s position for %s on the exchange but it is %s", getPositionSide(getSignedQuantity(p)), p.Currency, getPositionSide(amount))
+		}
+		request.Quantity = math.Min(request.Quantity, math.Abs(amount))
+	} else {
+		filters, err := e.GetSymbolFilters(p.Currency)
+		if err != nil {
+			return Order{}, err
+		}
+		balance, err := e.GetBalance(filters.baseAsset)
+		if err != nil {
+			return Order{}, err
+		}
+		request.Quantity = getSpotExitQuantity(p.Quantity, balance, filters)
+		if request.Quantity <= 0 {
+			return Order{}, fmt.Errorf("no free %s balance to sell", filters.baseAsset)
+		}
+	}
+	return e.PlaceOrder(request)
+}
+
+// Commissions paid in the base asset on entry leave less than the filled quantity in the account
+func getSpotExitQuantity(quantity float64, balance float64, filters symbolFilters) float64 {
+	rounded, _ := strconv.ParseFloat(filters.formatQuantity(math.Min(quantity, balance)), 64)
+	return rounded
+}
+
+// Spot markets have no positions, the amount is always zero
+func (e *binanceExecutor) GetPosition(symbol string) (ExchangePosition, error) {
+	if !e.futures {
+		return ExchangePosition{}, nil
+	}
+	return e.client.getPosition(symbol)
+}
+
+func (e *binanceExecutor) SupportsOCO() bool {
+	return !e.futures
+}
+
+func (e *binanceExecutor) DryRunRequests() []string {
+	return e.client.dryRunRequests
+}
+
+// Spot take-profits are resting limit orders while futures use conditional orders triggered by the mark price
+func (e *binanceExecutor) getOrderParameters(request OrderRequest) (url.Values, error) {
+	filters, err := e.client.getSymbolFilters(request.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	parameters := url.Values{}
+	parameters.Set("symbol", request.Symbol)
+	if request.ClientOrderID != "" {
+		parameters.Set("newClientOrderId", request.ClientOrderID)
+	}
+	parameters.Set("side", getBinanceSide(request.Buy))
+	switch request.Type {
+	case orderMarket:
+		parameters.Set("type", "MARKET")
+	case orderLimit:
+		parameters.Set("type", "LIMIT")
+		parameters.Set("timeInForce", "GTC")
+		parameters.Set("price", filters.formatPrice(request.Price))
+	case orderStopLoss:
+		if e.futures {
+			parameters.Set("type", "STOP_MARKET")
+			parameters.Set("workingType", "MARK_PRICE")
+		} else {
+			parameters.Set("type", "STOP_LOSS")
+		}
+		parameters.Set("stopPrice", filters.formatPrice(request.StopPrice))
+	case orderTakeProfit:
+		if e.futures {
+			parameters.Set("type", "TAKE_PROFIT_MARKET")
+			parameters.Set("workingType", "MARK_PRICE")
+			parameters.Set("stopPrice", filters.formatPrice(request.StopPrice))
+		} else {
+			parameters.Set("type", "LIMIT")
+			parameters.Set("timeInForce", "GTC")
+			parameters.Set("price", filters.formatPrice(request.StopPrice))
+		}
+	default:
+		return nil, fmt.Errorf("unknown order type: %s", request.Type)
+	}
+	if request.QuoteQuantity > 0 {
+		if e.futures || request.Type != orderMarket {
+			return nil, fmt.Errorf("quote quantities are only supported by spot market orders")
+		}
+		parameters.Set("quoteOrderQty", formatDecimal(request.QuoteQuantity))
+	} else {
+		parameters.Set("quantity", filters.formatQuantity(request.Quantity))
+	}
+	if request.ReduceOnly && e.futures {
+		parameters.Set("reduceOnly", "true")
+	}
+	return parameters, nil
+}
+
+func getBinanceSide(buy bool) string {
+	if buy {
+		return "BUY"
+	} else {
+		return "SELL"
+	}
+}
+
+func (r *binanceOrderResponse) getOrder() Order {
+	price, _ := strconv.ParseFloat(r.Price, 64)
+	quantity, _ := strconv.ParseFloat(r.OriginalQuantity, 64)
+	order := Order{
+		ID: strconv.FormatInt(r.OrderID, 10),
+		ClientOrderID: r.ClientOrderID,
+		Symbol: r.Symbol,
+		Buy: r.Side == "BUY",
+		Price: price,
+		Quantity: quantity,
+		ExecutedQuantity: r.getExecutedQuantity(),
+		AveragePrice: r.getAveragePrice(),
+	}
+	switch r.Type {
+	case "MARKET":
+		order.Type = orderMarket
+	case "LIMIT":
+		order.Type = orderLimit
+	case "STOP_LOSS", "STOP_LOSS_LIMIT", "STOP_MARKET", "STOP":
+		order.Type = orderStopLoss
+	case "LIMIT_MAKER", "TAKE_PROFIT", "TAKE_PROFIT_LIMIT", "TAKE_PROFIT_MARKET":
+		order.Type = orderTakeProfit
+	}
+	switch r.Status {
+	case "NEW":
+		order.Status = orderStatusNew
+	case "PARTIALLY_FILLED":
+		order.Status = orderStatusPartiallyFilled
+	case "FILLED":
+		order.Status = orderStatusFilled
+	case "CANCELED", "EXPIRED", "REJECTED", "EXPIRED_IN_MATCH":
+		order.Status = orderStatusCancelled
+	default:
+		order.Status = r.Status
+	}
+	return order
+}
\ No newline at end of file
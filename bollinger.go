@@ -0,0 +1,122 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	defaultBollingerPeriod = 20
+	defaultBollingerDeviations = 2.0
+	bollingerAboveUpper = "aboveUpper"
+	bollingerBelowUpper = "belowUpper"
+	bollingerAboveLower = "aboveLower"
+	bollingerBelowLower = "belowLower"
+)
+
+type BollingerConfiguration struct {
+	Period int `yaml:"period"`
+	Deviations *float64 `yaml:"deviations"`
+	IntervalMinutes int `yaml:"intervalMinutes"`
+	Band string `yaml:"band"`
+	MinBandwidth *float64 `yaml:"minBandwidth"`
+	MaxBandwidth *float64 `yaml:"maxBandwidth"`
+}
+
+type bollingerResult struct {
+	Upper float64 `json:"upper"`
+	Middle float64 `json:"middle"`
+	Lower float64 `json:"lower"`
+	Bandwidth float64 `json:"bandwidth"`
+	Match bool `json:"match"`
+}
+
+func (c *BollingerConfiguration) validate(strategy string) {
+	if c.Period < 0 || c.Period == 1 {
+		commons.Fatalf("Invalid Bollinger Band period for strategy %s", strategy)
+	}
+	if c.Deviations != nil && *c.Deviations <= 0 {
+		commons.Fatalf("Invalid Bollinger Band deviations for strategy %s", strategy)
+	}
+	switch c.Band {
+	case "", bollingerAboveUpper, bollingerBelowUpper, bollingerAboveLower, bollingerBelowLower:
+	default:
+		commons.Fatalf("Unknown Bollinger Band condition for strategy %s: %s", strategy, c.Band)
+	}
+	if c.MinBandwidth != nil && *c.MinBandwidth < 0 || c.MaxBandwidth != nil && *c.MaxBandwidth <= 0 {
+		commons.Fatalf("Invalid Bollinger bandwidth for strategy %s", strategy)
+	}
+	if c.Band == "" && c.MinBandwidth == nil && c.MaxBandwidth == nil {
+		commons.Fatalf("Missing Bollinger Band condition for strategy %s", strategy)
+	}
+	validateIndicatorInterval(c.IntervalMinutes, "Bollinger Band", strategy)
+}
+
+func (c *BollingerConfiguration) getPeriod() int {
+	if c.Period == 0 {
+		return defaultBollingerPeriod
+	}
+	return c.Period
+}
+
+func (c *BollingerConfiguration) getDeviations() float64 {
+	if c.Deviations == nil {
+		return defaultBollingerDeviations
+	}
+	return *c.Deviations
+}
+
+func (c *BollingerConfiguration) getLookback() time.Duration {
+	return time.Duration(c.getPeriod() + 1) * getIndicatorInterval(c.IntervalMinutes)
+}
+
+// Uses the population standard deviation like most charting software
+func getMeanDeviation(values []float64) (float64, float64) {
+	mean := getSMA(values, len(values))
+	variance := 0.0
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+// The bandwidth is the distance between the bands as a percentage of the middle band
+func (c *BollingerConfiguration) getBands(records []ohlcRecord) (bollingerResult, bool) {
+	bars := getBars(records, getIndicatorInterval(c.IntervalMinutes))
+	period := c.getPeriod()
+	if len(bars) < period {
+		return bollingerResult{}, false
+	}
+	closes := []float64{}
+	for _, bar := range bars[len(bars) - period:] {
+		closes = append(closes, bar.close)
+	}
+	middle, deviation := getMeanDeviation(closes)
+	result := bollingerResult{
+		Upper: middle + c.getDeviations() * deviation,
+		Middle: middle,
+		Lower: middle - c.getDeviations() * deviation,
+	}
+	result.Bandwidth = (result.Upper - result.Lower) / middle * percent
+	price := closes[len(closes) - 1]
+	result.Match = true
+	switch c.Band {
+	case bollingerAboveUpper:
+		result.Match = price > result.Upper
+	case bollingerBelowUpper:
+		result.Match = price < result.Upper
+	case bollingerAboveLower:
+		result.Match = price > result.Lower
+	case bollingerBelowLower:
+		result.Match = price < result.Lower
+	}
+	if c.MinBandwidth != nil && result.Bandwidth < *c.MinBandwidth {
+		result.Match = false
+	}
+	if c.MaxBandwidth != nil && result.Bandwidth > *c.MaxBandwidth {
+		result.Match = false
+	}
+	return result, true
+}
\ No newline at end of file
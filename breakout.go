@@ -0,0 +1,53 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+type BreakoutConfiguration struct {
+	Hours int `yaml:"hours"`
+}
+
+type breakoutResult struct {
+	Level float64 `json:"level"`
+	Match bool `json:"match"`
+}
+
+func (c *BreakoutConfiguration) validate(strategy string) {
+	if c.Hours <= 0 {
+		commons.Fatalf("Invalid breakout period for strategy %s", strategy)
+	}
+}
+
+func (c *BreakoutConfiguration) getLookback() time.Duration {
+	return time.Duration(c.Hours + 1) * time.Hour
+}
+
+// Up strategies require the latest close to exceed the highest high of the previous hours, down strategies a close below the lowest low
+func (c *BreakoutConfiguration) getBreakout(records []ohlcRecord, up bool) (breakoutResult, bool) {
+	latest := records[len(records) - 1]
+	start := latest.timestamp.Add(-time.Duration(c.Hours) * time.Hour)
+	if records[0].timestamp.After(start) {
+		return breakoutResult{}, false
+	}
+	high := math.Inf(-1)
+	low := math.Inf(1)
+	for _, record := range records[findRecord(records, start):len(records) - 1] {
+		high = math.Max(high, record.high)
+		low = math.Min(low, record.low)
+	}
+	if up {
+		return breakoutResult{
+			Level: high,
+			Match: latest.close > high,
+		}, true
+	} else {
+		return breakoutResult{
+			Level: low,
+			Match: latest.close < low,
+		}, true
+	}
+}
\ No newline at end of file
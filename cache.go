@@ -0,0 +1,147 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	cacheDirectory = "cache"
+	cacheInterval = "5m"
+	maxParallelDownloads = 4
+)
+
+type cachedRecord struct {
+	Timestamp int64 `json:"t"`
+	Open float64 `json:"o"`
+	High float64 `json:"h"`
+	Low float64 `json:"l"`
+	Close float64 `json:"c"`
+	Volume *float64 `json:"v"`
+}
+
+var (
+	refreshCache bool
+	backtestWorkers int
+	downloadSemaphore = make(chan struct{}, maxParallelDownloads)
+	currencyLocks sync.Map
+)
+
+func addBacktestFlags(flags *flag.FlagSet) {
+	flags.BoolVar(&refreshCache, "refresh", false, "Ignore cached candles and download them again")
+	flags.IntVar(&backtestWorkers, "workers", runtime.NumCPU(), "Number of strategies to backtest in parallel")
+}
+
+// Prevents concurrent backtests of strategies with the same currency from writing the same cache files
+func lockCurrency(currency string) *sync.Mutex {
+	value, _ := currencyLocks.LoadOrStore(currency, &sync.Mutex{})
+	lock := value.(*sync.Mutex)
+	lock.Lock()
+	return lock
+}
+
+// Candles are cached in files covering one UTC day each, days that haven't ended yet are never cached
+func loadHistoricalRecords(currency string, from time.Time, to time.Time) []ohlcRecord {
+	return loadHistoricalInterval(currency, cacheInterval, from, to)
+}
+
+func loadHistoricalInterval(currency string, interval string, from time.Time, to time.Time) []ohlcRecord {
+	lock := lockCurrency(currency)
+	defer lock.Unlock()
+	records := []ohlcRecord{}
+	now := time.Now().UTC()
+	firstDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	for day := firstDay; day.Before(to); day = day.AddDate(0, 0, 1) {
+		end := day.AddDate(0, 0, 1)
+		var dayRecords []ohlcRecord
+		if end.After(now) {
+			dayRecords = downloadRange(currency, interval, day, end)
+		} else {
+			dayRecords = loadCachedDay(currency, interval, day)
+		}
+		for _, record := range dayRecords {
+			if !record.timestamp.Before(from) && record.timestamp.Before(to) {
+				records = append(records, record)
+			}
+		}
+	}
+	return records
+}
+
+func loadCachedDay(currency string, interval string, day time.Time) []ohlcRecord {
+	fileName := fmt.Sprintf("%s.json", day.Format(time.DateOnly))
+	path := filepath.Join(dataDirectory, cacheDirectory, currency, interval, fileName)
+	cachedRecords := []cachedRecord{}
+	if !refreshCache && readJSON(path, &cachedRecords) && hasVolume(cachedRecords) {
+		records := []ohlcRecord{}
+		for _, cached := range cachedRecords {
+			record := ohlcRecord{
+				timestamp: time.UnixMilli(cached.Timestamp).UTC(),
+				open: cached.Open,
+				high: cached.High,
+				low: cached.Low,
+				close: cached.Close,
+				volume: *cached.Volume,
+			}
+			records = append(records, record)
+		}
+		return records
+	}
+	records := downloadRange(currency, interval, day, day.AddDate(0, 0, 1))
+	cachedRecords = []cachedRecord{}
+	for _, record := range records {
+		cached := cachedRecord{
+			Timestamp: record.timestamp.UnixMilli(),
+			Open: record.open,
+			High: record.high,
+			Low: record.low,
+			Close: record.close,
+			Volume: &record.volume,
+		}
+		cachedRecords = append(cachedRecords, cached)
+	}
+	writeJSON(path, cachedRecords)
+	return records
+}
+
+// Files cached before volumes were stored are downloaded again
+func hasVolume(cachedRecords []cachedRecord) bool {
+	for _, cached := range cachedRecords {
+		if cached.Volume == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func downloadRange(currency string, interval string, from time.Time, to time.Time) []ohlcRecord {
+	records := []ohlcRecord{}
+	start := from
+	for start.Before(to) {
+		parameters := map[string]string{
+			"symbol": currency,
+			"interval": interval,
+			"limit": "1000",
+			"startTime": commons.Int64ToString(start.UnixMilli()),
+			"endTime": commons.Int64ToString(to.UnixMilli() - 1),
+		}
+		downloadSemaphore <- struct{}{}
+		page, err := downloadRecords(parameters)
+		<-downloadSemaphore
+		if err != nil {
+			commons.Fatalf("Failed to download candles for %s: %v", currency, err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		records = append(records, page...)
+		start = page[len(page) - 1].timestamp.Add(getIntervalDuration(interval))
+	}
+	return records
+}
\ No newline at end of file
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"slices"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	maxBusinessDaysPerMonth = 23
+)
+
+type CalendarConfiguration struct {
+	Days []DayRange `yaml:"days"`
+	FirstDay bool `yaml:"firstDay"`
+	LastDay bool `yaml:"lastDay"`
+	FirstDayOfMonth *int `yaml:"firstDayOfMonth"`
+	LastDayOfMonth *int `yaml:"lastDayOfMonth"`
+	Months []string `yaml:"months"`
+}
+
+// Negative days count from the end of the month, a range whose start follows its end wraps around the turn of the month
+type DayRange struct {
+	From int `yaml:"from"`
+	To int `yaml:"to"`
+}
+
+func (c *CalendarConfiguration) validate(strategy string) {
+	for _, days := range c.Days {
+		if !isValidDayOfMonth(days.From) || !isValidDayOfMonth(days.To) {
+			commons.Fatalf("Invalid day of month range for strategy %s: %d to %d", strategy, days.From, days.To)
+		}
+	}
+	if c.FirstDayOfMonth != nil && !isValidBusinessDayWindow(*c.FirstDayOfMonth) {
+		commons.Fatalf("Invalid firstDayOfMonth window for strategy %s: %d", strategy, *c.FirstDayOfMonth)
+	}
+	if c.LastDayOfMonth != nil && !isValidBusinessDayWindow(*c.LastDayOfMonth) {
+		commons.Fatalf("Invalid lastDayOfMonth window for strategy %s: %d", strategy, *c.LastDayOfMonth)
+	}
+	for _, month := range c.Months {
+		_, valid := parseMonth(month)
+		if !valid {
+			commons.Fatalf("Invalid month for strategy %s: %s", strategy, month)
+		}
+	}
+	if !c.hasDayFilter() && len(c.Months) == 0 {
+		commons.Fatalf("Empty calendar filter for strategy %s", strategy)
+	}
+}
+
+func isValidDayOfMonth(day int) bool {
+	return day >= -31 && day <= 31 && day != 0
+}
+
+func isValidBusinessDayWindow(days int) bool {
+	return days >= 1 && days <= maxBusinessDaysPerMonth
+}
+
+func parseMonth(name string) (time.Month, bool) {
+	for month := time.January; month <= time.December; month++ {
+		if month.String() == name {
+			return month, true
+		}
+	}
+	return 0, false
+}
+
+func (r *DayRange) matches(day time.Time) bool {
+	days := getDaysInMonth(day)
+	resolve := func (value int) int {
+		if value < 0 {
+			return days + value + 1
+		}
+		return value
+	}
+	from := resolve(r.From)
+	to := resolve(r.To)
+	if from <= to {
+		return day.Day() >= from && day.Day() <= to
+	} else {
+		return day.Day() >= from || day.Day() <= to
+	}
+}
+
+func getDaysInMonth(day time.Time) int {
+	return time.Date(day.Year(), day.Month() + 1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// Trading days are the days of the month that fall on one of the weekdays of the strategy and aren't excluded holidays
+func (s *Strategy) isTradingDay(day time.Time) bool {
+	if s.isHoliday(day) {
+		return false
+	}
+	if s.schedule != nil {
+		return s.schedule.matchesDay(day)
+	}
+	return slices.ContainsFunc(s.Weekdays, func (w commons.SerializableWeekday) bool {
+		return w.Weekday == day.Weekday()
+	})
+}
+
+// Business days are weekdays from Monday to Friday that aren't excluded holidays, regardless of the weekdays of the strategy
+func (s *Strategy) isBusinessDay(day time.Time) bool {
+	return day.Weekday() != time.Saturday && day.Weekday() != time.Sunday && !s.isHoliday(day)
+}
+
+// Returns the one-based position of a business day in its month counting from the start and from the end, or zeros for other days
+func (s *Strategy) getBusinessDayOfMonth(day time.Time) (int, int) {
+	if !s.isBusinessDay(day) {
+		return 0, 0
+	}
+	first := 1
+	for previous := day.AddDate(0, 0, -1); previous.Month() == day.Month(); previous = previous.AddDate(0, 0, -1) {
+		if s.isBusinessDay(previous) {
+			first++
+		}
+	}
+	last := 1
+	for next := day.AddDate(0, 0, 1); next.Month() == day.Month(); next = next.AddDate(0, 0, 1) {
+		if s.isBusinessDay(next) {
+			last++
+		}
+	}
+	return first, last
+}
+
+func (s *Strategy) isFirstTradingDay(day time.Time) bool {
+	for previous := day.AddDate(0, 0, -1); previous.Month() == day.Month(); previous = previous.AddDate(0, 0, -1) {
+		if s.isTradingDay(previous) {
+			return false
+		}
+	}
+	return s.isTradingDay(day)
+}
+
+func (s *Strategy) isLastTradingDay(day time.Time) bool {
+	for next := day.AddDate(0, 0, 1); next.Month() == day.Month(); next = next.AddDate(0, 0, 1) {
+		if s.isTradingDay(next) {
+			return false
+		}
+	}
+	return s.isTradingDay(day)
+}
+
+func (c *CalendarConfiguration) hasDayFilter() bool {
+	return len(c.Days) > 0 || c.FirstDay || c.LastDay || c.FirstDayOfMonth != nil || c.LastDayOfMonth != nil
+}
+
+// Months have to match if specified, the day ranges, first and last trading days and month boundary windows are alternatives to each other
+func (c *CalendarConfiguration) matches(s *Strategy, day time.Time) bool {
+	if len(c.Months) > 0 {
+		monthMatch := slices.ContainsFunc(c.Months, func (name string) bool {
+			month, _ := parseMonth(name)
+			return month == day.Month()
+		})
+		if !monthMatch {
+			return false
+		}
+	}
+	if !c.hasDayFilter() {
+		return true
+	}
+	for _, days := range c.Days {
+		if days.matches(day) {
+			return true
+		}
+	}
+	if c.FirstDay && s.isFirstTradingDay(day) || c.LastDay && s.isLastTradingDay(day) {
+		return true
+	}
+	first, last := s.getBusinessDayOfMonth(day)
+	if c.FirstDayOfMonth != nil && first >= 1 && first <= *c.FirstDayOfMonth {
+		return true
+	}
+	return c.LastDayOfMonth != nil && last >= 1 && last <= *c.LastDayOfMonth
+}
+
+func (s *Strategy) matchesCalendar(day time.Time) bool {
+	return s.Calendar == nil || s.Calendar.matches(s, day)
+}
\ No newline at end of file
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+type comparisonMetric struct {
+	name string
+	get func (*backtestResult) float64
+	format func (float64) string
+}
+
+func runCompare(arguments []string) {
+	flags := flag.NewFlagSet("compare", flag.ExitOnError)
+	strategyFilter := flags.String("strategy", "", "Restrict the comparison to strategies whose names match this filter")
+	fromString := flags.String("from", "", "Start date of the backtests (YYYY-MM-DD)")
+	toString := flags.String("to", "", "End date of the backtests (YYYY-MM-DD), defaults to today")
+	addBacktestFlags(flags)
+	paths := parseArguments(flags, arguments)
+	if len(paths) != 2 {
+		commons.Fatalf("Usage: coinage compare <old configuration> <new configuration> -from <date> [-to <date>]")
+	}
+	from, to := parseDateRange(*fromString, *toString)
+	oldResults := backtestConfiguration(paths[0], *strategyFilter, from, to)
+	newResults := backtestConfiguration(paths[1], *strategyFilter, from, to)
+	names := []string{}
+	for _, result := range append(oldResults, newResults...) {
+		name := result.strategy.Name
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	fmt.Printf("\n")
+	fmt.Printf("Period: %s - %s\n\n", from.Format(time.DateOnly), to.Format(time.DateOnly))
+	for _, name := range names {
+		oldResult := findResult(oldResults, name)
+		newResult := findResult(newResults, name)
+		printComparison(name, oldResult, newResult)
+	}
+}
+
+func backtestConfiguration(path string, filter string, from time.Time, to time.Time) []backtestResult {
+	configuration = loadConfigurationFile(path)
+	return backtestStrategies(filter, from, to)
+}
+
+func findResult(results []backtestResult, name string) *backtestResult {
+	for i := range results {
+		if results[i].strategy.Name == name {
+			return &results[i]
+		}
+	}
+	return nil
+}
+
+func getComparisonMetrics() []comparisonMetric {
+	formatPercentage := func (value float64) string {
+		return fmt.Sprintf("%+.2f%%", value * percent)
+	}
+	formatRatio := func (value float64) string {
+		return fmt.Sprintf("%.2f", value)
+	}
+	return []comparisonMetric{
+		{
+			name: "Trades",
+			get: func (r *backtestResult) float64 {
+				return float64(len(r.trades))
+			},
+			format: func (value float64) string {
+				return strconv.Itoa(int(value))
+			},
+		},
+		{"Win rate", (*backtestResult).winRate, formatPercentage},
+		{"Mean return", (*backtestResult).meanReturn, formatPercentage},
+		{"Total return", (*backtestResult).totalReturn, formatPercentage},
+		{"Max drawdown", (*backtestResult).maxDrawdown, formatPercentage},
+		{"Sharpe ratio", (*backtestResult).sharpeRatio, formatRatio},
+	}
+}
+
+func printComparison(name string, oldResult *backtestResult, newResult *backtestResult) {
+	missing := "-"
+	fmt.Printf("%s:\n", name)
+	fmt.Printf("\t%-14s %12s %12s %12s\n", "Metric", "Old", "New", "Change")
+	for _, metric := range getComparisonMetrics() {
+		oldValue := math.NaN()
+		newValue := math.NaN()
+		oldString := missing
+		newString := missing
+		if oldResult != nil {
+			oldValue = metric.get(oldResult)
+			oldString = metric.format(oldValue)
+		}
+		if newResult != nil {
+			newValue = metric.get(newResult)
+			newString = metric.format(newValue)
+		}
+		changeString := missing
+		change := newValue - oldValue
+		if !math.IsNaN(change) {
+			changeString = metric.format(change)
+		}
+		fmt.Printf("\t%-14s %12s %12s %12s\n", metric.name, oldString, newString, changeString)
+	}
+	fmt.Printf("\n")
+}
\ No newline at end of file
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"slices"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+// Each node of a condition tree either combines other nodes or is a single condition
+type ConditionConfiguration struct {
+	All []ConditionConfiguration `yaml:"all"`
+	Any []ConditionConfiguration `yaml:"any"`
+	Not *ConditionConfiguration `yaml:"not"`
+	Weekdays []commons.SerializableWeekday `yaml:"weekdays"`
+	Times []commons.SerializableDuration `yaml:"times"`
+	Calendar *CalendarConfiguration `yaml:"calendar"`
+	Momentum *MomentumConfiguration `yaml:"momentum"`
+	RSI *RSIConfiguration `yaml:"rsi"`
+	MovingAverage *MovingAverageConfiguration `yaml:"movingAverage"`
+	MACD *MACDConfiguration `yaml:"macd"`
+	Bollinger *BollingerConfiguration `yaml:"bollinger"`
+	Volatility *VolatilityConfiguration `yaml:"volatility"`
+	Volume *VolumeConfiguration `yaml:"volume"`
+	Breakout *BreakoutConfiguration `yaml:"breakout"`
+	ZScore *ZScoreConfiguration `yaml:"zScore"`
+	RelativeStrength *RelativeStrengthConfiguration `yaml:"relativeStrength"`
+	WeekendGap *WeekendGapConfiguration `yaml:"weekendGap"`
+	Donchian *DonchianConfiguration `yaml:"donchian"`
+	EMASlope *EMASlopeConfiguration `yaml:"emaSlope"`
+	Gate *GateConfiguration `yaml:"gate"`
+	VWAP *VWAPConfiguration `yaml:"vwap"`
+	Pattern *PatternConfiguration `yaml:"pattern"`
+	Score *ScoreConfiguration `yaml:"score"`
+	Percentile *PercentileConfiguration `yaml:"percentile"`
+	RealizedVolatility *RealizedVolatilityConfiguration `yaml:"realizedVolatility"`
+	Correlation *CorrelationConfiguration `yaml:"correlation"`
+}
+
+func (c *ConditionConfiguration) validate(strategy string) {
+	kinds := 0
+	count := func (present bool) {
+		if present {
+			kinds++
+		}
+	}
+	count(len(c.All) > 0)
+	count(len(c.Any) > 0)
+	count(c.Not != nil)
+	count(len(c.Weekdays) > 0)
+	count(len(c.Times) > 0)
+	count(c.Calendar != nil)
+	count(c.Momentum != nil)
+	count(c.RSI != nil)
+	count(c.MovingAverage != nil)
+	count(c.MACD != nil)
+	count(c.Bollinger != nil)
+	count(c.Volatility != nil)
+	count(c.Volume != nil)
+	count(c.Breakout != nil)
+	count(c.ZScore != nil)
+	count(c.RelativeStrength != nil)
+	count(c.WeekendGap != nil)
+	count(c.Donchian != nil)
+	count(c.EMASlope != nil)
+	count(c.Gate != nil)
+	count(c.VWAP != nil)
+	count(c.Pattern != nil)
+	count(c.Score != nil)
+	count(c.Percentile != nil)
+	count(c.RealizedVolatility != nil)
+	count(c.Correlation != nil)
+	if kinds != 1 {
+		commons.Fatalf("Each node in the condition tree of strategy %s must specify exactly one operator or condition", strategy)
+	}
+	for i := range c.All {
+		c.All[i].validate(strategy)
+	}
+	for i := range c.Any {
+		c.Any[i].validate(strategy)
+	}
+	if c.Not != nil {
+		c.Not.validate(strategy)
+	}
+	if c.Calendar != nil {
+		c.Calendar.validate(strategy)
+	}
+	if c.Momentum != nil {
+		c.Momentum.validate(strategy)
+	}
+	if c.RSI != nil {
+		c.RSI.validate(strategy)
+	}
+	if c.MovingAverage != nil {
+		c.MovingAverage.validate(strategy)
+	}
+	if c.MACD != nil {
+		c.MACD.validate(strategy)
+	}
+	if c.Bollinger != nil {
+		c.Bollinger.validate(strategy)
+	}
+	if c.Volatility != nil {
+		c.Volatility.validate(strategy)
+	}
+	if c.Volume != nil {
+		c.Volume.validate(strategy)
+	}
+	if c.Breakout != nil {
+		c.Breakout.validate(strategy)
+	}
+	if c.ZScore != nil {
+		c.ZScore.validate(strategy)
+	}
+	if c.RelativeStrength != nil {
+		c.RelativeStrength.validate(strategy)
+	}
+	if c.WeekendGap != nil {
+		c.WeekendGap.validate(strategy)
+	}
+	if c.Donchian != nil {
+		c.Donchian.validate(strategy)
+	}
+	if c.EMASlope != nil {
+		c.EMASlope.validate(strategy)
+	}
+	if c.Gate != nil {
+		c.Gate.validate(strategy)
+	}
+	if c.VWAP != nil {
+		c.VWAP.validate(strategy)
+	}
+	if c.Pattern != nil {
+		c.Pattern.validate(strategy)
+	}
+	if c.Score != nil {
+		c.Score.validate(strategy)
+	}
+	if c.Percentile != nil {
+		c.Percentile.validate(strategy)
+	}
+	if c.RealizedVolatility != nil {
+		c.RealizedVolatility.validate(strategy)
+	}
+	if c.Correlation != nil {
+		c.Correlation.validate(strategy)
+	}
+}
+
+func (c *ConditionConfiguration) getLookback(s *Strategy) time.Duration {
+	lookback := time.Duration(0)
+	for i := range c.All {
+		lookback = max(lookback, c.All[i].getLookback(s))
+	}
+	for i := range c.Any {
+		lookback = max(lookback, c.Any[i].getLookback(s))
+	}
+	if c.Not != nil {
+		lookback = max(lookback, c.Not.getLookback(s))
+	}
+	if c.Momentum != nil {
+		lookback = max(lookback, c.Momentum.Offset.Duration)
+	}
+	if c.RSI != nil {
+		lookback = max(lookback, time.Duration(c.RSI.Period + 1) * time.Hour)
+	}
+	if c.MovingAverage != nil {
+		lookback = max(lookback, c.MovingAverage.getLookback())
+	}
+	if c.MACD != nil {
+		lookback = max(lookback, c.MACD.getLookback())
+	}
+	if c.Bollinger != nil {
+		lookback = max(lookback, c.Bollinger.getLookback())
+	}
+	if c.Volatility != nil {
+		lookback = max(lookback, c.Volatility.getLookback())
+	}
+	if c.Volume != nil {
+		lookback = max(lookback, c.Volume.getLookback(s))
+	}
+	if c.Breakout != nil {
+		lookback = max(lookback, c.Breakout.getLookback())
+	}
+	if c.ZScore != nil {
+		lookback = max(lookback, c.ZScore.getLookback())
+	}
+	if c.RelativeStrength != nil {
+		lookback = max(lookback, c.RelativeStrength.getOffset(s))
+	}
+	if c.WeekendGap != nil {
+		lookback = max(lookback, c.WeekendGap.getLookback())
+	}
+	if c.Donchian != nil {
+		lookback = max(lookback, c.Donchian.getLookback())
+	}
+	if c.EMASlope != nil {
+		lookback = max(lookback, c.EMASlope.getLookback())
+	}
+	if c.Gate != nil {
+		lookback = max(lookback, c.Gate.Offset.Duration)
+	}
+	if c.VWAP != nil {
+		lookback = max(lookback, c.VWAP.getLookback())
+	}
+	if c.Pattern != nil {
+		lookback = max(lookback, c.Pattern.getLookback())
+	}
+	if c.Score != nil {
+		lookback = max(lookback, c.Score.getLookback())
+	}
+	if c.Percentile != nil {
+		lookback = max(lookback, percentileWindow)
+	}
+	if c.RealizedVolatility != nil {
+		lookback = max(lookback, c.RealizedVolatility.getLookback())
+	}
+	if c.Correlation != nil {
+		lookback = max(lookback, c.Correlation.getLookback())
+	}
+	return lookback
+}
+
+// The weekdays and times of the strategy itself still determine when it is evaluated, the ones in the tree are matched against the entry window
+func (c *ConditionConfiguration) matches(s *Strategy, records []ohlcRecord, latestIndex int, entryTime time.Time) bool {
+	switch {
+	case len(c.All) > 0:
+		for i := range c.All {
+			if !c.All[i].matches(s, records, latestIndex, entryTime) {
+				return false
+			}
+		}
+		return true
+	case len(c.Any) > 0:
+		for i := range c.Any {
+			if c.Any[i].matches(s, records, latestIndex, entryTime) {
+				return true
+			}
+		}
+		return false
+	case c.Not != nil:
+		return !c.Not.matches(s, records, latestIndex, entryTime)
+	case len(c.Weekdays) > 0:
+		return slices.ContainsFunc(c.Weekdays, func (w commons.SerializableWeekday) bool {
+			return w.Weekday == entryTime.In(s.getLocation()).Weekday()
+		})
+	case len(c.Times) > 0:
+		return slices.ContainsFunc(c.Times, func (t commons.SerializableDuration) bool {
+			return isTimeOfDay(entryTime.In(s.getLocation()), t.Duration)
+		})
+	case c.Calendar != nil:
+		return c.Calendar.matches(s, entryTime.In(s.getLocation()))
+	case c.Momentum != nil:
+		momentum, ok := getAnchoredMomentum(records, latestIndex, entryTime, c.Momentum.Offset.Duration)
+		return ok && c.Momentum.matches(momentum)
+	case c.RelativeStrength != nil:
+		_, match, ok := c.RelativeStrength.getRelativeStrength(s, records, latestIndex, entryTime)
+		return ok && match
+	case c.Gate != nil:
+		return c.Gate.getGate(records, latestIndex, entryTime).Match
+	case c.Score != nil:
+		_, match, ok := c.Score.getScore(records, latestIndex, entryTime)
+		return ok && match
+	case c.Correlation != nil:
+		_, match, ok := c.Correlation.getCorrelation(records, latestIndex)
+		return ok && match
+	}
+	window := getIndicatorWindow(records, latestIndex, c.getLookback(s))
+	switch {
+	case c.RSI != nil:
+		rsi, ok := getRSI(getHourlyBars(window), c.RSI.Period)
+		return ok && c.RSI.matches(rsi)
+	case c.MovingAverage != nil:
+		fast, slow, ok := c.MovingAverage.getAverages(window)
+		return ok && s.getMovingAverageMatch(c.MovingAverage, fast, slow)
+	case c.MACD != nil:
+		macd, ok := c.MACD.getMACD(window, s.Up)
+		return ok && macd.Match
+	case c.Bollinger != nil:
+		bands, ok := c.Bollinger.getBands(window)
+		return ok && bands.Match
+	case c.Volatility != nil:
+		_, match, ok := c.Volatility.getATR(window)
+		return ok && match
+	case c.Volume != nil:
+		ratio, ok := c.Volume.getRatio(s, window)
+		return ok && ratio > c.Volume.Multiple
+	case c.Breakout != nil:
+		breakout, ok := c.Breakout.getBreakout(window, s.Up)
+		return ok && breakout.Match
+	case c.ZScore != nil:
+		_, match, ok := c.ZScore.getZScore(window)
+		return ok && match
+	case c.WeekendGap != nil:
+		_, match, ok := c.WeekendGap.getGap(window)
+		return ok && match
+	case c.Donchian != nil:
+		_, match, ok := c.Donchian.getPosition(window)
+		return ok && match
+	case c.EMASlope != nil:
+		_, match, ok := c.EMASlope.getSlope(window, s.Up)
+		return ok && match
+	case c.VWAP != nil:
+		_, match, ok := c.VWAP.getDeviation(window)
+		return ok && match
+	case c.Pattern != nil:
+		pattern, _ := c.Pattern.findPattern(window, s.Up)
+		return pattern != ""
+	case c.Percentile != nil:
+		_, match, ok := c.Percentile.getPercentileRank(s.Currency, window)
+		return ok && match
+	case c.RealizedVolatility != nil:
+		_, match, ok := c.RealizedVolatility.getVolatility(window)
+		return ok && match
+	}
+	return false
+}
+
+func (c *ConditionConfiguration) getReferenceSymbols() []string {
+	symbols := []string{}
+	for i := range c.All {
+		symbols = append(symbols, c.All[i].getReferenceSymbols()...)
+	}
+	for i := range c.Any {
+		symbols = append(symbols, c.Any[i].getReferenceSymbols()...)
+	}
+	if c.Not != nil {
+		symbols = append(symbols, c.Not.getReferenceSymbols()...)
+	}
+	if c.RelativeStrength != nil {
+		symbols = append(symbols, c.RelativeStrength.getSymbol())
+	}
+	if c.Gate != nil {
+		symbols = append(symbols, c.Gate.Symbol)
+	}
+	if c.Correlation != nil {
+		symbols = append(symbols, c.Correlation.getSymbol())
+	}
+	return symbols
+}
+
+func (c *ConditionConfiguration) getPercentileLookback() time.Duration {
+	lookback := time.Duration(0)
+	for i := range c.All {
+		lookback = max(lookback, c.All[i].getPercentileLookback())
+	}
+	for i := range c.Any {
+		lookback = max(lookback, c.Any[i].getPercentileLookback())
+	}
+	if c.Not != nil {
+		lookback = max(lookback, c.Not.getPercentileLookback())
+	}
+	if c.Percentile != nil {
+		lookback = max(lookback, c.Percentile.getLookback())
+	}
+	return lookback
+}
+
+func (s *Strategy) getConditionMatch(records []ohlcRecord, latestIndex int, entryTime time.Time) bool {
+	if s.Conditions == nil {
+		return true
+	}
+	return s.Conditions.matches(s, records, latestIndex, entryTime)
+}
\ No newline at end of file
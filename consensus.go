@@ -0,0 +1,45 @@
+package main
+
+import (
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+type ConsensusConfiguration struct {
+	Horizons []int `yaml:"horizons"`
+	Required int `yaml:"required"`
+}
+
+func (c *ConsensusConfiguration) validate(strategy string) {
+	if len(c.Horizons) == 0 {
+		commons.Fatalf("Missing consensus horizons for strategy %s", strategy)
+	}
+	for _, horizon := range c.Horizons {
+		if horizon <= 0 {
+			commons.Fatalf("Invalid consensus horizon for strategy %s: %d", strategy, horizon)
+		}
+	}
+	if c.Required < 1 || c.Required > len(c.Horizons) {
+		commons.Fatalf("Invalid number of required horizons for strategy %s", strategy)
+	}
+}
+
+// Counts the horizons whose momentum has the same sign as the primary momentum, missing data counts as disagreement
+func (s *Strategy) getConsensus(records []ohlcRecord, latestIndex int, entryTime time.Time, momentum float64) (int, bool) {
+	if s.Consensus == nil {
+		return 0, true
+	}
+	agreements := 0
+	for _, horizon := range s.Consensus.Horizons {
+		anchorIndex := findRecord(records, entryTime.Add(-time.Duration(horizon) * time.Hour))
+		if anchorIndex >= latestIndex {
+			continue
+		}
+		horizonMomentum := records[latestIndex].close / records[anchorIndex].open - 1.0
+		if horizonMomentum > 0 && momentum > 0 || horizonMomentum < 0 && momentum < 0 {
+			agreements++
+		}
+	}
+	return agreements, agreements >= s.Consensus.Required
+}
\ No newline at end of file
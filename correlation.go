@@ -0,0 +1,103 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+type CorrelationConfiguration struct {
+	Symbol string `yaml:"symbol"`
+	Period int `yaml:"period"`
+	IntervalMinutes int `yaml:"intervalMinutes"`
+	GreaterThan *float64 `yaml:"greaterThan"`
+	LessThan *float64 `yaml:"lessThan"`
+}
+
+func (c *CorrelationConfiguration) validate(strategy string) {
+	if c.Period < 3 {
+		commons.Fatalf("Invalid correlation period for strategy %s", strategy)
+	}
+	if c.GreaterThan == nil && c.LessThan == nil {
+		commons.Fatalf("Missing correlation constraint for strategy %s", strategy)
+	}
+	for _, threshold := range []*float64{c.GreaterThan, c.LessThan} {
+		if threshold != nil && (*threshold < -1 || *threshold > 1) {
+			commons.Fatalf("Invalid correlation threshold for strategy %s", strategy)
+		}
+	}
+	validateIndicatorInterval(c.IntervalMinutes, "correlation", strategy)
+}
+
+func (c *CorrelationConfiguration) getSymbol() string {
+	if c.Symbol == "" {
+		return defaultBenchmark
+	}
+	return c.Symbol
+}
+
+func (c *CorrelationConfiguration) getLookback() time.Duration {
+	return time.Duration(c.Period + 1) * getIndicatorInterval(c.IntervalMinutes)
+}
+
+// Pearson correlation of the returns of the bars of both symbols, which are matched by their timestamps
+func (c *CorrelationConfiguration) getCorrelation(records []ohlcRecord, latestIndex int) (float64, bool, bool) {
+	interval := getIndicatorInterval(c.IntervalMinutes)
+	latest := records[latestIndex].timestamp
+	reference := getReferenceRecords(c.getSymbol())
+	referenceIndex := findRecord(reference, latest)
+	if referenceIndex >= len(reference) || !reference[referenceIndex].timestamp.Equal(latest) {
+		return 0, false, false
+	}
+	bars := getBars(getIndicatorWindow(records, latestIndex, c.getLookback()), interval)
+	referenceBars := getBars(getIndicatorWindow(reference, referenceIndex, c.getLookback()), interval)
+	referenceCloses := map[time.Time]float64{}
+	for _, bar := range referenceBars {
+		referenceCloses[bar.timestamp] = bar.close
+	}
+	if len(bars) < c.Period + 1 {
+		return 0, false, false
+	}
+	bars = bars[len(bars) - c.Period - 1:]
+	returns := []float64{}
+	referenceReturns := []float64{}
+	for i := 1; i < len(bars); i++ {
+		previous, previousExists := referenceCloses[bars[i - 1].timestamp]
+		current, currentExists := referenceCloses[bars[i].timestamp]
+		if !previousExists || !currentExists {
+			return 0, false, false
+		}
+		returns = append(returns, math.Log(bars[i].close / bars[i - 1].close))
+		referenceReturns = append(referenceReturns, math.Log(current / previous))
+	}
+	correlation, ok := getPearsonCorrelation(returns, referenceReturns)
+	if !ok {
+		return 0, false, false
+	}
+	match := true
+	if c.GreaterThan != nil {
+		match = match && correlation > *c.GreaterThan
+	}
+	if c.LessThan != nil {
+		match = match && correlation < *c.LessThan
+	}
+	return correlation, match, true
+}
+
+func getPearsonCorrelation(x []float64, y []float64) (float64, bool) {
+	meanX := getMean(x)
+	meanY := getMean(y)
+	covariance := 0.0
+	varianceX := 0.0
+	varianceY := 0.0
+	for i := range x {
+		covariance += (x[i] - meanX) * (y[i] - meanY)
+		varianceX += math.Pow(x[i] - meanX, 2.0)
+		varianceY += math.Pow(y[i] - meanY, 2.0)
+	}
+	if varianceX == 0 || varianceY == 0 {
+		return 0, false
+	}
+	return covariance / math.Sqrt(varianceX * varianceY), true
+}
\ No newline at end of file
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	credentialsFile = "credentials.json"
+	credentialsService = "coinage"
+	passphraseVariable = "COINAGE_PASSPHRASE"
+	defaultAccount = "default"
+	exchangeBinance = "binance"
+	exchangeBinanceTestnet = "binance-testnet"
+	keyIterations = 600000
+	keyLength = 32
+	saltLength = 16
+)
+
+type credential struct {
+	APIKey string `json:"apiKey"`
+	APISecret string `json:"apiSecret"`
+}
+
+type encryptedCredentials struct {
+	Salt []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func getCredentialName(exchange string, account string) string {
+	return exchange + "/" + account
+}
+
+// Credentials are looked up in the environment first, then in the encrypted file and finally in the OS keychain
+func getCredential(exchange string, account string) (credential, error) {
+	if account == "" {
+		account = defaultAccount
+	}
+	c, found := getEnvironmentCredential(exchange, account)
+	if found {
+		return c, nil
+	}
+	credentials, err := loadEncryptedCredentials()
+	if err != nil {
+		return credential{}, err
+	}
+	c, found = credentials[getCredentialName(exchange, account)]
+	if found {
+		return c, nil
+	}
+	c, found = getKeychainCredential(exchange, account)
+	if found {
+		return c, nil
+	}
+	return credential{}, fmt.Errorf("missing API credentials for %s account %s", exchange, account)
+}
+
+// The default account also accepts the variables without an account name, e.g. BINANCE_API_KEY
+func getEnvironmentCredential(exchange string, account string) (credential, bool) {
+	prefixes := []string{strings.ToUpper(exchange + "_" + account)}
+	if account == defaultAccount {
+		prefixes = append(prefixes, strings.ToUpper(exchange))
+	}
+	for _, prefix := range prefixes {
+		prefix = strings.ReplaceAll(prefix, "-", "_")
+		c := credential{
+			APIKey: os.Getenv(prefix + "_API_KEY"),
+			APISecret: os.Getenv(prefix + "_API_SECRET"),
+		}
+		if c.APIKey != "" && c.APISecret != "" {
+			return c, true
+		}
+	}
+	return credential{}, false
+}
+
+func getKeychainCredential(exchange string, account string) (credential, bool) {
+	name := getCredentialName(exchange, account)
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", credentialsService, "-a", name, "-w")
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", credentialsService, "account", name)
+	default:
+		return credential{}, false
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return credential{}, false
+	}
+	var c credential
+	err = json.Unmarshal(output, &c)
+	if err != nil || c.APIKey == "" || c.APISecret == "" {
+		return credential{}, false
+	}
+	return c, true
+}
+
+func getCredentialsKey(salt []byte) ([]byte, error) {
+	passphrase := os.Getenv(passphraseVariable)
+	if passphrase == "" {
+		return nil, fmt.Errorf("%s must be set to access the encrypted credentials", passphraseVariable)
+	}
+	return pbkdf2.Key(sha256.New, passphrase, salt, keyIterations, keyLength)
+}
+
+func loadEncryptedCredentials() (map[string]credential, error) {
+	credentials := map[string]credential{}
+	path := filepath.Join(dataDirectory, credentialsFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return credentials, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials: %v", err)
+	}
+	var encrypted encryptedCredentials
+	err = json.Unmarshal(data, &encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse credentials: %v", err)
+	}
+	key, err := getCredentialsKey(encrypted.Salt)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, encrypted.Nonce, encrypted.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt credentials, the passphrase may be wrong")
+	}
+	err = json.Unmarshal(plaintext, &credentials)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse decrypted credentials: %v", err)
+	}
+	return credentials, nil
+}
+
+// A new salt and nonce are generated every time the file is written
+func saveEncryptedCredentials(credentials map[string]credential) error {
+	salt := make([]byte, saltLength)
+	rand.Read(salt)
+	key, err := getCredentialsKey(salt)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	rand.Read(nonce)
+	plaintext, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+	encrypted := encryptedCredentials{
+		Salt: salt,
+		Nonce: nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, nil),
+	}
+	data, err := json.Marshal(encrypted)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(dataDirectory, 0755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dataDirectory, credentialsFile), data, 0600)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func runCredentials(arguments []string) {
+	flags := flag.NewFlagSet("credentials", flag.ExitOnError)
+	exchange := flags.String("exchange", exchangeBinance, "Exchange the credentials belong to")
+	account := flags.String("account", defaultAccount, "Name of the account on the exchange")
+	positional := parseArguments(flags, arguments)
+	if len(positional) != 1 {
+		commons.Fatalf("Usage: coinage credentials <set|delete|list> [-exchange <exchange>] [-account <account>]")
+	}
+	credentials, err := loadEncryptedCredentials()
+	if err != nil {
+		commons.Fatalf("%v", err)
+	}
+	name := getCredentialName(*exchange, *account)
+	switch positional[0] {
+	case "set":
+		// The secrets are read from standard input so that they don't end up in the shell history
+		reader := bufio.NewReader(os.Stdin)
+		fmt.Printf("API key: ")
+		apiKey, _ := reader.ReadString('\n')
+		fmt.Printf("API secret: ")
+		apiSecret, _ := reader.ReadString('\n')
+		c := credential{
+			APIKey: strings.TrimSpace(apiKey),
+			APISecret: strings.TrimSpace(apiSecret),
+		}
+		if c.APIKey == "" || c.APISecret == "" {
+			commons.Fatalf("Missing API key or secret")
+		}
+		credentials[name] = c
+	case "delete":
+		_, exists := credentials[name]
+		if !exists {
+			commons.Fatalf("No credentials stored for %s", name)
+		}
+		delete(credentials, name)
+	case "list":
+		names := []string{}
+		for name := range credentials {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("%s\n", name)
+		}
+		return
+	default:
+		commons.Fatalf("Unknown credentials action: %s", positional[0])
+	}
+	err = saveEncryptedCredentials(credentials)
+	if err != nil {
+		commons.Fatalf("Failed to save credentials: %v", err)
+	}
+	fmt.Printf("Saved credentials to %s\n", filepath.Join(dataDirectory, credentialsFile))
+}
\ No newline at end of file
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+var (
+	csvHeader bool
+	csvHeaderWritten bool
+)
+
+type csvRenderer struct {
+	results []EvaluationResult
+}
+
+var csvColumns = []string{
+	"time",
+	"entryTime",
+	"strategy",
+	"currency",
+	"side",
+	"price",
+	"momentum",
+	"weekdayMatch",
+	"timeMatch",
+	"gapMatch",
+	"priceMatch",
+	"momentumMatch",
+	"consensusMatch",
+	"lookbackMatch",
+	"timeframeMatch",
+	"indicatorMatch",
+	"conditionMatch",
+	"expressionMatch",
+	"liquidityMatch",
+	"fundingMatch",
+	"openInterestMatch",
+	"signal",
+	"suppressedBy",
+	"error",
+}
+
+func (r *csvRenderer) Render(result EvaluationResult) {
+	r.results = append(r.results, result)
+}
+
+// The header is only written once per process so that the output of daemon mode can be appended to a single log file
+func (r *csvRenderer) Close() {
+	writer := csv.NewWriter(os.Stdout)
+	if csvHeader && !csvHeaderWritten {
+		writer.Write(csvColumns)
+		csvHeaderWritten = true
+	}
+	for _, result := range r.results {
+		writer.Write(result.getCSVRow())
+	}
+	writer.Flush()
+	err := writer.Error()
+	if err != nil {
+		commons.Fatalf("Failed to write CSV output: %v", err)
+	}
+}
+
+// Optional conditions that weren't configured and live checks that didn't run because of an earlier mismatch are left empty
+func (r *EvaluationResult) getCSVRow() []string {
+	formatFloat := func (value *float64) string {
+		if value == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*value, 'f', -1, 64)
+	}
+	formatOptional := func (match *bool) string {
+		if match == nil {
+			return ""
+		}
+		return strconv.FormatBool(*match)
+	}
+	formatChecked := func (match bool) string {
+		if !r.Signal {
+			return ""
+		}
+		return strconv.FormatBool(match)
+	}
+	if r.Error != "" {
+		row := make([]string, len(csvColumns))
+		row[0] = r.Time.Format(time.RFC3339)
+		row[2] = r.Strategy
+		row[3] = r.Currency
+		row[4] = r.getSide()
+		row[len(row) - 1] = r.Error
+		return row
+	}
+	return []string{
+		r.Time.Format(time.RFC3339),
+		r.getEntryWindow().Format(time.RFC3339),
+		r.Strategy,
+		r.Currency,
+		r.getSide(),
+		formatFloat(&r.CurrentPrice),
+		formatFloat(r.Momentum),
+		strconv.FormatBool(r.WeekdayMatch),
+		strconv.FormatBool(r.TimeMatch),
+		strconv.FormatBool(r.GapMatch),
+		strconv.FormatBool(r.PriceMatch),
+		strconv.FormatBool(r.MomentumMatch),
+		strconv.FormatBool(r.ConsensusMatch),
+		strconv.FormatBool(r.LookbackMatch),
+		strconv.FormatBool(r.TimeframeMatch),
+		strconv.FormatBool(r.IndicatorMatch),
+		formatOptional(r.ConditionMatch),
+		formatOptional(r.ExpressionMatch),
+		formatChecked(r.LiquidityMatch),
+		formatChecked(r.FundingMatch),
+		formatChecked(r.OpenInterestMatch),
+		strconv.FormatBool(r.Signal && !r.Suppressed),
+		r.SuppressedBy,
+		"",
+	}
+}
\ No newline at end of file
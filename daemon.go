@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+// Evaluations are delayed slightly past the interval boundary so that the latest candle has closed
+const daemonDelay = 5 * time.Second
+
+var (
+	daemonMode bool
+	daemonInterval time.Duration
+)
+
+func runDaemon(filter string, format string, webhookURL string) {
+	if daemonInterval <= 0 {
+		commons.Fatalf("Invalid daemon interval: %s", daemonInterval)
+	}
+	startMetricsServer()
+	for {
+		renderer := newRenderer(format, webhookURL)
+		evaluateStrategies(filter, renderer)
+		next := time.Now().Truncate(daemonInterval).Add(daemonInterval + daemonDelay)
+		fmt.Fprintf(statusOutput, "Next evaluation at %s\n", next.UTC().Format(time.TimeOnly))
+		time.Sleep(time.Until(next))
+	}
+}
\ No newline at end of file
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/encratite/commons"
+	"github.com/fatih/color"
+)
+
+const (
+	dashboardRedraw = time.Second
+	defaultDashboardRefresh = time.Minute
+	clearScreen = "\033[H\033[2J"
+	hideCursor = "\033[?25l"
+	showCursor = "\033[?25h"
+)
+
+type dashboardRow struct {
+	strategy *Strategy
+	entryTime time.Time
+	momentum *float64
+	signal bool
+	suppressed bool
+	err error
+}
+
+func runDashboard(arguments []string) {
+	flags := flag.NewFlagSet("dashboard", flag.ExitOnError)
+	strategyFilter := flags.String("strategy", "", "Restrict the dashboard to strategies whose names match this filter")
+	refresh := flags.Duration("refresh", defaultDashboardRefresh, "Interval between evaluations of the strategies")
+	flags.Parse(arguments)
+	if *refresh <= 0 {
+		commons.Fatalf("Invalid refresh interval: %s", *refresh)
+	}
+	loadConfiguration()
+	// Status output of the evaluations would corrupt the screen
+	statusOutput = io.Discard
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	fmt.Print(hideCursor)
+	defer fmt.Print(showCursor)
+	// Evaluations run in the background so that the countdowns keep ticking while candles are being downloaded
+	updates := make(chan []dashboardRow)
+	go func () {
+		for {
+			updates <- getDashboardRows(*strategyFilter)
+			time.Sleep(*refresh)
+		}
+	}()
+	ticker := time.NewTicker(dashboardRedraw)
+	defer ticker.Stop()
+	var rows []dashboardRow
+	updated := time.Time{}
+	for {
+		drawDashboard(rows, updated, *refresh)
+		select {
+		case <-interrupt:
+			fmt.Print(clearScreen)
+			return
+		case rows = <-updates:
+			updated = time.Now().UTC()
+		case <-ticker.C:
+		}
+	}
+}
+
+func getDashboardRows(filter string) []dashboardRow {
+	now := time.Now().UTC()
+	rows := []dashboardRow{}
+	for i := range configuration.Strategies {
+		strategy := &configuration.Strategies[i]
+		if !strategy.matchesFilter(filter) {
+			continue
+		}
+		row := dashboardRow{
+			strategy: strategy,
+		}
+		entryTime, exists := strategy.getNextEntry(now)
+		if exists {
+			row.entryTime = entryTime
+			row.momentum, row.err = strategy.getDashboardMomentum(entryTime, now)
+		}
+		// Only strategies within their evaluation window can produce signals, which are previewed rather than emitted
+		_, pending := strategy.getPendingEntry(now)
+		if pending && row.err == nil {
+			result, err := strategy.preview()
+			if err != nil {
+				row.err = err
+			} else if result != nil {
+				row.signal = result.Signal && !result.Suppressed
+				row.suppressed = result.Suppressed
+				if result.Momentum != nil {
+					row.momentum = result.Momentum
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// The momentum relative to the anchor of the next entry, which is only available once the anchor candle exists
+func (s *Strategy) getDashboardMomentum(entryTime time.Time, now time.Time) (*float64, error) {
+	anchorTime := s.getAnchorTime(entryTime)
+	if anchorTime.After(now) {
+		return nil, nil
+	}
+	records, err := s.loadRecords(s.Currency)
+	if err != nil {
+		return nil, err
+	}
+	records, _ = filterRecords(records)
+	lastIndex := len(records) - 1
+	anchorIndex := findRecord(records, anchorTime)
+	if anchorIndex >= lastIndex {
+		return nil, nil
+	}
+	momentum := s.getMomentum(records, anchorIndex, lastIndex)
+	return &momentum, nil
+}
+
+func drawDashboard(rows []dashboardRow, updated time.Time, refresh time.Duration) {
+	bold := color.New(color.Bold).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	highlight := color.New(color.FgBlack, color.BgGreen, color.Bold).SprintFunc()
+	now := time.Now().UTC()
+	var builder strings.Builder
+	builder.WriteString(clearScreen)
+	fmt.Fprintf(&builder, "%s %s UTC\n", bold("coinage"), commons.GetTimeString(now))
+	if updated.IsZero() {
+		fmt.Fprintf(&builder, "Evaluating strategies...\n")
+		fmt.Print(builder.String())
+		return
+	}
+	fmt.Fprintf(&builder, "Updated at %s UTC, refreshing every %s, press Ctrl+C to quit\n\n", updated.Format(time.TimeOnly), refresh)
+	table := [][]string{{"Strategy", "Currency", "Side", "Next entry", "In", "Momentum", "Thresholds", "Signal"}}
+	for _, row := range rows {
+		table = append(table, row.getCells(now))
+	}
+	widths := make([]int, len(table[0]))
+	for _, cells := range table {
+		for i, cell := range cells {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+	for i, cells := range table {
+		padded := make([]string, len(cells))
+		for j, cell := range cells {
+			padded[j] = fmt.Sprintf("%-*s", widths[j], cell)
+		}
+		if i == 0 {
+			fmt.Fprintf(&builder, "%s\n", bold(strings.Join(padded, "  ")))
+			continue
+		}
+		row := rows[i - 1]
+		// Colors are applied after padding since the escape sequences would otherwise count towards the column widths
+		if row.signal {
+			fmt.Fprintf(&builder, "%s\n", highlight(strings.Join(padded, "  ")))
+			continue
+		}
+		if row.momentum != nil {
+			if row.strategy.getMomentumMatch(*row.momentum) {
+				padded[5] = green(padded[5])
+			} else {
+				padded[5] = red(padded[5])
+			}
+		}
+		if row.err != nil {
+			padded[7] = red(padded[7])
+		} else if row.suppressed {
+			padded[7] = yellow(padded[7])
+		}
+		fmt.Fprintf(&builder, "%s\n", strings.Join(padded, "  "))
+	}
+	fmt.Print(builder.String())
+}
+
+func (r *dashboardRow) getCells(now time.Time) []string {
+	s := r.strategy
+	side := "Down"
+	if s.Up {
+		side = "Up"
+	}
+	// Entries that have passed since the last evaluation are replaced without waiting for the next refresh
+	entryTime := r.entryTime
+	if !entryTime.IsZero() && !entryTime.After(now) {
+		entryTime, _ = s.getNextEntry(now)
+	}
+	nextEntry := "none"
+	countdown := ""
+	if !entryTime.IsZero() {
+		local := entryTime.In(s.getLocation())
+		nextEntry = fmt.Sprintf("%s %s", local.Weekday().String()[:3], local.Format("2006-01-02 15:04 MST"))
+		countdown = formatCountdown(entryTime.Sub(now))
+	}
+	momentum := ""
+	if r.momentum != nil {
+		momentum = fmt.Sprintf("%+.2f%%", *r.momentum)
+	}
+	thresholds := []string{}
+	if s.GreaterThan != nil {
+		thresholds = append(thresholds, fmt.Sprintf("> %+.2f%%", *s.GreaterThan))
+	}
+	if s.LessThan != nil {
+		thresholds = append(thresholds, fmt.Sprintf("< %+.2f%%", *s.LessThan))
+	}
+	status := ""
+	if r.err != nil {
+		status = fmt.Sprintf("error: %v", r.err)
+	} else if r.signal {
+		status = "SIGNAL"
+	} else if r.suppressed {
+		status = "suppressed"
+	}
+	return []string{s.Name, s.Currency, side, nextEntry, countdown, momentum, strings.Join(thresholds, ", "), status}
+}
+
+func formatCountdown(duration time.Duration) string {
+	duration = duration.Truncate(time.Second)
+	days := int(duration.Hours()) / 24
+	hours := int(duration.Hours()) % 24
+	minutes := int(duration.Minutes()) % 60
+	seconds := int(duration.Seconds()) % 60
+	if days > 0 {
+		return fmt.Sprintf("%dd %02dh %02dm", days, hours, minutes)
+	}
+	return fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds)
+}
\ No newline at end of file
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const datasetVolatilityHours = 24
+
+func runDataset(arguments []string) {
+	flags := flag.NewFlagSet("dataset", flag.ExitOnError)
+	currencies := flags.String("currency", "", "Comma-separated list of symbols to export, e.g. BTCUSDT,ETHUSDT")
+	fromString := flags.String("from", "", "Start date of the dataset (YYYY-MM-DD)")
+	toString := flags.String("to", "", "End date of the dataset (YYYY-MM-DD), defaults to today")
+	horizonsString := flags.String("horizons", "1,2,4,8,24", "Comma-separated momentum horizons in hours")
+	labelsString := flags.String("labels", "1,4,24", "Comma-separated forward return horizons in hours")
+	output := flags.String("output", "dataset.csv", "Path of the CSV file to write")
+	addBacktestFlags(flags)
+	flags.Parse(arguments)
+	if *currencies == "" {
+		commons.Fatalf("Missing currency")
+	}
+	from, to := parseDateRange(*fromString, *toString)
+	horizons := parseHours(*horizonsString)
+	labels := parseHours(*labelsString)
+	loadConfiguration()
+	file, err := os.Create(*output)
+	if err != nil {
+		commons.Fatalf("Failed to create dataset: %v", err)
+	}
+	defer file.Close()
+	writer := csv.NewWriter(file)
+	header := []string{"time", "currency", "weekday", "hour", "price"}
+	for _, horizon := range horizons {
+		header = append(header, fmt.Sprintf("momentum%dh", horizon))
+	}
+	header = append(header, fmt.Sprintf("volatility%dh", datasetVolatilityHours))
+	for _, label := range labels {
+		header = append(header, fmt.Sprintf("return%dh", label))
+	}
+	writer.Write(header)
+	rows := 0
+	for _, currency := range strings.Split(*currencies, ",") {
+		rows += writeDataset(writer, currency, from, to, horizons, labels)
+	}
+	writer.Flush()
+	err = writer.Error()
+	if err != nil {
+		commons.Fatalf("Failed to write dataset: %v", err)
+	}
+	fmt.Printf("Wrote %d rows to %s\n", rows, *output)
+}
+
+func parseHours(input string) []int {
+	hours := []int{}
+	for _, token := range strings.Split(input, ",") {
+		value, err := strconv.Atoi(strings.TrimSpace(token))
+		if err != nil || value <= 0 {
+			commons.Fatalf("Invalid number of hours: %s", token)
+		}
+		hours = append(hours, value)
+	}
+	return hours
+}
+
+// Each row describes the state at the start of an hour, features only use candles that closed before it
+func writeDataset(writer *csv.Writer, currency string, from time.Time, to time.Time, horizons []int, labels []int) int {
+	lookback := datasetVolatilityHours
+	for _, horizon := range horizons {
+		lookback = max(lookback, horizon)
+	}
+	lookahead := 0
+	for _, label := range labels {
+		lookahead = max(lookahead, label)
+	}
+	records := loadHistoricalRecords(currency, from.Add(-time.Duration(lookback + 1) * time.Hour), to.Add(time.Duration(lookahead + 1) * time.Hour))
+	records, _ = filterRecords(records)
+	format := func (value float64) string {
+		if math.IsNaN(value) {
+			return ""
+		}
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	rows := 0
+	for t := from; t.Before(to); t = t.Add(time.Hour) {
+		index := findRecord(records, t)
+		if index <= 0 || index >= len(records) || !records[index].timestamp.Equal(t) {
+			continue
+		}
+		latest := records[index - 1]
+		row := []string{
+			t.Format(time.RFC3339),
+			currency,
+			strconv.Itoa(int(t.Weekday())),
+			strconv.Itoa(t.Hour()),
+			format(latest.close),
+		}
+		for _, horizon := range horizons {
+			row = append(row, format(getHistoricalMomentum(records, index, t, horizon)))
+		}
+		row = append(row, format(getRealizedVolatility(records, index, t, datasetVolatilityHours)))
+		for _, label := range labels {
+			exitIndex := findRecord(records, t.Add(time.Duration(label) * time.Hour))
+			value := math.NaN()
+			if exitIndex < len(records) {
+				value = (records[exitIndex].open / records[index].open - 1.0) * percent
+			}
+			row = append(row, format(value))
+		}
+		writer.Write(row)
+		rows++
+	}
+	return rows
+}
+
+func getHistoricalMomentum(records []ohlcRecord, index int, t time.Time, hours int) float64 {
+	anchorIndex := findRecord(records, t.Add(-time.Duration(hours) * time.Hour))
+	if anchorIndex >= index {
+		return math.NaN()
+	}
+	return (records[index - 1].close / records[anchorIndex].open - 1.0) * percent
+}
+
+// Standard deviation of the log returns of the candles in the window, scaled to the length of the window
+func getRealizedVolatility(records []ohlcRecord, index int, t time.Time, hours int) float64 {
+	anchorIndex := findRecord(records, t.Add(-time.Duration(hours) * time.Hour))
+	if index - anchorIndex < 3 {
+		return math.NaN()
+	}
+	returns := []float64{}
+	for i := anchorIndex + 1; i < index; i++ {
+		returns = append(returns, math.Log(records[i].close / records[i - 1].close))
+	}
+	return getStandardDeviation(returns) * math.Sqrt(float64(len(returns))) * percent
+}
\ No newline at end of file
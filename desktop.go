@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+type desktopNotifier struct {}
+
+func (desktopNotifier) getName() string {
+	return "Desktop"
+}
+
+func (desktopNotifier) notifiesErrors() bool {
+	return false
+}
+
+func (desktopNotifier) send(n notification) error {
+	title := n.getTitle()
+	body := n.getCompactText()
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux":
+		cmd = exec.CommandContext(ctx, "notify-send", "--app-name=coinage", title, body)
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", quoteAppleScript(body), quoteAppleScript(title))
+		cmd = exec.CommandContext(ctx, "osascript", "-e", script)
+	case "windows":
+		cmd = exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", getToastScript(title, body))
+	default:
+		return fmt.Errorf("desktop notifications are not supported on %s", runtime.GOOS)
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func quoteAppleScript(text string) string {
+	text = strings.ReplaceAll(text, "\\", "\\\\")
+	text = strings.ReplaceAll(text, "\"", "\\\"")
+	return "\"" + text + "\""
+}
+
+// Toasts are raised through the WinRT API that ships with Windows 10 and later, no additional modules are required
+func getToastScript(title string, body string) string {
+	quote := func (text string) string {
+		return "'" + strings.ReplaceAll(text, "'", "''") + "'"
+	}
+	lines := []string{
+		"[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null",
+		"$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)",
+		"$text = $template.GetElementsByTagName('text')",
+		fmt.Sprintf("$text.Item(0).AppendChild($template.CreateTextNode(%s)) | Out-Null", quote(title)),
+		fmt.Sprintf("$text.Item(1).AppendChild($template.CreateTextNode(%s)) | Out-Null", quote(body)),
+		"$toast = [Windows.UI.Notifications.ToastNotification]::new($template)",
+		"[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('coinage').Show($toast)",
+	}
+	return strings.Join(lines, "; ")
+}
\ No newline at end of file
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+type DonchianConfiguration struct {
+	Period int `yaml:"period"`
+	IntervalMinutes int `yaml:"intervalMinutes"`
+	GreaterThan *float64 `yaml:"greaterThan"`
+	LessThan *float64 `yaml:"lessThan"`
+}
+
+func (c *DonchianConfiguration) validate(strategy string) {
+	if c.Period <= 0 {
+		commons.Fatalf("Invalid Donchian channel period for strategy %s", strategy)
+	}
+	if c.GreaterThan == nil && c.LessThan == nil {
+		commons.Fatalf("Missing Donchian channel constraint for strategy %s", strategy)
+	}
+	validateIndicatorInterval(c.IntervalMinutes, "Donchian channel", strategy)
+}
+
+func (c *DonchianConfiguration) getLookback() time.Duration {
+	return time.Duration(c.Period + 1) * getIndicatorInterval(c.IntervalMinutes)
+}
+
+// The position is the percentile of the latest close within the range between the lowest low and the highest high of the period
+func (c *DonchianConfiguration) getPosition(records []ohlcRecord) (float64, bool, bool) {
+	bars := getBars(records, getIndicatorInterval(c.IntervalMinutes))
+	if len(bars) < c.Period {
+		return 0, false, false
+	}
+	high := math.Inf(-1)
+	low := math.Inf(1)
+	for _, bar := range bars[len(bars) - c.Period:] {
+		high = math.Max(high, bar.high)
+		low = math.Min(low, bar.low)
+	}
+	if high == low {
+		return 0, false, false
+	}
+	position := (bars[len(bars) - 1].close - low) / (high - low) * percent
+	match := true
+	if c.GreaterThan != nil {
+		match = match && position > *c.GreaterThan
+	}
+	if c.LessThan != nil {
+		match = match && position < *c.LessThan
+	}
+	return position, match, true
+}
\ No newline at end of file
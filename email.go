@@ -0,0 +1,136 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	defaultSMTPPort = 587
+)
+
+type EmailConfiguration struct {
+	Host string `yaml:"host"`
+	Port int `yaml:"port"`
+	TLS bool `yaml:"tls"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From string `yaml:"from"`
+	To []string `yaml:"to"`
+	Template string `yaml:"template"`
+	template *template.Template
+}
+
+func (c *EmailConfiguration) validate() {
+	if c.Host == "" || c.From == "" {
+		commons.Fatalf("Email notifications require an SMTP host and a sender address")
+	}
+	if c.Port < 0 || c.Port > 65535 {
+		commons.Fatalf("Invalid SMTP port: %d", c.Port)
+	}
+	c.template = parseTemplate("email", c.Template)
+}
+
+func (c *EmailConfiguration) getName() string {
+	return "Email"
+}
+
+func (c *EmailConfiguration) notifiesErrors() bool {
+	return true
+}
+
+// Strategies may override the default recipients
+func (c *EmailConfiguration) getRecipients(strategy string) []string {
+	s := findStrategy(strategy)
+	if s != nil && len(s.EmailRecipients) > 0 {
+		return s.EmailRecipients
+	}
+	return c.To
+}
+
+func (c *EmailConfiguration) send(n notification) error {
+	recipients := c.getRecipients(n.Result.Strategy)
+	if len(recipients) == 0 {
+		return nil
+	}
+	text, err := n.format(c.template, n.getText)
+	if err != nil {
+		return err
+	}
+	var message strings.Builder
+	fmt.Fprintf(&message, "From: %s\r\n", c.From)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(recipients, ", "))
+	fmt.Fprintf(&message, "Subject: coinage %s\r\n", n.getTitle())
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	message.WriteString(strings.ReplaceAll(text, "\n", "\r\n"))
+	message.WriteString("\r\n")
+	return c.sendMail(recipients, []byte(message.String()))
+}
+
+// Port 465 style implicit TLS has to be requested explicitly, otherwise STARTTLS is used if the server offers it
+func (c *EmailConfiguration) sendMail(recipients []string, message []byte) error {
+	port := c.Port
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+	address := net.JoinHostPort(c.Host, strconv.Itoa(port))
+	var auth smtp.Auth
+	if c.Username != "" {
+		auth = smtp.PlainAuth("", c.Username, c.Password, c.Host)
+	}
+	if !c.TLS {
+		return smtp.SendMail(address, auth, c.From, recipients, message)
+	}
+	dialer := &net.Dialer{
+		Timeout: hookTimeout,
+	}
+	connection, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{ServerName: c.Host})
+	if err != nil {
+		return err
+	}
+	client, err := smtp.NewClient(connection, c.Host)
+	if err != nil {
+		connection.Close()
+		return err
+	}
+	defer client.Close()
+	if auth != nil {
+		err = client.Auth(auth)
+		if err != nil {
+			return err
+		}
+	}
+	err = client.Mail(c.From)
+	if err != nil {
+		return err
+	}
+	for _, recipient := range recipients {
+		err = client.Rcpt(recipient)
+		if err != nil {
+			return err
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(message)
+	if err != nil {
+		return err
+	}
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+	return client.Quit()
+}
\ No newline at end of file
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	slopeRising = "rising"
+	slopeFalling = "falling"
+)
+
+type EMASlopeConfiguration struct {
+	Period int `yaml:"period"`
+	Candles int `yaml:"candles"`
+	IntervalMinutes int `yaml:"intervalMinutes"`
+	Direction string `yaml:"direction"`
+	MinSlope *float64 `yaml:"minSlope"`
+}
+
+func (c *EMASlopeConfiguration) validate(strategy string) {
+	if c.Period <= 0 || c.Candles <= 0 {
+		commons.Fatalf("Invalid EMA slope parameters for strategy %s", strategy)
+	}
+	if c.Direction != "" && c.Direction != slopeRising && c.Direction != slopeFalling {
+		commons.Fatalf("Unknown EMA slope direction for strategy %s: %s", strategy, c.Direction)
+	}
+	if c.MinSlope != nil && *c.MinSlope < 0 {
+		commons.Fatalf("Invalid minimum EMA slope for strategy %s", strategy)
+	}
+	validateIndicatorInterval(c.IntervalMinutes, "EMA slope", strategy)
+}
+
+// The EMA is seeded the same way as the moving average crossovers and then extended by the candles the slope is measured over
+func (c *EMASlopeConfiguration) getBarCount() int {
+	return 2 * c.Period + c.Candles
+}
+
+func (c *EMASlopeConfiguration) getLookback() time.Duration {
+	return time.Duration(c.getBarCount() + 1) * getIndicatorInterval(c.IntervalMinutes)
+}
+
+// The slope is the change of the EMA over the candles in percent, without an explicit direction it has to point in the direction of the strategy
+func (c *EMASlopeConfiguration) getSlope(records []ohlcRecord, up bool) (float64, bool, bool) {
+	bars := getBars(records, getIndicatorInterval(c.IntervalMinutes))
+	if len(bars) < c.getBarCount() {
+		return 0, false, false
+	}
+	closes := []float64{}
+	for _, bar := range bars[len(bars) - c.getBarCount():] {
+		closes = append(closes, bar.close)
+	}
+	series := getEMASeries(closes, c.Period)
+	last := len(series) - 1
+	slope := (series[last] / series[last - c.Candles] - 1.0) * percent
+	minSlope := 0.0
+	if c.MinSlope != nil {
+		minSlope = *c.MinSlope
+	}
+	rising := up
+	if c.Direction != "" {
+		rising = c.Direction == slopeRising
+	}
+	if rising {
+		return slope, slope > minSlope, true
+	} else {
+		return slope, slope < -minSlope, true
+	}
+}
\ No newline at end of file
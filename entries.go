@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+func (s *Strategy) getEntryOrders() map[string]event {
+	orders := map[string]event{}
+	for orderID, order := range getState().orders {
+		if order.Strategy == s.Name && (order.Reason == orderMarket || order.Reason == orderLimit) {
+			orders[orderID] = order
+		}
+	}
+	return orders
+}
+
+// Returns the quantity and the quote quantity of the fills already recorded for an order
+func (s *engineState) getRecordedFills(orderID string) (float64, float64) {
+	quantity := 0.0
+	quote := 0.0
+	for _, fill := range s.fills {
+		if fill.OrderID == orderID {
+			quantity += fill.Quantity
+			quote += fill.Quantity * fill.Price
+		}
+	}
+	return quantity, quote
+}
+
+func syncEntryOrders(filter string) {
+	for i := range configuration.Strategies {
+		strategy := &configuration.Strategies[i]
+		if !strategy.matchesFilter(filter) || strategy.Order == nil || len(strategy.getEntryOrders()) == 0 {
+			continue
+		}
+		result := strategy.getEmptyResult()
+		err := strategy.syncEntryOrders(result)
+		for _, message := range result.Messages {
+			fmt.Fprintf(statusOutput, "%s: %s\n", strategy.Name, message)
+		}
+		if err != nil {
+			fmt.Fprintf(statusOutput, "%s: failed to synchronize entry orders: %v\n", strategy.Name, err)
+		}
+	}
+}
+
+func (s *Strategy) syncEntryOrders(result *EvaluationResult) error {
+	executor, err := s.newExecutor()
+	if err != nil {
+		return err
+	}
+	defer func () {
+		for _, request := range executor.DryRunRequests() {
+			result.addMessage("Dry run: %s", request)
+		}
+	}()
+	for orderID, pending := range s.getEntryOrders() {
+		order, err := executor.GetOrder(s.Currency, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to query order %s: %v", orderID, err)
+		}
+		err = s.processEntryOrder(executor, pending, order, result)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Orders report their cumulative executed quantity so only the difference to the recorded fills is new
+func (s *Strategy) recordEntryFills(pending event, order Order) {
+	quantity, quote := getState().getRecordedFills(order.ID)
+	delta := order.ExecutedQuantity - quantity
+	if delta <= 0 {
+		// Orders whose fills were all recorded as partial fills are completed without an empty fill
+		if order.Status == orderStatusFilled {
+			appendEvent(event{
+				Type: eventOrderCompleted,
+				Strategy: s.Name,
+				Currency: s.Currency,
+				Up: s.Up,
+				OrderID: order.ID,
+				ClientOrderID: pending.ClientOrderID,
+			})
+		}
+		return
+	}
+	fillType := eventPartialFill
+	if order.Status == orderStatusFilled {
+		fillType = eventFill
+	}
+	price := (order.AveragePrice * order.ExecutedQuantity - quote) / delta
+	appendEvent(event{
+		Type: fillType,
+		Strategy: s.Name,
+		Currency: s.Currency,
+		Up: s.Up,
+		Buy: s.Up,
+		OrderID: order.ID,
+		ClientOrderID: pending.ClientOrderID,
+		Price: price,
+		SignalPrice: pending.SignalPrice,
+		Quantity: delta,
+		Momentum: pending.Momentum,
+	})
+	p := s.getPosition()
+	if p == nil {
+		appendEvent(event{
+			Type: eventPositionOpened,
+			Strategy: s.Name,
+			Currency: s.Currency,
+			Up: s.Up,
+			Price: price,
+			Quantity: delta,
+		})
+		return
+	}
+	total := p.Quantity + delta
+	averagePrice := (p.EntryPrice * p.Quantity + price * delta) / total
+	appendEvent(event{
+		Type: eventPositionUpdated,
+		Strategy: s.Name,
+		Currency: s.Currency,
+		Up: s.Up,
+		Price: averagePrice,
+		Quantity: total,
+	})
+}
+
+// Limit orders that haven't been filled completely within the timeout are cancelled and optionally chased at the current price
+func (s *Strategy) processEntryOrder(executor Executor, pending event, order Order, result *EvaluationResult) error {
+	s.recordEntryFills(pending, order)
+	switch order.Status {
+	case orderStatusFilled:
+		return s.completeEntry(executor, result)
+	case orderStatusCancelled:
+		s.recordEntryCancelled(order.ID)
+		return s.completeEntry(executor, result)
+	}
+	timeout := time.Duration(s.Order.TimeoutMinutes) * time.Minute
+	if timeout == 0 || time.Since(pending.Time) < timeout {
+		result.addMessage("Order %s status: %s, %s of %s filled", order.ID, order.Status, formatDecimal(order.ExecutedQuantity), formatDecimal(order.Quantity))
+		return nil
+	}
+	err := executor.CancelOrder(s.Currency, order.ID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel order %s: %v", order.ID, err)
+	}
+	if dryRun {
+		return nil
+	}
+	// Fills may have occurred between the last query and the cancellation
+	order, err = executor.GetOrder(s.Currency, order.ID)
+	if err != nil {
+		return fmt.Errorf("failed to query order %s: %v", order.ID, err)
+	}
+	s.recordEntryFills(pending, order)
+	if order.Status == orderStatusFilled {
+		return s.completeEntry(executor, result)
+	}
+	s.recordEntryCancelled(order.ID)
+	result.addMessage("Cancelled order %s after %d minutes with %s of %s filled", order.ID, s.Order.TimeoutMinutes, formatDecimal(order.ExecutedQuantity), formatDecimal(order.Quantity))
+	if s.Order.Chase && suspensionReason == "" {
+		chased, err := s.chaseEntry(executor, pending, order, result)
+		if err != nil || chased {
+			return err
+		}
+	}
+	return s.completeEntry(executor, result)
+}
+
+func (s *Strategy) recordEntryCancelled(orderID string) {
+	appendEvent(event{
+		Type: eventOrderCancelled,
+		Strategy: s.Name,
+		Currency: s.Currency,
+		Up: s.Up,
+		OrderID: orderID,
+	})
+}
+
+// The remaining quantity is placed again relative to the current price, which may be rejected if it is too small
+func (s *Strategy) chaseEntry(executor Executor, pending event, cancelled Order, result *EvaluationResult) (bool, error) {
+	filters, err := executor.GetSymbolFilters(s.Currency)
+	if err != nil {
+		return false, err
+	}
+	price, err := getLastPrice(s.Currency)
+	if err != nil {
+		return false, err
+	}
+	price, _ = strconv.ParseFloat(filters.formatPrice(s.getLimitPrice(price)), 64)
+	quantityString := filters.formatQuantity(cancelled.Quantity - cancelled.ExecutedQuantity)
+	err = filters.check(quantityString, price)
+	if err != nil {
+		result.addMessage("Remaining quantity %s can't be chased: %v", quantityString, err)
+		return false, nil
+	}
+	quantity, _ := strconv.ParseFloat(quantityString, 64)
+	clientOrderID := getClientOrderID(s.Name, "chase " + cancelled.ID, pending.Time)
+	submitted, status, err := s.isSubmitted(executor, clientOrderID)
+	if err != nil {
+		return false, err
+	}
+	if submitted {
+		result.addMessage("Chase order %s was already submitted (%s)", clientOrderID, status)
+		return true, nil
+	}
+	request := OrderRequest{
+		Symbol: s.Currency,
+		ClientOrderID: clientOrderID,
+		Buy: s.Up,
+		Type: orderLimit,
+		Quantity: quantity,
+		Price: price,
+	}
+	order, err := executor.PlaceOrder(request)
+	if err != nil {
+		return false, fmt.Errorf("failed to place chase order: %v", err)
+	}
+	chase := event{
+		Time: time.Now().UTC(),
+		Type: eventOrder,
+		Strategy: s.Name,
+		Currency: s.Currency,
+		Up: s.Up,
+		Buy: s.Up,
+		OrderID: order.ID,
+		ClientOrderID: clientOrderID,
+		Price: price,
+		SignalPrice: pending.SignalPrice,
+		Quantity: quantity,
+		Momentum: pending.Momentum,
+		Reason: orderLimit,
+	}
+	appendEvent(chase)
+	result.addMessage("Chasing remaining %s at %.4f with order %s", quantityString, price, order.ID)
+	return true, s.processEntryOrder(executor, chase, order, result)
+}
+
+// Protective orders are only placed once the entry is complete so that they cover the entire position
+func (s *Strategy) completeEntry(executor Executor, result *EvaluationResult) error {
+	p := s.getPosition()
+	if p == nil || len(s.getProtectiveOrders()) > 0 {
+		return nil
+	}
+	result.addMessage("Filled %s at an average price of %.4f", formatDecimal(p.Quantity), p.EntryPrice)
+	return s.placeProtectiveOrders(executor, p.EntryPrice, p.Quantity, result)
+}
+
+// Outstanding entry orders are cancelled before exiting so that the position can't grow after it has been closed
+func (s *Strategy) cancelEntryOrders(executor Executor) error {
+	for orderID, pending := range s.getEntryOrders() {
+		err := executor.CancelOrder(s.Currency, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to cancel order %s: %v", orderID, err)
+		}
+		if dryRun {
+			continue
+		}
+		order, err := executor.GetOrder(s.Currency, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to query order %s: %v", orderID, err)
+		}
+		s.recordEntryFills(pending, order)
+		if order.Status != orderStatusFilled {
+			s.recordEntryCancelled(orderID)
+		}
+	}
+	return nil
+}
\ No newline at end of file
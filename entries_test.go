@@ -0,0 +1,82 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func isClose(a float64, b float64) bool {
+	return math.Abs(a - b) < 1e-9
+}
+
+func TestRecordEntryFills(t *testing.T) {
+	tests := []struct {
+		name string
+		updates []Order
+		fills []float64
+		entryPrice float64
+		pending bool
+	}{
+		{"filled at once", []Order{{Status: orderStatusFilled, ExecutedQuantity: 1, AveragePrice: 100}}, []float64{1}, 100, false},
+		{"partially filled", []Order{{Status: orderStatusPartiallyFilled, ExecutedQuantity: 0.4, AveragePrice: 100}}, []float64{0.4}, 100, true},
+		{"filled in two steps", []Order{
+			{Status: orderStatusPartiallyFilled, ExecutedQuantity: 0.4, AveragePrice: 100},
+			{Status: orderStatusFilled, ExecutedQuantity: 1, AveragePrice: 101.2},
+		}, []float64{0.4, 0.6}, 101.2, false},
+		{"filled without new fills", []Order{
+			{Status: orderStatusPartiallyFilled, ExecutedQuantity: 1, AveragePrice: 100},
+			{Status: orderStatusFilled, ExecutedQuantity: 1, AveragePrice: 100},
+		}, []float64{1}, 100, false},
+		{"repeated status", []Order{
+			{Status: orderStatusPartiallyFilled, ExecutedQuantity: 0.4, AveragePrice: 100},
+			{Status: orderStatusPartiallyFilled, ExecutedQuantity: 0.4, AveragePrice: 100},
+		}, []float64{0.4}, 100, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func (t *testing.T) {
+			setupEventLog(t)
+			s := getBreakEvenStrategy()
+			pending := event{
+				Type: eventOrder,
+				Strategy: s.Name,
+				Currency: s.Currency,
+				Up: s.Up,
+				Buy: s.Up,
+				OrderID: "entry",
+				Quantity: 1,
+				Reason: orderLimit,
+			}
+			appendEvent(pending)
+			for _, order := range test.updates {
+				order.ID = "entry"
+				order.Quantity = 1
+				s.recordEntryFills(pending, order)
+			}
+			fills := []float64{}
+			for _, e := range loadEvents() {
+				if e.Type == eventFill || e.Type == eventPartialFill {
+					fills = append(fills, e.Quantity)
+				}
+			}
+			if len(fills) != len(test.fills) {
+				t.Fatalf("expected fills %v, got %v", test.fills, fills)
+			}
+			for i := range fills {
+				if !isClose(fills[i], test.fills[i]) {
+					t.Fatalf("expected fills %v, got %v", test.fills, fills)
+				}
+			}
+			_, exists := getState().orders["entry"]
+			if exists != test.pending {
+				t.Errorf("expected the order to be pending: %t", test.pending)
+			}
+			p := s.getPosition()
+			if p == nil || !isClose(p.Quantity, test.updates[len(test.updates) - 1].ExecutedQuantity) {
+				t.Errorf("unexpected position: %+v", p)
+			}
+			if p != nil && !isClose(p.EntryPrice, test.entryPrice) {
+				t.Errorf("expected an entry price of %.4f, got %.4f", test.entryPrice, p.EntryPrice)
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+// Set by --at to evaluate the strategies as if the current time were a past timestamp
+var evaluationTime time.Time
+
+func setEvaluationTime(value string) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		commons.Fatalf("Invalid evaluation time: %s", value)
+	}
+	t = t.UTC()
+	if t.After(time.Now()) {
+		commons.Fatalf("Evaluation time must not be in the future: %s", value)
+	}
+	if executeOrders || paperTrading || daemonMode {
+		commons.Fatalf("Evaluations at a past time can't be combined with order execution, paper trading or daemon mode")
+	}
+	evaluationTime = t
+}
+
+func isHistoricalEvaluation() bool {
+	return !evaluationTime.IsZero()
+}
+
+func getNow() time.Time {
+	if isHistoricalEvaluation() {
+		return evaluationTime
+	}
+	return time.Now().UTC()
+}
+
+// Candles that were still in progress at a past evaluation time are excluded since their closes weren't known yet
+func getCandleEndTime() string {
+	if isHistoricalEvaluation() {
+		return commons.Int64ToString(evaluationTime.Truncate(candleInterval).UnixMilli() - 1)
+	}
+	return commons.Int64ToString(time.Now().UTC().UnixMilli())
+}
\ No newline at end of file
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	eventsFile = "events.jsonl"
+	eventSignal = "signal"
+	eventOrder = "order"
+	eventOrderCancelled = "orderCancelled"
+	eventOrderCompleted = "orderCompleted"
+	eventFill = "fill"
+	eventPartialFill = "partialFill"
+	eventPositionOpened = "positionOpened"
+	eventPositionUpdated = "positionUpdated"
+	eventPositionClosed = "positionClosed"
+)
+
+type event struct {
+	Time time.Time `json:"time"`
+	Type string `json:"type"`
+	Strategy string `json:"strategy"`
+	Currency string `json:"currency"`
+	Up bool `json:"up"`
+	Buy bool `json:"buy,omitempty"`
+	OrderID string `json:"orderId,omitempty"`
+	ClientOrderID string `json:"clientOrderId,omitempty"`
+	Price float64 `json:"price,omitempty"`
+	SignalPrice float64 `json:"signalPrice,omitempty"`
+	Quantity float64 `json:"quantity,omitempty"`
+	Momentum float64 `json:"momentum,omitempty"`
+	EntryTime time.Time `json:"entryTime,omitzero"`
+	Stop float64 `json:"stop,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type position struct {
+	Strategy string `json:"strategy"`
+	Currency string `json:"currency"`
+	Up bool `json:"up"`
+	EntryTime time.Time `json:"entryTime"`
+	EntryPrice float64 `json:"entryPrice"`
+	Quantity float64 `json:"quantity"`
+	Stop float64 `json:"stop,omitempty"`
+}
+
+type closedPosition struct {
+	position
+	ExitTime time.Time `json:"exitTime"`
+	ExitPrice float64 `json:"exitPrice"`
+}
+
+type engineState struct {
+	signals []event
+	orders map[string]event
+	// Every order event by client order ID, including the ones that have since been filled or cancelled
+	clientOrders map[string]event
+	fills []event
+	positions map[string]*position
+	closed []closedPosition
+}
+
+func newEngineState() engineState {
+	return engineState{
+		orders: map[string]event{},
+		clientOrders: map[string]event{},
+		positions: map[string]*position{},
+	}
+}
+
+func appendEvent(e event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	// The returns depend on the position, which has to be looked up before the event is written
+	returns := getJournalReturns(getState(), e)
+	path := filepath.Join(dataDirectory, eventsFile)
+	err := os.MkdirAll(dataDirectory, 0755)
+	if err != nil {
+		commons.Fatalf("Failed to create directory %s: %v", dataDirectory, err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND | os.O_CREATE | os.O_WRONLY, 0644)
+	if err != nil {
+		commons.Fatalf("Failed to open event log: %v", err)
+	}
+	defer file.Close()
+	data, err := json.Marshal(e)
+	if err != nil {
+		commons.Fatalf("Failed to serialize event: %v", err)
+	}
+	data = append(data, '\n')
+	_, err = file.Write(data)
+	if err != nil {
+		commons.Fatalf("Failed to write to event log: %v", err)
+	}
+	err = file.Sync()
+	if err != nil {
+		commons.Fatalf("Failed to sync event log: %v", err)
+	}
+	// Only events that made it into the event log are journaled
+	writeJournal(e, returns)
+	if currentState != nil {
+		currentState.apply(e)
+	}
+}
+
+func loadEvents() []event {
+	path := filepath.Join(dataDirectory, eventsFile)
+	events := []event{}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return events
+	}
+	if err != nil {
+		commons.Fatalf("Failed to open event log: %v", err)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var e event
+		err := json.Unmarshal(line, &e)
+		if err != nil {
+			// A crash in the middle of a write can only leave a partial line at the very end of the log
+			fmt.Printf("Ignoring malformed event: %s\n", string(line))
+			continue
+		}
+		events = append(events, e)
+	}
+	err = scanner.Err()
+	if err != nil {
+		commons.Fatalf("Failed to read event log: %v", err)
+	}
+	return events
+}
+
+// Derives the state after applying all events up to but not including the specified time
+func replayEvents(events []event, until time.Time) engineState {
+	state := newEngineState()
+	for _, e := range events {
+		if !until.IsZero() && !e.Time.Before(until) {
+			break
+		}
+		state.apply(e)
+	}
+	return state
+}
+
+func loadState() engineState {
+	return replayEvents(loadEvents(), time.Time{})
+}
+
+func (s *engineState) apply(e event) {
+	switch e.Type {
+	case eventSignal:
+		s.signals = append(s.signals, e)
+	case eventOrder:
+		s.orders[e.OrderID] = e
+		if e.ClientOrderID != "" {
+			s.clientOrders[e.ClientOrderID] = e
+		}
+	case eventOrderCancelled, eventOrderCompleted:
+		delete(s.orders, e.OrderID)
+	case eventFill:
+		delete(s.orders, e.OrderID)
+		s.fills = append(s.fills, e)
+	case eventPartialFill:
+		s.fills = append(s.fills, e)
+	case eventPositionOpened:
+		s.positions[e.Strategy] = &position{
+			Strategy: e.Strategy,
+			Currency: e.Currency,
+			Up: e.Up,
+			EntryTime: e.Time,
+			EntryPrice: e.Price,
+			Quantity: e.Quantity,
+			Stop: e.Stop,
+		}
+	case eventPositionUpdated:
+		p, exists := s.positions[e.Strategy]
+		if exists {
+			if e.Price != 0 {
+				p.EntryPrice = e.Price
+			}
+			if e.Quantity != 0 {
+				p.Quantity = e.Quantity
+			}
+			if e.Stop != 0 {
+				p.Stop = e.Stop
+			}
+		}
+	case eventPositionClosed:
+		p, exists := s.positions[e.Strategy]
+		if exists {
+			s.closed = append(s.closed, closedPosition{
+				position: *p,
+				ExitTime: e.Time,
+				ExitPrice: e.Price,
+			})
+		}
+		delete(s.positions, e.Strategy)
+	}
+}
+
+// Signals are identified by their strategy, the entry window they were emitted for and the reason they weren't acted upon
+func (s *engineState) hasSignal(strategy string, entryWindow time.Time, reason string) bool {
+	for _, signal := range s.signals {
+		signalWindow := signal.EntryTime
+		if signalWindow.IsZero() {
+			signalWindow = getEntryWindow(signal.Time)
+		}
+		if signal.Strategy == strategy && signal.Reason == reason && signalWindow.Equal(entryWindow) {
+			return true
+		}
+	}
+	return false
+}
+
+func runReplay(arguments []string) {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	dayString := flags.String("day", "", "Replay the events of this day (YYYY-MM-DD), defaults to today")
+	flags.Parse(arguments)
+	now := time.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if *dayString != "" {
+		var err error
+		day, err = time.Parse(time.DateOnly, *dayString)
+		if err != nil {
+			commons.Fatalf("Invalid day: %s", *dayString)
+		}
+	}
+	end := day.AddDate(0, 0, 1)
+	events := loadEvents()
+	initialState := replayEvents(events, day)
+	fmt.Printf("\nState at the start of %s:\n", day.Format(time.DateOnly))
+	initialState.print()
+	fmt.Printf("Events:\n")
+	for _, e := range events {
+		if !e.Time.Before(day) && e.Time.Before(end) {
+			e.print()
+		}
+	}
+	finalState := replayEvents(events, end)
+	fmt.Printf("\nState at the end of %s:\n", day.Format(time.DateOnly))
+	finalState.print()
+}
+
+func (e *event) print() {
+	fmt.Printf("\t%s UTC %s %s", commons.GetTimeString(e.Time), e.Type, e.Strategy)
+	if e.OrderID != "" {
+		fmt.Printf(" order %s", e.OrderID)
+	}
+	if e.Quantity != 0 {
+		fmt.Printf(" quantity %g", e.Quantity)
+	}
+	if e.Price != 0 {
+		fmt.Printf(" price %.4f", e.Price)
+	}
+	if e.Stop != 0 {
+		fmt.Printf(" stop %.4f", e.Stop)
+	}
+	if e.Reason != "" {
+		fmt.Printf(" (%s)", e.Reason)
+	}
+	fmt.Printf("\n")
+}
+
+func (s *engineState) print() {
+	strategies := []string{}
+	for strategy := range s.positions {
+		strategies = append(strategies, strategy)
+	}
+	sort.Strings(strategies)
+	fmt.Printf("\tOpen positions: %d\n", len(strategies))
+	for _, strategy := range strategies {
+		p := s.positions[strategy]
+		fmt.Printf("\t\t%s: %s %g at %.4f since %s UTC\n", p.Strategy, p.Currency, p.Quantity, p.EntryPrice, commons.GetTimeString(p.EntryTime))
+	}
+	fmt.Printf("\tOpen orders: %d\n", len(s.orders))
+	fmt.Printf("\tSignals: %d\n", len(s.signals))
+	fmt.Printf("\tFills: %d\n\n", len(s.fills))
+}
\ No newline at end of file
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	orderMarket = "market"
+	orderLimit = "limit"
+)
+
+type OrderConfiguration struct {
+	Type string `yaml:"type"`
+	Exchange string `yaml:"exchange"`
+	Market string `yaml:"market"`
+	Account string `yaml:"account"`
+	Leverage int `yaml:"leverage"`
+	MarginType string `yaml:"marginType"`
+	Quantity *float64 `yaml:"quantity"`
+	QuoteQuantity *float64 `yaml:"quoteQuantity"`
+	BalancePercentage *float64 `yaml:"balancePercentage"`
+	LimitOffset float64 `yaml:"limitOffset"`
+	TimeoutMinutes int `yaml:"timeoutMinutes"`
+	Chase bool `yaml:"chase"`
+}
+
+var (
+	executeOrders bool
+	dryRun bool
+)
+
+func (o *OrderConfiguration) validate(strategy string) {
+	if o.Type != orderMarket && o.Type != orderLimit {
+		commons.Fatalf("Invalid order type for strategy %s: %s", strategy, o.Type)
+	}
+	if o.Exchange != "" && o.Exchange != exchangeBinance {
+		commons.Fatalf("Unsupported exchange for strategy %s: %s", strategy, o.Exchange)
+	}
+	sizes := 0
+	for _, size := range []*float64{o.Quantity, o.QuoteQuantity, o.BalancePercentage} {
+		if size != nil {
+			sizes++
+		}
+	}
+	if sizes != 1 {
+		commons.Fatalf("Strategy %s must specify exactly one of order quantity, quote quantity or balance percentage", strategy)
+	}
+	if o.Quantity != nil && *o.Quantity <= 0 || o.QuoteQuantity != nil && *o.QuoteQuantity <= 0 {
+		commons.Fatalf("Invalid order quantity for strategy %s", strategy)
+	}
+	if o.BalancePercentage != nil && (*o.BalancePercentage <= 0 || *o.BalancePercentage > percent) {
+		commons.Fatalf("Invalid balance percentage for strategy %s", strategy)
+	}
+	if o.LimitOffset < 0 {
+		commons.Fatalf("Invalid limit offset for strategy %s", strategy)
+	}
+	if o.TimeoutMinutes < 0 || o.TimeoutMinutes > 0 && o.Type != orderLimit {
+		commons.Fatalf("Invalid order timeout for strategy %s", strategy)
+	}
+	if o.Chase && o.TimeoutMinutes == 0 {
+		commons.Fatalf("Chasing orders requires a timeout for strategy %s", strategy)
+	}
+	o.validateFutures(strategy)
+}
+
+// Spot accounts can't hold short positions, strategies expecting falling prices would sell assets they don't own
+func (s *Strategy) validateOrder() {
+	s.Order.validate(s.Name)
+	if !s.Up && !s.Order.isFutures() {
+		commons.Fatalf("Short strategy %s requires the futures market", s.Name)
+	}
+}
+
+func (s *Strategy) getOrderSide() string {
+	if s.Up {
+		return "BUY"
+	} else {
+		return "SELL"
+	}
+}
+
+// Limit orders are placed below the current price for buys and above it for sells
+func (s *Strategy) getLimitPrice(price float64) float64 {
+	offset := s.Order.LimitOffset / percent
+	if s.Up {
+		return price * (1.0 - offset)
+	} else {
+		return price * (1.0 + offset)
+	}
+}
+
+// Spot market orders can be sized in the quote currency directly, all other orders are converted to a base quantity
+func (s *Strategy) setOrderQuantity(executor Executor, filters symbolFilters, request *OrderRequest) error {
+	if s.Order.QuoteQuantity != nil && s.Order.Type == orderMarket && !s.Order.isFutures() {
+		request.QuoteQuantity = *s.Order.QuoteQuantity
+		return filters.checkNotional(request.QuoteQuantity)
+	}
+	var quantity float64
+	if s.Order.Quantity != nil {
+		quantity = *s.Order.Quantity
+	} else if s.Order.QuoteQuantity != nil {
+		quantity = *s.Order.QuoteQuantity / request.Price
+	} else {
+		notional, err := s.getBalanceNotional(executor, filters)
+		if err != nil {
+			return err
+		}
+		quantity = notional / request.Price
+	}
+	quantityString := filters.formatQuantity(quantity)
+	request.Quantity, _ = strconv.ParseFloat(quantityString, 64)
+	return filters.check(quantityString, request.Price)
+}
+
+func (s *Strategy) execute(result *EvaluationResult) error {
+	price := result.CurrentPrice
+	momentum := *result.Momentum
+	executor, err := s.newExecutor()
+	if err != nil {
+		return err
+	}
+	defer func () {
+		for _, request := range executor.DryRunRequests() {
+			result.addMessage("Dry run: %s", request)
+		}
+	}()
+	err = executor.Configure(s.Currency, s.Order)
+	if err != nil {
+		return err
+	}
+	filters, err := executor.GetSymbolFilters(s.Currency)
+	if err != nil {
+		return err
+	}
+	if len(s.getEntryOrders()) > 0 {
+		result.addMessage("An entry order for this strategy is still open")
+		return nil
+	}
+	entryWindow := result.getEntryWindow()
+	clientOrderID := getClientOrderID(s.Name, "entry", entryWindow)
+	submitted, status, err := s.isSubmitted(executor, clientOrderID)
+	if err != nil {
+		return err
+	}
+	if submitted {
+		result.addMessage("Order %s for this signal was already submitted (%s)", clientOrderID, status)
+		return nil
+	}
+	request := OrderRequest{
+		Symbol: s.Currency,
+		ClientOrderID: clientOrderID,
+		Buy: s.Up,
+		Type: s.Order.Type,
+		Price: price,
+	}
+	if s.Order.Type == orderLimit {
+		request.Price, _ = strconv.ParseFloat(filters.formatPrice(s.getLimitPrice(price)), 64)
+	}
+	err = s.setOrderQuantity(executor, filters, &request)
+	if err != nil {
+		return err
+	}
+	order, err := executor.PlaceOrder(request)
+	if err != nil {
+		return fmt.Errorf("failed to place order: %v", err)
+	}
+	if dryRun {
+		// Protective orders are previewed as if the entry had been filled at the order price
+		quantity := request.Quantity
+		if quantity == 0 {
+			quantity = request.QuoteQuantity / request.Price
+		}
+		return s.placeProtectiveOrders(executor, request.Price, quantity, result)
+	}
+	pending := event{
+		Time: time.Now().UTC(),
+		Type: eventOrder,
+		Strategy: s.Name,
+		Currency: s.Currency,
+		Up: s.Up,
+		Buy: s.Up,
+		OrderID: order.ID,
+		ClientOrderID: clientOrderID,
+		Price: request.Price,
+		SignalPrice: price,
+		Quantity: order.Quantity,
+		Momentum: momentum,
+		Reason: s.Order.Type,
+	}
+	appendEvent(pending)
+	result.addMessage("Placed %s %s %s order %s for %s", s.getMarketDescription(), s.Order.Type, s.getOrderSide(), order.ID, s.Currency)
+	return s.processEntryOrder(executor, pending, order, result)
+}
\ No newline at end of file
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// Invalid configurations terminate the process, so each case is validated in a child process
+func TestValidateOrder(t *testing.T) {
+	if os.Getenv("COINAGE_VALIDATE_MARKET") != "" {
+		quantity := 1.0
+		s := &Strategy{
+			Name: "validate",
+			Up: os.Getenv("COINAGE_VALIDATE_UP") == "true",
+			Order: &OrderConfiguration{
+				Type: orderMarket,
+				Market: os.Getenv("COINAGE_VALIDATE_MARKET"),
+				Quantity: &quantity,
+			},
+		}
+		s.validateOrder()
+		return
+	}
+	tests := []struct {
+		name string
+		market string
+		up bool
+		valid bool
+	}{
+		{"spot long", marketSpot, true, true},
+		{"spot short", marketSpot, false, false},
+		{"futures long", marketFutures, true, true},
+		{"futures short", marketFutures, false, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func (t *testing.T) {
+			up := "false"
+			if test.up {
+				up = "true"
+			}
+			command := exec.Command(os.Args[0], "-test.run=^TestValidateOrder$")
+			command.Env = append(os.Environ(), "COINAGE_VALIDATE_MARKET=" + test.market, "COINAGE_VALIDATE_UP=" + up)
+			output, err := command.CombinedOutput()
+			var exitError *exec.ExitError
+			if err != nil && !errors.As(err, &exitError) {
+				t.Fatalf("failed to run validation: %v", err)
+			}
+			if (err == nil) != test.valid {
+				t.Errorf("expected valid to be %t, got: %s", test.valid, output)
+			}
+		})
+	}
+}
\ No newline at end of file
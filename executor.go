@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	orderStopLoss = "stopLoss"
+	orderTakeProfit = "takeProfit"
+	orderStatusNew = "new"
+	orderStatusPartiallyFilled = "partiallyFilled"
+	orderStatusFilled = "filled"
+	orderStatusCancelled = "cancelled"
+)
+
+type ExchangeConfiguration struct {
+	Testnet bool `yaml:"testnet"`
+}
+
+type OrderRequest struct {
+	Symbol string
+	ClientOrderID string
+	Buy bool
+	Type string
+	Quantity float64
+	QuoteQuantity float64
+	Price float64
+	StopPrice float64
+	ReduceOnly bool
+}
+
+type Order struct {
+	ID string
+	ClientOrderID string
+	Symbol string
+	Type string
+	Status string
+	Buy bool
+	Price float64
+	Quantity float64
+	ExecutedQuantity float64
+	AveragePrice float64
+}
+
+type Balance struct {
+	Asset string
+	Free float64
+	Locked float64
+}
+
+// Long positions have a positive amount and short positions a negative one
+type ExchangePosition struct {
+	Amount float64
+	EntryPrice float64
+}
+
+// Strategies only interact with exchanges through this interface so that further exchanges can be added without touching them
+type Executor interface {
+	Configure(symbol string, order *OrderConfiguration) error
+	GetSymbolFilters(symbol string) (symbolFilters, error)
+	GetBalance(asset string) (float64, error)
+	GetBalances() ([]Balance, error)
+	GetOpenOrders(symbol string) ([]Order, error)
+	GetOrder(symbol string, orderID string) (Order, error)
+	FindOrder(symbol string, clientOrderID string) (*Order, error)
+	PlaceOrder(request OrderRequest) (Order, error)
+	PlaceOCO(stopLoss OrderRequest, takeProfit OrderRequest) ([]Order, error)
+	CancelOrder(symbol string, orderID string) error
+	ClosePosition(p *position, clientOrderID string) (Order, error)
+	GetPosition(symbol string) (ExchangePosition, error)
+	SupportsOCO() bool
+	DryRunRequests() []string
+}
+
+func (c *Configuration) validateExchanges() {
+	for exchange := range c.Exchanges {
+		if exchange != exchangeBinance {
+			commons.Fatalf("Unsupported exchange: %s", exchange)
+		}
+	}
+}
+
+// Market data is always retrieved from the live exchange, the testnet only affects account and order requests
+func isTestnet(exchange string) bool {
+	c, exists := configuration.Exchanges[exchange]
+	return exists && c.Testnet
+}
+
+func (s *Strategy) newExecutor() (Executor, error) {
+	switch s.Order.Exchange {
+	case "", exchangeBinance:
+		return newBinanceExecutor(s.Order)
+	default:
+		return nil, fmt.Errorf("unsupported exchange: %s", s.Order.Exchange)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Exit orders that were cancelled, expired or rejected without a fill are retried up to this many times
+const maxExitAttempts = 5
+
+func (s *Strategy) getLiveDailyPnL() dailyPnL {
+	pnl := newDailyPnL()
+	for _, closed := range getState().closed {
+		if closed.Strategy == s.Name && closed.ExitPrice > 0 {
+			pnl.add(closed.ExitTime, s.getReturns(closed.EntryPrice, closed.ExitPrice))
+		}
+	}
+	return pnl
+}
+
+func closeExpiredPositions(filter string) {
+	now := time.Now().UTC()
+	for i := range configuration.Strategies {
+		strategy := &configuration.Strategies[i]
+		if !strategy.matchesFilter(filter) || strategy.HoldHours <= 0 || strategy.Order == nil {
+			continue
+		}
+		p := strategy.getPosition()
+		if p == nil {
+			continue
+		}
+		exitTime := p.EntryTime.Add(time.Duration(strategy.HoldHours) * time.Hour)
+		if now.Before(exitTime) {
+			continue
+		}
+		err := strategy.closePosition(p, exitHold)
+		if err != nil {
+			fmt.Fprintf(statusOutput, "%s: failed to close position: %v\n", strategy.Name, err)
+		}
+	}
+}
+
+// Positions are always closed with market orders since the holding period has already elapsed
+func (s *Strategy) closePosition(p *position, reason string) error {
+	executor, err := s.newExecutor()
+	if err != nil {
+		return err
+	}
+	defer func () {
+		for _, request := range executor.DryRunRequests() {
+			fmt.Fprintf(statusOutput, "%s: dry run: %s\n", s.Name, request)
+		}
+	}()
+	err = s.cancelEntryOrders(executor)
+	if err != nil {
+		return err
+	}
+	err = s.cancelProtectiveOrders(executor)
+	if err != nil {
+		return err
+	}
+	return s.submitExit(executor, p, reason)
+}
+
+func getExitClientOrderID(strategy string, entryTime time.Time, attempt int) string {
+	purpose := "exit"
+	if attempt > 0 {
+		purpose = fmt.Sprintf("exit|%d", attempt)
+	}
+	return getClientOrderID(strategy, purpose, entryTime)
+}
+
+// Attempts are numbered so that a restarted run finds the same client order IDs, the orders of earlier attempts are synchronized before the next one is placed
+func (s *Strategy) submitExit(executor Executor, p *position, reason string) error {
+	for attempt := range maxExitAttempts {
+		clientOrderID := getExitClientOrderID(s.Name, p.EntryTime, attempt)
+		existing, err := executor.FindOrder(s.Currency, clientOrderID)
+		if err != nil {
+			return fmt.Errorf("failed to check for existing order: %v", err)
+		}
+		_, recorded := getState().clientOrders[clientOrderID]
+		if existing == nil && !recorded {
+			return s.placeExit(executor, p, clientOrderID, reason)
+		}
+		if existing == nil {
+			// Binance eventually forgets cancelled orders without fills
+			s.recordExitCancellation(clientOrderID)
+		} else {
+			closed, err := s.syncExitOrder(executor, p, *existing, reason)
+			if err != nil || closed {
+				return err
+			}
+			if existing.Status != orderStatusCancelled {
+				fmt.Fprintf(statusOutput, "%s: exit order %s status: %s\n", s.Name, existing.ID, existing.Status)
+				return nil
+			}
+			p = s.getPosition()
+		}
+		fmt.Fprintf(statusOutput, "%s: exit order %s ended with %s remaining, retrying\n", s.Name, clientOrderID, formatDecimal(p.Quantity))
+	}
+	return fmt.Errorf("exit orders failed %d times", maxExitAttempts)
+}
+
+func (s *Strategy) placeExit(executor Executor, p *position, clientOrderID string, reason string) error {
+	order, err := executor.ClosePosition(p, clientOrderID)
+	if err != nil {
+		return fmt.Errorf("failed to place exit order: %v", err)
+	}
+	if dryRun {
+		return nil
+	}
+	appendEvent(event{
+		Type: eventOrder,
+		Strategy: s.Name,
+		Currency: s.Currency,
+		Up: s.Up,
+		Buy: !s.Up,
+		OrderID: order.ID,
+		ClientOrderID: clientOrderID,
+		Quantity: order.Quantity,
+		Reason: reason,
+	})
+	if order.Status != orderStatusFilled {
+		fmt.Fprintf(statusOutput, "%s: exit order %s status: %s\n", s.Name, order.ID, order.Status)
+		return nil
+	}
+	return s.recordExit(executor, p, order, reason)
+}
+
+// Exit orders may have been filled after a crash or after a market order was still being processed, partial fills of cancelled orders reduce the position so that the remainder is retried
+func (s *Strategy) syncExitOrder(executor Executor, p *position, order Order, reason string) (bool, error) {
+	if dryRun {
+		return false, nil
+	}
+	_, recorded := getState().clientOrders[order.ClientOrderID]
+	if !recorded {
+		appendEvent(event{
+			Type: eventOrder,
+			Strategy: s.Name,
+			Currency: s.Currency,
+			Up: s.Up,
+			Buy: !s.Up,
+			OrderID: order.ID,
+			ClientOrderID: order.ClientOrderID,
+			Quantity: order.Quantity,
+			Reason: reason,
+		})
+	}
+	quantity, quote := getState().getRecordedFills(order.ID)
+	delta := order.ExecutedQuantity - quantity
+	switch {
+	case order.Status == orderStatusFilled:
+		return true, s.recordExit(executor, p, order, reason)
+	case order.Status != orderStatusCancelled:
+		return false, nil
+	case delta <= 0:
+		s.recordExitCancellation(order.ClientOrderID)
+		return false, nil
+	}
+	appendEvent(event{
+		Type: eventFill,
+		Strategy: s.Name,
+		Currency: s.Currency,
+		Up: s.Up,
+		Buy: !s.Up,
+		OrderID: order.ID,
+		ClientOrderID: order.ClientOrderID,
+		Price: (order.AveragePrice * order.ExecutedQuantity - quote) / delta,
+		Quantity: delta,
+	})
+	appendEvent(event{
+		Type: eventPositionUpdated,
+		Strategy: s.Name,
+		Currency: s.Currency,
+		Up: s.Up,
+		Quantity: p.Quantity - delta,
+	})
+	return false, nil
+}
+
+func (s *Strategy) recordExit(executor Executor, p *position, order Order, reason string) error {
+	appendEvent(event{
+		Type: eventFill,
+		Strategy: s.Name,
+		Currency: s.Currency,
+		Up: s.Up,
+		Buy: !s.Up,
+		OrderID: order.ID,
+		ClientOrderID: order.ClientOrderID,
+		Price: order.AveragePrice,
+		Quantity: order.ExecutedQuantity,
+	})
+	appendEvent(event{
+		Type: eventPositionClosed,
+		Strategy: s.Name,
+		Currency: s.Currency,
+		Up: s.Up,
+		Price: order.AveragePrice,
+		Quantity: order.ExecutedQuantity,
+		Reason: reason,
+	})
+	returns := s.getReturns(p.EntryPrice, order.AveragePrice)
+	fmt.Fprintf(statusOutput, "%s: closed position at %.4f (%s), returns %+.2f%%\n", s.Name, order.AveragePrice, reason, returns * percent)
+	s.sendExitNotification(p, order.AveragePrice, reason)
+	if s.Order.isFutures() {
+		return s.verifyExit(executor, p)
+	}
+	return nil
+}
+
+func (s *Strategy) recordExitCancellation(clientOrderID string) {
+	if dryRun {
+		return
+	}
+	for orderID, order := range getState().orders {
+		if order.ClientOrderID != clientOrderID {
+			continue
+		}
+		appendEvent(event{
+			Type: eventOrderCancelled,
+			Strategy: s.Name,
+			Currency: s.Currency,
+			Up: s.Up,
+			OrderID: orderID,
+			ClientOrderID: clientOrderID,
+			Reason: order.Reason,
+		})
+	}
+}
\ No newline at end of file
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubmitExit(t *testing.T) {
+	entryTime := time.Date(2025, 3, 3, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		status string
+		executed float64
+		averagePrice float64
+		recorded bool
+		placed []float64
+		fills []float64
+		exitPrice float64
+	}{
+		{"no previous exit", "", 0, 0, false, []float64{1}, []float64{1}, 104},
+		{"previous exit open", orderStatusNew, 0, 0, true, nil, nil, 0},
+		{"previous exit filled", orderStatusFilled, 1, 105, true, nil, []float64{1}, 105},
+		{"previous exit filled but not recorded", orderStatusFilled, 1, 105, false, nil, []float64{1}, 105},
+		{"previous exit cancelled", orderStatusCancelled, 0, 0, true, []float64{1}, []float64{1}, 104},
+		{"previous exit partially filled", orderStatusCancelled, 0.4, 103, true, []float64{0.6}, []float64{0.4, 0.6}, 104},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func (t *testing.T) {
+			setupEventLog(t)
+			s := getBreakEvenStrategy()
+			appendEvent(event{
+				Time: entryTime,
+				Type: eventPositionOpened,
+				Strategy: s.Name,
+				Currency: s.Currency,
+				Up: s.Up,
+				Price: 100,
+				Quantity: 1,
+			})
+			first := getExitClientOrderID(s.Name, entryTime, 0)
+			executor := &fakeExecutor{
+				fillPrice: 104,
+			}
+			if test.status != "" {
+				executor.orders = map[string]Order{
+					"exit": {
+						ID: "exit",
+						ClientOrderID: first,
+						Symbol: s.Currency,
+						Status: test.status,
+						Buy: false,
+						Quantity: 1,
+						ExecutedQuantity: test.executed,
+						AveragePrice: test.averagePrice,
+					},
+				}
+			}
+			if test.recorded {
+				appendEvent(event{
+					Type: eventOrder,
+					Strategy: s.Name,
+					Currency: s.Currency,
+					Up: s.Up,
+					OrderID: "exit",
+					ClientOrderID: first,
+					Quantity: 1,
+					Reason: exitHold,
+				})
+			}
+			// Repeated runs must neither place further orders nor record the fills again
+			for range 2 {
+				p := s.getPosition()
+				if p == nil {
+					break
+				}
+				err := s.submitExit(executor, p, exitHold)
+				if err != nil {
+					t.Fatalf("failed to submit exit: %v", err)
+				}
+			}
+			if len(executor.placed) != len(test.placed) {
+				t.Fatalf("expected %d exit orders, got %d", len(test.placed), len(executor.placed))
+			}
+			for i, request := range executor.placed {
+				expected := getExitClientOrderID(s.Name, entryTime, 1)
+				if test.status == "" {
+					expected = first
+				}
+				if request.ClientOrderID != expected || !isClose(request.Quantity, test.placed[i]) {
+					t.Errorf("unexpected exit order: %+v", request)
+				}
+			}
+			state := getState()
+			fills := []float64{}
+			for _, fill := range state.fills {
+				fills = append(fills, fill.Quantity)
+			}
+			if len(fills) != len(test.fills) {
+				t.Fatalf("expected fills %v, got %v", test.fills, fills)
+			}
+			for i := range fills {
+				if !isClose(fills[i], test.fills[i]) {
+					t.Fatalf("expected fills %v, got %v", test.fills, fills)
+				}
+			}
+			if test.exitPrice == 0 {
+				if s.getPosition() == nil {
+					t.Fatalf("expected the position to remain open")
+				}
+				return
+			}
+			if s.getPosition() != nil || len(state.closed) != 1 {
+				t.Fatalf("expected the position to be closed")
+			}
+			if state.closed[0].ExitPrice != test.exitPrice {
+				t.Errorf("expected an exit price of %.2f, got %.2f", test.exitPrice, state.closed[0].ExitPrice)
+			}
+			if len(state.orders) != 0 {
+				t.Errorf("expected no pending orders, got %v", state.orders)
+			}
+		})
+	}
+}
+
+// Orders that are only known to the event log have been purged by the exchange and are retried as well
+func TestSubmitExitRecordedOnly(t *testing.T) {
+	setupEventLog(t)
+	entryTime := time.Date(2025, 3, 3, 14, 0, 0, 0, time.UTC)
+	s := getBreakEvenStrategy()
+	appendEvent(event{
+		Time: entryTime,
+		Type: eventPositionOpened,
+		Strategy: s.Name,
+		Currency: s.Currency,
+		Up: s.Up,
+		Price: 100,
+		Quantity: 1,
+	})
+	for attempt := range 2 {
+		appendEvent(event{
+			Type: eventOrder,
+			Strategy: s.Name,
+			Currency: s.Currency,
+			Up: s.Up,
+			OrderID: "exit" + formatDecimal(float64(attempt)),
+			ClientOrderID: getExitClientOrderID(s.Name, entryTime, attempt),
+			Quantity: 1,
+			Reason: exitHold,
+		})
+	}
+	executor := &fakeExecutor{}
+	err := s.submitExit(executor, s.getPosition(), exitHold)
+	if err != nil {
+		t.Fatalf("failed to submit exit: %v", err)
+	}
+	expected := getExitClientOrderID(s.Name, entryTime, 2)
+	if len(executor.placed) != 1 || executor.placed[0].ClientOrderID != expected {
+		t.Errorf("expected exit order %s, got %+v", expected, executor.placed)
+	}
+	pending := getState().orders
+	if len(pending) != 1 || pending["1"].ClientOrderID != expected {
+		t.Errorf("expected only the new exit order to be pending, got %v", pending)
+	}
+}
+
+func TestGetSpotExitQuantity(t *testing.T) {
+	filters := symbolFilters{
+		stepSize: "0.0001",
+	}
+	tests := []struct {
+		quantity float64
+		balance float64
+		expected float64
+	}{
+		{0.5, 0.6, 0.5},
+		{0.5, 0.5, 0.5},
+		{0.5, 0.4995, 0.4995},
+		{0.5, 0.49995, 0.4999},
+		{0.5, 0.00004, 0},
+	}
+	for _, test := range tests {
+		quantity := getSpotExitQuantity(test.quantity, test.balance, filters)
+		if !isClose(quantity, test.expected) {
+			t.Errorf("expected an exit quantity of %s for %s with a balance of %s, got %s", formatDecimal(test.expected), formatDecimal(test.quantity), formatDecimal(test.balance), formatDecimal(quantity))
+		}
+	}
+}
\ No newline at end of file
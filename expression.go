@@ -0,0 +1,171 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/encratite/commons"
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+)
+
+type expressionVisitor struct {
+	lookback time.Duration
+}
+
+// Functions that access candle data from before the entry and the lookback required for their argument
+var expressionFunctions = map[string]func (int) time.Duration{
+	"roc": func (hours int) time.Duration {
+		return time.Duration(hours) * time.Hour
+	},
+	"rsi": func (period int) time.Duration {
+		return time.Duration(period + 1) * time.Hour
+	},
+	"sma": func (period int) time.Duration {
+		return time.Duration(period + 1) * candleInterval
+	},
+	"ema": func (period int) time.Duration {
+		return time.Duration(2 * period + 1) * candleInterval
+	},
+	"atr": func (hours int) time.Duration {
+		return time.Duration(hours + 1) * time.Hour
+	},
+	"high": func (hours int) time.Duration {
+		return time.Duration(hours + 1) * time.Hour
+	},
+	"low": func (hours int) time.Duration {
+		return time.Duration(hours + 1) * time.Hour
+	},
+}
+
+func (s *Strategy) compileExpression() {
+	if s.Expression == "" {
+		return
+	}
+	program, err := expr.Compile(s.Expression, expr.Env(getExpressionEnvironment(nil, 0, time.Time{})), expr.AsBool())
+	if err != nil {
+		commons.Fatalf("Invalid expression for strategy %s: %v", s.Name, err)
+	}
+	node := program.Node()
+	visitor := &expressionVisitor{}
+	ast.Walk(&node, visitor)
+	s.expression = program
+	s.expressionLookback = visitor.lookback
+}
+
+// The lookback of each call is derived from its argument, which therefore has to be a constant
+func (v *expressionVisitor) Visit(node *ast.Node) {
+	call, ok := (*node).(*ast.CallNode)
+	if !ok {
+		return
+	}
+	identifier, ok := call.Callee.(*ast.IdentifierNode)
+	if !ok {
+		return
+	}
+	getLookback, exists := expressionFunctions[identifier.Value]
+	if !exists || len(call.Arguments) != 1 {
+		return
+	}
+	argument, ok := call.Arguments[0].(*ast.IntegerNode)
+	if !ok {
+		commons.Fatalf("The argument of %s in expressions must be an integer constant", identifier.Value)
+	}
+	v.lookback = max(v.lookback, getLookback(argument.Value))
+}
+
+// Functions return NaN when there aren't enough candles, which fails any comparison
+func getExpressionEnvironment(records []ohlcRecord, latestIndex int, entryTime time.Time) map[string]any {
+	window := func (lookback time.Duration) []ohlcRecord {
+		return getIndicatorWindow(records, latestIndex, lookback)
+	}
+	closes := func (period int) []float64 {
+		values := []float64{}
+		for _, record := range window(time.Duration(period + 1) * candleInterval) {
+			values = append(values, record.close)
+		}
+		return values
+	}
+	extreme := func (hours int, high bool) float64 {
+		candles := window(time.Duration(hours + 1) * time.Hour)
+		if len(candles) == 0 {
+			return math.NaN()
+		}
+		start := candles[len(candles) - 1].timestamp.Add(-time.Duration(hours) * time.Hour)
+		if candles[0].timestamp.After(start) {
+			return math.NaN()
+		}
+		output := candles[len(candles) - 1].close
+		for _, record := range candles[findRecord(candles, start):] {
+			if high {
+				output = math.Max(output, record.high)
+			} else {
+				output = math.Min(output, record.low)
+			}
+		}
+		return output
+	}
+	environment := map[string]any{
+		"price": 0.0,
+		"hour": entryTime.Hour(),
+		"weekday": entryTime.Weekday().String(),
+		"day": entryTime.Day(),
+		"month": int(entryTime.Month()),
+		"roc": func (hours int) float64 {
+			momentum, ok := getAnchoredMomentum(records, latestIndex, entryTime, time.Duration(hours) * time.Hour)
+			if !ok {
+				return math.NaN()
+			}
+			return momentum
+		},
+		"rsi": func (period int) float64 {
+			rsi, ok := getRSI(getHourlyBars(window(time.Duration(period + 1) * time.Hour)), period)
+			if !ok {
+				return math.NaN()
+			}
+			return rsi
+		},
+		"sma": func (period int) float64 {
+			values := closes(period)
+			if len(values) < period {
+				return math.NaN()
+			}
+			return getSMA(values, period)
+		},
+		"ema": func (period int) float64 {
+			values := closes(2 * period)
+			if len(values) < 2 * period {
+				return math.NaN()
+			}
+			return getEMA(values, period)
+		},
+		"atr": func (hours int) float64 {
+			atr, err := getATR(window(time.Duration(hours + 1) * time.Hour), hours)
+			if err != nil {
+				return math.NaN()
+			}
+			return atr / records[latestIndex].close * percent
+		},
+		"high": func (hours int) float64 {
+			return extreme(hours, true)
+		},
+		"low": func (hours int) float64 {
+			return extreme(hours, false)
+		},
+	}
+	if records != nil {
+		environment["price"] = records[latestIndex].close
+	}
+	return environment
+}
+
+func (s *Strategy) getExpressionMatch(records []ohlcRecord, latestIndex int, entryTime time.Time) (bool, error) {
+	if s.expression == nil {
+		return true, nil
+	}
+	output, err := expr.Run(s.expression, getExpressionEnvironment(records, latestIndex, entryTime.In(s.getLocation())))
+	if err != nil {
+		return false, err
+	}
+	return output.(bool), nil
+}
\ No newline at end of file
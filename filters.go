@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type binanceExchangeInfo struct {
+	Symbols []binanceSymbol `json:"symbols"`
+}
+
+type binanceSymbol struct {
+	Symbol string `json:"symbol"`
+	BaseAsset string `json:"baseAsset"`
+	QuoteAsset string `json:"quoteAsset"`
+	Filters []binanceFilter `json:"filters"`
+}
+
+type binanceFilter struct {
+	FilterType string `json:"filterType"`
+	MinQuantity string `json:"minQty"`
+	MaxQuantity string `json:"maxQty"`
+	StepSize string `json:"stepSize"`
+	TickSize string `json:"tickSize"`
+	MinNotional string `json:"minNotional"`
+	Notional string `json:"notional"`
+}
+
+type symbolFilters struct {
+	baseAsset string
+	quoteAsset string
+	stepSize string
+	tickSize string
+	minQuantity float64
+	maxQuantity float64
+	minNotional float64
+}
+
+var symbolFilterCache sync.Map
+
+// Exchange information rarely changes so it is only requested once per symbol and market
+func (c *binanceClient) getSymbolFilters(symbol string) (symbolFilters, error) {
+	key := c.baseURL + "/" + symbol
+	cached, exists := symbolFilterCache.Load(key)
+	if exists {
+		return cached.(symbolFilters), nil
+	}
+	var info binanceExchangeInfo
+	var err error
+	if c.futures {
+		err = c.publicRequest("/fapi/v1/exchangeInfo", url.Values{}, &info)
+	} else {
+		parameters := url.Values{}
+		parameters.Set("symbol", symbol)
+		err = c.publicRequest("/api/v3/exchangeInfo", parameters, &info)
+	}
+	if err != nil {
+		return symbolFilters{}, fmt.Errorf("failed to retrieve exchange information: %v", err)
+	}
+	for _, s := range info.Symbols {
+		if s.Symbol != symbol {
+			continue
+		}
+		filters := symbolFilters{
+			baseAsset: s.BaseAsset,
+			quoteAsset: s.QuoteAsset,
+		}
+		for _, filter := range s.Filters {
+			switch filter.FilterType {
+			case "LOT_SIZE":
+				filters.stepSize = filter.StepSize
+				filters.minQuantity, _ = strconv.ParseFloat(filter.MinQuantity, 64)
+				filters.maxQuantity, _ = strconv.ParseFloat(filter.MaxQuantity, 64)
+			case "PRICE_FILTER":
+				filters.tickSize = filter.TickSize
+			case "NOTIONAL":
+				filters.minNotional, _ = strconv.ParseFloat(filter.MinNotional, 64)
+			case "MIN_NOTIONAL":
+				notional := filter.MinNotional
+				if notional == "" {
+					notional = filter.Notional
+				}
+				filters.minNotional, _ = strconv.ParseFloat(notional, 64)
+			}
+		}
+		symbolFilterCache.Store(key, filters)
+		return filters, nil
+	}
+	return symbolFilters{}, fmt.Errorf("unknown symbol: %s", symbol)
+}
+
+func getDecimals(increment string) int {
+	increment = strings.TrimRight(increment, "0")
+	index := strings.Index(increment, ".")
+	if index < 0 {
+		return 0
+	}
+	return len(increment) - index - 1
+}
+
+func roundToIncrement(value float64, increment string, round func (float64) float64) string {
+	step, _ := strconv.ParseFloat(increment, 64)
+	if step <= 0 {
+		return formatDecimal(value)
+	}
+	// The epsilon prevents values that are already multiples of the step from being rounded down due to floating point errors
+	rounded := round(value / step + 1e-9) * step
+	return strconv.FormatFloat(rounded, 'f', getDecimals(increment), 64)
+}
+
+// Quantities are always rounded down so that orders never exceed the available balance
+func (f *symbolFilters) formatQuantity(quantity float64) string {
+	return roundToIncrement(quantity, f.stepSize, math.Floor)
+}
+
+func (f *symbolFilters) formatPrice(price float64) string {
+	return roundToIncrement(price, f.tickSize, math.Round)
+}
+
+func (f *symbolFilters) check(quantityString string, price float64) error {
+	quantity, _ := strconv.ParseFloat(quantityString, 64)
+	if quantity <= 0 || quantity < f.minQuantity {
+		return fmt.Errorf("order quantity %s is below the minimum of %s", quantityString, formatDecimal(f.minQuantity))
+	}
+	if f.maxQuantity > 0 && quantity > f.maxQuantity {
+		return fmt.Errorf("order quantity %s exceeds the maximum of %s", quantityString, formatDecimal(f.maxQuantity))
+	}
+	return f.checkNotional(quantity * price)
+}
+
+func (f *symbolFilters) checkNotional(notional float64) error {
+	if notional < f.minNotional {
+		return fmt.Errorf("order value %.2f is below the minimum notional of %s", notional, formatDecimal(f.minNotional))
+	}
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestFormatQuantity(t *testing.T) {
+	tests := []struct {
+		quantity float64
+		stepSize string
+		expected string
+	}{
+		{1.23456, "0.001", "1.234"},
+		{1.23456, "0.00100000", "1.234"},
+		{0.0999, "0.01", "0.09"},
+		{0.3, "0.1", "0.3"},
+		{2.9999999, "0.1", "2.9"},
+		{12.7, "1", "12"},
+		{12.7, "1.00000000", "12"},
+		{17, "5", "15"},
+		{0.00049, "0.001", "0.000"},
+		{1.23456, "", "1.23456"},
+	}
+	for _, test := range tests {
+		filters := symbolFilters{
+			stepSize: test.stepSize,
+		}
+		quantity := filters.formatQuantity(test.quantity)
+		if quantity != test.expected {
+			t.Errorf("expected %v with step size %q to be rounded down to %s, got %s", test.quantity, test.stepSize, test.expected, quantity)
+		}
+	}
+}
+
+func TestFormatPrice(t *testing.T) {
+	tests := []struct {
+		price float64
+		tickSize string
+		expected string
+	}{
+		{101.234, "0.01", "101.23"},
+		{101.235001, "0.01", "101.24"},
+		{0.123456, "0.0001", "0.1235"},
+		{27345.67, "0.10000000", "27345.7"},
+		{27345.67, "1", "27346"},
+	}
+	for _, test := range tests {
+		filters := symbolFilters{
+			tickSize: test.tickSize,
+		}
+		price := filters.formatPrice(test.price)
+		if price != test.expected {
+			t.Errorf("expected %v with tick size %q to be rounded to %s, got %s", test.price, test.tickSize, test.expected, price)
+		}
+	}
+}
+
+func TestCheckFilters(t *testing.T) {
+	filters := symbolFilters{
+		stepSize: "0.001",
+		minQuantity: 0.001,
+		maxQuantity: 100,
+		minNotional: 10,
+	}
+	tests := []struct {
+		name string
+		quantity string
+		price float64
+		valid bool
+	}{
+		{"valid", "0.010", 2000, true},
+		{"exactly the minimum notional", "0.005", 2000, true},
+		{"below the minimum notional", "0.004", 2000, false},
+		{"zero quantity", "0.000", 2000, false},
+		{"below the minimum quantity", "0.0005", 100000, false},
+		{"above the maximum quantity", "100.001", 2000, false},
+	}
+	for _, test := range tests {
+		err := filters.check(test.quantity, test.price)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid to be %t, got %v", test.name, test.valid, err)
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+type FundingConfiguration struct {
+	GreaterThan *float64 `yaml:"greaterThan"`
+	LessThan *float64 `yaml:"lessThan"`
+}
+
+type binancePremiumIndex struct {
+	Symbol string `json:"symbol"`
+	LastFundingRate string `json:"lastFundingRate"`
+}
+
+func (s *Strategy) requireFutures(condition string) {
+	if s.Order == nil || !s.Order.isFutures() {
+		commons.Fatalf("%s requires the futures market for strategy %s", condition, s.Name)
+	}
+}
+
+func (s *Strategy) validateFunding() {
+	c := s.Funding
+	s.requireFutures("Funding rate condition")
+	if c.GreaterThan == nil && c.LessThan == nil {
+		commons.Fatalf("Missing funding rate constraint for strategy %s", s.Name)
+	}
+}
+
+// The last funding rate of the premium index is the predicted rate for the upcoming funding
+func getFundingRate(symbol string) (float64, error) {
+	url := fmt.Sprintf("%s/fapi/v1/premiumIndex", binanceFuturesURL)
+	parameters := map[string]string{
+		"symbol": symbol,
+	}
+	start := time.Now()
+	index, err := commons.DownloadJSON[binancePremiumIndex](url, parameters)
+	recordFetch(providerBinance, time.Since(start), err)
+	if err != nil {
+		return 0, fmt.Errorf("failed to download premium index from Binance: %v", err)
+	}
+	rate, err := strconv.ParseFloat(index.LastFundingRate, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid funding rate: %s", index.LastFundingRate)
+	}
+	return rate * percent, nil
+}
+
+// Like the liquidity floor, the funding rate is only available live and is checked once all other conditions match
+func (s *Strategy) checkFunding(result *EvaluationResult) error {
+	result.FundingMatch = true
+	if s.Funding == nil {
+		return nil
+	}
+	if isHistoricalEvaluation() {
+		result.addMessage("The funding rate is only available live and was not checked")
+		return nil
+	}
+	rate, err := getFundingRate(s.Currency)
+	if err != nil {
+		return err
+	}
+	result.FundingRate = &rate
+	if s.Funding.GreaterThan != nil && rate <= *s.Funding.GreaterThan {
+		result.FundingMatch = false
+	}
+	if s.Funding.LessThan != nil && rate >= *s.Funding.LessThan {
+		result.FundingMatch = false
+	}
+	return nil
+}
\ No newline at end of file
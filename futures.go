@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	marketSpot = "spot"
+	marketFutures = "futures"
+	marginIsolated = "isolated"
+	marginCross = "cross"
+	maxLeverage = 125
+	binanceNoMarginChange = -4046
+)
+
+func (o *OrderConfiguration) isFutures() bool {
+	return o.Market == marketFutures
+}
+
+func (o *OrderConfiguration) validateFutures(strategy string) {
+	if o.Market != "" && o.Market != marketSpot && o.Market != marketFutures {
+		commons.Fatalf("Invalid market for strategy %s: %s", strategy, o.Market)
+	}
+	if !o.isFutures() && (o.Leverage != 0 || o.MarginType != "") {
+		commons.Fatalf("Leverage and margin type require the futures market for strategy %s", strategy)
+	}
+	if o.Leverage < 0 || o.Leverage > maxLeverage {
+		commons.Fatalf("Invalid leverage for strategy %s: %d", strategy, o.Leverage)
+	}
+	if o.MarginType != "" && o.MarginType != marginIsolated && o.MarginType != marginCross {
+		commons.Fatalf("Invalid margin type for strategy %s: %s", strategy, o.MarginType)
+	}
+}
+
+type binancePositionRisk struct {
+	Symbol string `json:"symbol"`
+	PositionAmount string `json:"positionAmt"`
+	EntryPrice string `json:"entryPrice"`
+}
+
+// Leverage and margin mode are account settings per symbol, leaving them unset keeps whatever is currently configured
+func (c *binanceClient) configureFutures(symbol string, order *OrderConfiguration) error {
+	if order.MarginType != "" {
+		parameters := url.Values{}
+		parameters.Set("symbol", symbol)
+		marginType := "ISOLATED"
+		if order.MarginType == marginCross {
+			marginType = "CROSSED"
+		}
+		parameters.Set("marginType", marginType)
+		err := c.signedRequest(http.MethodPost, "/fapi/v1/marginType", parameters, nil)
+		var apiError *binanceError
+		if err != nil && !(errors.As(err, &apiError) && apiError.Code == binanceNoMarginChange) {
+			return fmt.Errorf("failed to set margin type: %v", err)
+		}
+	}
+	if order.Leverage > 0 {
+		parameters := url.Values{}
+		parameters.Set("symbol", symbol)
+		parameters.Set("leverage", strconv.Itoa(order.Leverage))
+		err := c.signedRequest(http.MethodPost, "/fapi/v1/leverage", parameters, nil)
+		if err != nil {
+			return fmt.Errorf("failed to set leverage: %v", err)
+		}
+	}
+	return nil
+}
+
+func (c *binanceClient) getPosition(symbol string) (ExchangePosition, error) {
+	var positions []binancePositionRisk
+	parameters := url.Values{}
+	parameters.Set("symbol", symbol)
+	err := c.signedRequest(http.MethodGet, "/fapi/v2/positionRisk", parameters, &positions)
+	if err != nil {
+		return ExchangePosition{}, fmt.Errorf("failed to retrieve futures position: %v", err)
+	}
+	output := ExchangePosition{}
+	for _, position := range positions {
+		if position.Symbol != symbol {
+			continue
+		}
+		amount, err := strconv.ParseFloat(position.PositionAmount, 64)
+		if err != nil {
+			return ExchangePosition{}, fmt.Errorf("invalid position amount for %s: %s", symbol, position.PositionAmount)
+		}
+		if amount != 0 {
+			output.EntryPrice, _ = strconv.ParseFloat(position.EntryPrice, 64)
+		}
+		output.Amount += amount
+	}
+	return output, nil
+}
+
+func getPositionSide(amount float64) string {
+	if amount > 0 {
+		return "long"
+	} else if amount < 0 {
+		return "short"
+	} else {
+		return "flat"
+	}
+}
+
+// Verifies that an exit left the futures position either closed or reduced but never on the opposite side
+func (s *Strategy) verifyExit(executor Executor, p *position) error {
+	exchangePosition, err := executor.GetPosition(s.Currency)
+	if err != nil {
+		return err
+	}
+	amount := exchangePosition.Amount
+	if amount == 0 {
+		return nil
+	}
+	if (amount > 0) != p.Up {
+		return fmt.Errorf("exit flipped the position of %s to %s %s", s.Currency, getPositionSide(amount), formatDecimal(math.Abs(amount)))
+	}
+	fmt.Fprintf(statusOutput, "%s: %s %s remains on the exchange after the exit\n", s.Name, getPositionSide(amount), formatDecimal(math.Abs(amount)))
+	return nil
+}
+
+func (s *Strategy) getMarketDescription() string {
+	if !s.Order.isFutures() {
+		return marketSpot
+	}
+	description := marketFutures
+	if s.Order.Leverage > 0 {
+		description = fmt.Sprintf("%s %dx", description, s.Order.Leverage)
+	}
+	if s.Order.MarginType != "" {
+		description = fmt.Sprintf("%s %s", description, s.Order.MarginType)
+	}
+	return description
+}
+
+func getSignedQuantity(p *position) float64 {
+	if p.Up {
+		return p.Quantity
+	} else {
+		return -p.Quantity
+	}
+}
\ No newline at end of file
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+type candleGap struct {
+	from time.Time
+	to time.Time
+}
+
+// Detects missing candles caused by exchange downtime or delistings, from is the first missing candle and to the first one after the gap
+func findGaps(records []ohlcRecord) []candleGap {
+	gaps := []candleGap{}
+	for i := 1; i < len(records); i++ {
+		expected := records[i - 1].timestamp.Add(candleInterval)
+		if records[i].timestamp.After(expected) {
+			gap := candleGap{
+				from: expected,
+				to: records[i].timestamp,
+			}
+			gaps = append(gaps, gap)
+		}
+	}
+	return gaps
+}
+
+func (g *candleGap) getMissing() int {
+	return int(g.to.Sub(g.from) / candleInterval)
+}
+
+// Returns the gaps between the momentum anchor and the last candle before the entry
+func (s *Strategy) getMomentumGaps(entryTime time.Time) []candleGap {
+	from := s.getAnchorTime(entryTime)
+	to := entryTime.Add(-candleInterval)
+	output := []candleGap{}
+	for _, gap := range s.gaps {
+		if gap.to.After(from) && !gap.from.After(to) {
+			output = append(output, gap)
+		}
+	}
+	return output
+}
+
+func formatGaps(gaps []candleGap) []string {
+	descriptions := []string{}
+	for _, gap := range gaps {
+		description := fmt.Sprintf("%s UTC (%d missing)", commons.GetTimeString(gap.from), gap.getMissing())
+		descriptions = append(descriptions, description)
+	}
+	return descriptions
+}
\ No newline at end of file
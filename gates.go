@@ -0,0 +1,40 @@
+package main
+
+import (
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+type GateConfiguration struct {
+	Symbol string `yaml:"symbol"`
+	MomentumConfiguration `yaml:",inline"`
+}
+
+type gateResult struct {
+	Symbol string `json:"symbol"`
+	Offset string `json:"offset"`
+	Momentum *float64 `json:"momentum,omitempty"`
+	Match bool `json:"match"`
+}
+
+func (c *GateConfiguration) validate(strategy string) {
+	if c.Symbol == "" {
+		commons.Fatalf("Missing gate symbol for strategy %s", strategy)
+	}
+	c.MomentumConfiguration.validate(strategy)
+}
+
+// Gates require the momentum of another symbol over the same entry window to match, missing candles close the gate
+func (c *GateConfiguration) getGate(records []ohlcRecord, latestIndex int, entryTime time.Time) gateResult {
+	result := gateResult{
+		Symbol: c.Symbol,
+		Offset: c.Offset.String(),
+	}
+	momentum, ok := getReferenceMomentum(c.Symbol, records[latestIndex].timestamp, entryTime, c.Offset.Duration)
+	if ok {
+		result.Momentum = &momentum
+		result.Match = c.matches(momentum)
+	}
+	return result
+}
\ No newline at end of file
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	holidaysUS = "us"
+	holidaysEU = "eu"
+)
+
+var builtInHolidays = map[string]func (int) []time.Time{
+	holidaysUS: getUSHolidays,
+	holidaysEU: getEUHolidays,
+}
+
+// Dates of user-defined calendars are added to the built-in calendar with the same name
+func (s *Strategy) normalizeHolidays(calendars map[string][]string) {
+	s.holidays = map[string]bool{}
+	for _, name := range s.ExcludeHolidays {
+		_, builtIn := builtInHolidays[name]
+		dates, exists := calendars[name]
+		if !builtIn && !exists {
+			commons.Fatalf("Unknown holiday calendar in strategy %s: %s", s.Name, name)
+		}
+		for _, date := range dates {
+			day, err := time.Parse(time.DateOnly, date)
+			if err != nil {
+				commons.Fatalf("Invalid date in holiday calendar %s: %s", name, date)
+			}
+			s.holidays[day.Format(time.DateOnly)] = true
+		}
+	}
+}
+
+func (s *Strategy) isHoliday(day time.Time) bool {
+	if s.holidays[day.Format(time.DateOnly)] {
+		return true
+	}
+	for _, name := range s.ExcludeHolidays {
+		getHolidays, builtIn := builtInHolidays[name]
+		if !builtIn {
+			continue
+		}
+		for _, holiday := range getHolidays(day.Year()) {
+			if holiday.Year() == day.Year() && holiday.YearDay() == day.YearDay() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Full-day closures of the NYSE, fixed holidays on a weekend are observed on the adjacent weekday except for New Year's Day on a Saturday
+func getUSHolidays(year int) []time.Time {
+	date := func (month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	observed := func (day time.Time) time.Time {
+		switch day.Weekday() {
+		case time.Saturday:
+			return day.AddDate(0, 0, -1)
+		case time.Sunday:
+			return day.AddDate(0, 0, 1)
+		}
+		return day
+	}
+	holidays := []time.Time{
+		getNthWeekday(year, time.January, time.Monday, 3),
+		getNthWeekday(year, time.February, time.Monday, 3),
+		getEaster(year).AddDate(0, 0, -2),
+		getLastWeekday(year, time.May, time.Monday),
+		observed(date(time.July, 4)),
+		getNthWeekday(year, time.September, time.Monday, 1),
+		getNthWeekday(year, time.November, time.Thursday, 4),
+		observed(date(time.December, 25)),
+	}
+	newYear := date(time.January, 1)
+	if newYear.Weekday() != time.Saturday {
+		holidays = append(holidays, observed(newYear))
+	}
+	if year >= 2022 {
+		holidays = append(holidays, observed(date(time.June, 19)))
+	}
+	return holidays
+}
+
+// TARGET2 closing days, which are shared by most European exchanges
+func getEUHolidays(year int) []time.Time {
+	easter := getEaster(year)
+	return []time.Time{
+		time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC),
+		easter.AddDate(0, 0, -2),
+		easter.AddDate(0, 0, 1),
+		time.Date(year, time.May, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(year, time.December, 25, 0, 0, 0, 0, time.UTC),
+		time.Date(year, time.December, 26, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func getNthWeekday(year int, month time.Month, weekday time.Weekday, n int) time.Time {
+	day := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	for day.Weekday() != weekday {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day.AddDate(0, 0, 7 * (n - 1))
+}
+
+func getLastWeekday(year int, month time.Month, weekday time.Weekday) time.Time {
+	day := time.Date(year, month + 1, 0, 0, 0, 0, 0, time.UTC)
+	for day.Weekday() != weekday {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day
+}
+
+// Anonymous Gregorian algorithm for the date of Easter Sunday
+func getEaster(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19 * a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2 * e + 2 * i - h - k) % 7
+	m := (a + 11 * h + 22 * l) / 451
+	month := (h + l - 7 * m + 114) / 31
+	day := (h + l - 7 * m + 114) % 31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
\ No newline at end of file
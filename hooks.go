@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	hookBeforeEvaluation = "beforeEvaluation"
+	hookSignal = "signal"
+	hookSuppressed = "suppressed"
+	hookError = "error"
+	hookTimeout = 30 * time.Second
+)
+
+type HookConfiguration struct {
+	Event string `yaml:"event"`
+	Command string `yaml:"command"`
+	URL string `yaml:"url"`
+}
+
+type hookPayload struct {
+	Event string `json:"event"`
+	Result EvaluationResult `json:"result"`
+}
+
+func (h *HookConfiguration) validate() {
+	switch h.Event {
+	case hookBeforeEvaluation, hookSignal, hookSuppressed, hookError:
+	default:
+		commons.Fatalf("Invalid hook event: %s", h.Event)
+	}
+	if (h.Command == "") == (h.URL == "") {
+		commons.Fatalf("Hook for event %s must specify either a command or a URL", h.Event)
+	}
+}
+
+// Hook failures are reported but never interrupt the evaluation of strategies
+func runHooks(hookEvent string, result EvaluationResult) {
+	for _, hook := range configuration.Hooks {
+		if hook.Event != hookEvent {
+			continue
+		}
+		payload := hookPayload{
+			Event: hookEvent,
+			Result: result,
+		}
+		data, err := json.Marshal(payload)
+		if err != nil {
+			commons.Fatalf("Failed to serialize hook payload: %v", err)
+		}
+		if hook.Command != "" {
+			err = runHookCommand(hook.Command, hookEvent, result.Strategy, data)
+		} else {
+			err = postHook(hook.URL, data)
+		}
+		if err != nil {
+			fmt.Fprintf(statusOutput, "%s: %s hook failed: %v\n", result.Strategy, hookEvent, err)
+		}
+	}
+}
+
+// The payload is passed to commands on standard input
+func runHookCommand(command string, hookEvent string, strategy string, data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = statusOutput
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(
+		os.Environ(),
+		"COINAGE_HOOK=" + hookEvent,
+		"COINAGE_STRATEGY=" + strategy,
+	)
+	return cmd.Run()
+}
+
+func postHook(url string, data []byte) error {
+	client := http.Client{
+		Timeout: hookTimeout,
+	}
+	response, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("status %d", response.StatusCode)
+	}
+	return nil
+}
\ No newline at end of file
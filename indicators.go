@@ -0,0 +1,284 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+func getBars(records []ohlcRecord, interval time.Duration) []ohlcRecord {
+	bars := []ohlcRecord{}
+	for _, record := range records {
+		start := record.timestamp.Truncate(interval)
+		if len(bars) == 0 || !bars[len(bars) - 1].timestamp.Equal(start) {
+			bar := record
+			bar.timestamp = start
+			bars = append(bars, bar)
+			continue
+		}
+		bar := &bars[len(bars) - 1]
+		bar.high = math.Max(bar.high, record.high)
+		bar.low = math.Min(bar.low, record.low)
+		bar.close = record.close
+		bar.volume += record.volume
+	}
+	return bars
+}
+
+func getIndicatorInterval(minutes int) time.Duration {
+	if minutes == 0 {
+		return candleInterval
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// Bars must consist of whole candles and line up with the start of each day
+func validateIndicatorInterval(minutes int, indicator string, strategy string) {
+	interval := getIndicatorInterval(minutes)
+	if interval < candleInterval || interval % candleInterval != 0 || (24 * time.Hour) % interval != 0 {
+		commons.Fatalf("Invalid %s interval for strategy %s", indicator, strategy)
+	}
+}
+
+func getHourlyBars(records []ohlcRecord) []ohlcRecord {
+	return getBars(records, time.Hour)
+}
+
+func (s *Strategy) getIndicatorLookback() time.Duration {
+	lookback := time.Duration(0)
+	if s.RSI != nil {
+		lookback = max(lookback, time.Duration(s.RSI.Period + 1) * time.Hour)
+	}
+	for _, movingAverage := range s.MovingAverages {
+		lookback = max(lookback, movingAverage.getLookback())
+	}
+	if s.MACD != nil {
+		lookback = max(lookback, s.MACD.getLookback())
+	}
+	if s.Bollinger != nil {
+		lookback = max(lookback, s.Bollinger.getLookback())
+	}
+	if s.Volatility != nil {
+		lookback = max(lookback, s.Volatility.getLookback())
+	}
+	if s.Volume != nil {
+		lookback = max(lookback, s.Volume.getLookback(s))
+	}
+	if s.Breakout != nil {
+		lookback = max(lookback, s.Breakout.getLookback())
+	}
+	if s.ZScore != nil {
+		lookback = max(lookback, s.ZScore.getLookback())
+	}
+	if s.RelativeStrength != nil {
+		lookback = max(lookback, s.RelativeStrength.getOffset(s))
+	}
+	if s.WeekendGap != nil {
+		lookback = max(lookback, s.WeekendGap.getLookback())
+	}
+	if s.Donchian != nil {
+		lookback = max(lookback, s.Donchian.getLookback())
+	}
+	if s.EMASlope != nil {
+		lookback = max(lookback, s.EMASlope.getLookback())
+	}
+	for _, gate := range s.Gates {
+		lookback = max(lookback, gate.Offset.Duration)
+	}
+	if s.VWAP != nil {
+		lookback = max(lookback, s.VWAP.getLookback())
+	}
+	if s.Pattern != nil {
+		lookback = max(lookback, s.Pattern.getLookback())
+	}
+	if s.Score != nil {
+		lookback = max(lookback, s.Score.getLookback())
+	}
+	if s.Percentile != nil {
+		lookback = max(lookback, percentileWindow)
+	}
+	if s.RealizedVolatility != nil {
+		lookback = max(lookback, s.RealizedVolatility.getLookback())
+	}
+	if s.Correlation != nil {
+		lookback = max(lookback, s.Correlation.getLookback())
+	}
+	lookback = max(lookback, s.expressionLookback)
+	return lookback
+}
+
+func (s *Strategy) getIndicatorLookbackHours() int {
+	return int(math.Ceil(s.getIndicatorLookback().Hours()))
+}
+
+func getIndicatorWindow(records []ohlcRecord, latestIndex int, lookback time.Duration) []ohlcRecord {
+	start := records[latestIndex].timestamp.Truncate(time.Hour).Add(-lookback)
+	return records[findRecord(records, start):latestIndex + 1]
+}
+
+// Indicator conditions are evaluated on bars up to and including the latest candle before the entry
+func (s *Strategy) getIndicatorMatch(records []ohlcRecord, latestIndex int, entryTime time.Time, result *EvaluationResult) bool {
+	lookback := s.getIndicatorLookback()
+	if lookback == 0 {
+		return true
+	}
+	window := getIndicatorWindow(records, latestIndex, lookback)
+	match := true
+	if s.RSI != nil {
+		rsi, ok := getRSI(getHourlyBars(window), s.RSI.Period)
+		rsiMatch := ok && s.RSI.matches(rsi)
+		match = match && rsiMatch
+		if ok && result != nil {
+			result.RSI = &rsi
+			result.RSIMatch = rsiMatch
+		}
+	}
+	for i := range s.MovingAverages {
+		movingAverage := &s.MovingAverages[i]
+		fast, slow, ok := movingAverage.getAverages(window)
+		movingAverageMatch := ok && s.getMovingAverageMatch(movingAverage, fast, slow)
+		match = match && movingAverageMatch
+		if ok && result != nil {
+			result.MovingAverages = append(result.MovingAverages, movingAverageResult{
+				Name: movingAverage.getName(),
+				Fast: fast,
+				Slow: slow,
+				Match: movingAverageMatch,
+			})
+		}
+	}
+	if s.MACD != nil {
+		macd, ok := s.MACD.getMACD(window, s.Up)
+		match = match && ok && macd.Match
+		if ok && result != nil {
+			result.MACD = &macd
+		}
+	}
+	if s.Bollinger != nil {
+		bands, ok := s.Bollinger.getBands(window)
+		match = match && ok && bands.Match
+		if ok && result != nil {
+			result.Bollinger = &bands
+		}
+	}
+	if s.Volatility != nil {
+		atr, atrMatch, ok := s.Volatility.getATR(window)
+		match = match && ok && atrMatch
+		if ok && result != nil {
+			result.ATR = &atr
+			result.ATRMatch = atrMatch
+		}
+	}
+	if s.Volume != nil {
+		ratio, ok := s.Volume.getRatio(s, window)
+		volumeMatch := ok && ratio > s.Volume.Multiple
+		match = match && volumeMatch
+		if ok && result != nil {
+			result.VolumeRatio = &ratio
+			result.VolumeMatch = volumeMatch
+		}
+	}
+	if s.Breakout != nil {
+		breakout, ok := s.Breakout.getBreakout(window, s.Up)
+		match = match && ok && breakout.Match
+		if ok && result != nil {
+			result.Breakout = &breakout
+		}
+	}
+	if s.ZScore != nil {
+		zScore, zScoreMatch, ok := s.ZScore.getZScore(window)
+		match = match && ok && zScoreMatch
+		if ok && result != nil {
+			result.ZScore = &zScore
+			result.ZScoreMatch = zScoreMatch
+		}
+	}
+	if s.RelativeStrength != nil {
+		strength, strengthMatch, ok := s.RelativeStrength.getRelativeStrength(s, records, latestIndex, entryTime)
+		match = match && ok && strengthMatch
+		if ok && result != nil {
+			result.RelativeStrength = &strength
+			result.RelativeStrengthMatch = strengthMatch
+		}
+	}
+	if s.WeekendGap != nil {
+		gap, gapMatch, ok := s.WeekendGap.getGap(window)
+		match = match && ok && gapMatch
+		if ok && result != nil {
+			result.WeekendGap = &gap
+			result.WeekendGapMatch = gapMatch
+		}
+	}
+	if s.Donchian != nil {
+		position, positionMatch, ok := s.Donchian.getPosition(window)
+		match = match && ok && positionMatch
+		if ok && result != nil {
+			result.Donchian = &position
+			result.DonchianMatch = positionMatch
+		}
+	}
+	if s.EMASlope != nil {
+		slope, slopeMatch, ok := s.EMASlope.getSlope(window, s.Up)
+		match = match && ok && slopeMatch
+		if ok && result != nil {
+			result.EMASlope = &slope
+			result.EMASlopeMatch = slopeMatch
+		}
+	}
+	for i := range s.Gates {
+		gate := s.Gates[i].getGate(records, latestIndex, entryTime)
+		match = match && gate.Match
+		if result != nil {
+			result.Gates = append(result.Gates, gate)
+		}
+	}
+	if s.VWAP != nil {
+		deviation, deviationMatch, ok := s.VWAP.getDeviation(window)
+		match = match && ok && deviationMatch
+		if ok && result != nil {
+			result.VWAPDeviation = &deviation
+			result.VWAPMatch = deviationMatch
+		}
+	}
+	if s.Pattern != nil {
+		pattern, ok := s.Pattern.findPattern(window, s.Up)
+		match = match && pattern != ""
+		if ok && result != nil {
+			result.Pattern = &pattern
+		}
+	}
+	if s.Score != nil {
+		score, scoreMatch, ok := s.Score.getScore(records, latestIndex, entryTime)
+		match = match && ok && scoreMatch
+		if ok && result != nil {
+			result.Score = &score
+			result.ScoreMatch = scoreMatch
+		}
+	}
+	if s.Percentile != nil {
+		rank, rankMatch, ok := s.Percentile.getPercentileRank(s.Currency, window)
+		match = match && ok && rankMatch
+		if ok && result != nil {
+			result.PercentileRank = &rank
+			result.PercentileMatch = rankMatch
+		}
+	}
+	if s.RealizedVolatility != nil {
+		volatility, volatilityMatch, ok := s.RealizedVolatility.getVolatility(window)
+		match = match && ok && volatilityMatch
+		if ok && result != nil {
+			result.RealizedVolatility = &volatility
+			result.RealizedVolatilityMatch = volatilityMatch
+		}
+	}
+	if s.Correlation != nil {
+		correlation, correlationMatch, ok := s.Correlation.getCorrelation(records, latestIndex)
+		match = match && ok && correlationMatch
+		if ok && result != nil {
+			result.Correlation = &correlation
+			result.CorrelationMatch = correlationMatch
+		}
+	}
+	return match
+}
\ No newline at end of file
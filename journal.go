@@ -0,0 +1,248 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/encratite/commons"
+	_ "modernc.org/sqlite"
+)
+
+const (
+	journalFile = "journal.db"
+	outcomeWin = "win"
+	outcomeLoss = "loss"
+)
+
+const journalSchema = `
+create table if not exists journal (
+	id integer primary key autoincrement,
+	time integer not null,
+	type text not null,
+	strategy text not null,
+	currency text not null,
+	up integer not null,
+	order_id text,
+	client_order_id text,
+	price real,
+	quantity real,
+	reason text,
+	returns real
+);
+create index if not exists journal_strategy_time on journal (strategy, time);
+`
+
+var (
+	journal *sql.DB
+	journalOnce sync.Once
+)
+
+func getJournal() *sql.DB {
+	journalOnce.Do(func () {
+		err := os.MkdirAll(dataDirectory, 0755)
+		if err != nil {
+			commons.Fatalf("Failed to create directory %s: %v", dataDirectory, err)
+		}
+		db, err := sql.Open("sqlite", filepath.Join(dataDirectory, journalFile))
+		if err != nil {
+			commons.Fatalf("Failed to open journal: %v", err)
+		}
+		_, err = db.Exec(journalSchema)
+		if err != nil {
+			commons.Fatalf("Failed to initialize journal: %v", err)
+		}
+		journal = db
+	})
+	return journal
+}
+
+// Returns of positions closed without an exit price are unknown
+func getJournalReturns(state *engineState, e event) any {
+	if e.Type != eventPositionClosed {
+		return nil
+	}
+	p, exists := state.positions[e.Strategy]
+	strategy := findStrategy(e.Strategy)
+	if !exists || strategy == nil || e.Price <= 0 {
+		return nil
+	}
+	return strategy.getReturns(p.EntryPrice, e.Price)
+}
+
+// The event log remains the source of truth, a failure to write to the journal is only reported
+func writeJournal(e event, returns any) {
+	err := insertJournal(getJournal(), e, returns)
+	if err != nil {
+		fmt.Fprintf(statusOutput, "Failed to write to journal: %v\n", err)
+	}
+}
+
+type journalExecutor interface {
+	Exec(query string, arguments ...any) (sql.Result, error)
+}
+
+func insertJournal(db journalExecutor, e event, returns any) error {
+	_, err := db.Exec(
+		"insert into journal (time, type, strategy, currency, up, order_id, client_order_id, price, quantity, reason, returns) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		e.Time.UnixMilli(),
+		e.Type,
+		e.Strategy,
+		e.Currency,
+		e.Up,
+		e.OrderID,
+		e.ClientOrderID,
+		e.Price,
+		e.Quantity,
+		e.Reason,
+		returns,
+	)
+	return err
+}
+
+// Imports the events that precede the first journal entry, which covers the history recorded before the journal existed
+func backfillJournal() {
+	db := getJournal()
+	var first sql.NullInt64
+	err := db.QueryRow("select min(time) from journal").Scan(&first)
+	if err != nil {
+		commons.Fatalf("Failed to query journal: %v", err)
+	}
+	transaction, err := db.Begin()
+	if err != nil {
+		commons.Fatalf("Failed to start transaction: %v", err)
+	}
+	state := newEngineState()
+	count := 0
+	for _, e := range loadEvents() {
+		if first.Valid && e.Time.UnixMilli() >= first.Int64 {
+			break
+		}
+		err = insertJournal(transaction, e, getJournalReturns(&state, e))
+		if err != nil {
+			transaction.Rollback()
+			commons.Fatalf("Failed to write to journal: %v", err)
+		}
+		state.apply(e)
+		count++
+	}
+	err = transaction.Commit()
+	if err != nil {
+		commons.Fatalf("Failed to commit journal: %v", err)
+	}
+	fmt.Printf("Imported %d events into the journal\n", count)
+}
+
+func findStrategy(name string) *Strategy {
+	if configuration == nil {
+		return nil
+	}
+	for i := range configuration.Strategies {
+		if configuration.Strategies[i].Name == name {
+			return &configuration.Strategies[i]
+		}
+	}
+	return nil
+}
+
+func runJournal(arguments []string) {
+	flags := flag.NewFlagSet("journal", flag.ExitOnError)
+	strategyFilter := flags.String("strategy", "", "Only show entries of strategies whose names match this filter")
+	fromString := flags.String("from", "", "Only show entries from this date on (YYYY-MM-DD)")
+	toString := flags.String("to", "", "Only show entries before this date (YYYY-MM-DD)")
+	eventType := flags.String("type", "", "Only show entries of this type, e.g. signal, order, fill or positionClosed")
+	outcome := flags.String("outcome", "", "Only show closed positions with this outcome, either \"win\" or \"loss\"")
+	backfill := flags.Bool("backfill", false, "Import the events that were recorded before the journal was introduced")
+	flags.Parse(arguments)
+	if *backfill {
+		loadConfiguration()
+		backfillJournal()
+		return
+	}
+	query := "select time, type, strategy, currency, order_id, price, quantity, reason, returns from journal where strategy like ?"
+	parameters := []any{"%" + *strategyFilter + "%"}
+	if *fromString != "" {
+		from, err := time.Parse(time.DateOnly, *fromString)
+		if err != nil {
+			commons.Fatalf("Invalid start date: %s", *fromString)
+		}
+		query += " and time >= ?"
+		parameters = append(parameters, from.UnixMilli())
+	}
+	if *toString != "" {
+		to, err := time.Parse(time.DateOnly, *toString)
+		if err != nil {
+			commons.Fatalf("Invalid end date: %s", *toString)
+		}
+		query += " and time < ?"
+		parameters = append(parameters, to.UnixMilli())
+	}
+	if *eventType != "" {
+		query += " and type = ?"
+		parameters = append(parameters, *eventType)
+	}
+	switch *outcome {
+	case "":
+	case outcomeWin:
+		query += " and returns > 0"
+	case outcomeLoss:
+		query += " and returns <= 0"
+	default:
+		commons.Fatalf("Unknown outcome: %s", *outcome)
+	}
+	query += " order by time, id"
+	rows, err := getJournal().Query(query, parameters...)
+	if err != nil {
+		commons.Fatalf("Failed to query journal: %v", err)
+	}
+	defer rows.Close()
+	count := 0
+	total := 0.0
+	closed := 0
+	for rows.Next() {
+		var (
+			timestamp int64
+			entryType string
+			strategy string
+			currency string
+			orderID string
+			price float64
+			quantity float64
+			reason string
+			returns sql.NullFloat64
+		)
+		err = rows.Scan(&timestamp, &entryType, &strategy, &currency, &orderID, &price, &quantity, &reason, &returns)
+		if err != nil {
+			commons.Fatalf("Failed to read journal: %v", err)
+		}
+		fmt.Printf("%s UTC %s %s %s", commons.GetTimeString(time.UnixMilli(timestamp).UTC()), entryType, strategy, currency)
+		if orderID != "" {
+			fmt.Printf(" order %s", orderID)
+		}
+		if quantity != 0 {
+			fmt.Printf(" quantity %g", quantity)
+		}
+		if price != 0 {
+			fmt.Printf(" price %.4f", price)
+		}
+		if returns.Valid {
+			fmt.Printf(" returns %+.2f%%", returns.Float64 * percent)
+			total += returns.Float64
+			closed++
+		}
+		if reason != "" {
+			fmt.Printf(" (%s)", reason)
+		}
+		fmt.Printf("\n")
+		count++
+	}
+	fmt.Printf("\n%d entries", count)
+	if closed > 0 {
+		fmt.Printf(", %d closed positions with total returns of %+.2f%%", closed, total * percent)
+	}
+	fmt.Printf("\n")
+}
\ No newline at end of file
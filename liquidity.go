@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+type binanceTicker struct {
+	Symbol string `json:"symbol"`
+	LastPrice string `json:"lastPrice"`
+	QuoteVolume string `json:"quoteVolume"`
+	Count int64 `json:"count"`
+}
+
+func (s *Strategy) hasLiquidityFloor() bool {
+	return s.MinQuoteVolume != nil || s.MinTradeCount != nil
+}
+
+func getTicker(symbol string) (binanceTicker, error) {
+	url := "https://api.binance.com/api/v3/ticker/24hr"
+	parameters := map[string]string{
+		"symbol": symbol,
+	}
+	start := time.Now()
+	ticker, err := commons.DownloadJSON[binanceTicker](url, parameters)
+	recordFetch(providerBinance, time.Since(start), err)
+	if err != nil {
+		return ticker, fmt.Errorf("failed to download 24h ticker from Binance: %v", err)
+	}
+	return ticker, nil
+}
+
+// Liquidity is only checked once all other conditions match to avoid an additional request for every evaluation
+func (s *Strategy) checkLiquidity(result *EvaluationResult) error {
+	result.LiquidityMatch = true
+	if !s.hasLiquidityFloor() {
+		return nil
+	}
+	if isHistoricalEvaluation() {
+		result.addMessage("Liquidity is only available live and was not checked")
+		return nil
+	}
+	ticker, err := getTicker(s.Currency)
+	if err != nil {
+		return err
+	}
+	quoteVolume, err := strconv.ParseFloat(ticker.QuoteVolume, 64)
+	if err != nil {
+		return fmt.Errorf("invalid quote volume: %s", ticker.QuoteVolume)
+	}
+	result.QuoteVolume = &quoteVolume
+	result.TradeCount = &ticker.Count
+	if s.MinQuoteVolume != nil && quoteVolume < *s.MinQuoteVolume {
+		result.LiquidityMatch = false
+	}
+	if s.MinTradeCount != nil && ticker.Count < *s.MinTradeCount {
+		result.LiquidityMatch = false
+	}
+	return nil
+}
\ No newline at end of file
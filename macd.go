@@ -0,0 +1,127 @@
+package main
+
+import (
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	defaultMACDFast = 12
+	defaultMACDSlow = 26
+	defaultMACDSignal = 9
+	macdPositive = "positive"
+	macdNegative = "negative"
+)
+
+type MACDConfiguration struct {
+	Fast int `yaml:"fast"`
+	Slow int `yaml:"slow"`
+	Signal int `yaml:"signal"`
+	IntervalMinutes int `yaml:"intervalMinutes"`
+	Histogram string `yaml:"histogram"`
+	CrossBars int `yaml:"crossBars"`
+}
+
+type macdResult struct {
+	Line float64 `json:"line"`
+	Signal float64 `json:"signal"`
+	Histogram float64 `json:"histogram"`
+	Match bool `json:"match"`
+}
+
+func (c *MACDConfiguration) validate(strategy string) {
+	if c.Fast < 0 || c.Slow < 0 || c.Signal < 0 || c.CrossBars < 0 {
+		commons.Fatalf("Invalid MACD parameters for strategy %s", strategy)
+	}
+	if c.getSlow() <= c.getFast() {
+		commons.Fatalf("The slow MACD period must exceed the fast one for strategy %s", strategy)
+	}
+	if c.Histogram != "" && c.Histogram != macdPositive && c.Histogram != macdNegative {
+		commons.Fatalf("Unknown MACD histogram condition for strategy %s: %s", strategy, c.Histogram)
+	}
+	if c.Histogram == "" && c.CrossBars == 0 {
+		commons.Fatalf("Missing MACD condition for strategy %s", strategy)
+	}
+	validateIndicatorInterval(c.IntervalMinutes, "MACD", strategy)
+}
+
+func (c *MACDConfiguration) getFast() int {
+	if c.Fast == 0 {
+		return defaultMACDFast
+	}
+	return c.Fast
+}
+
+func (c *MACDConfiguration) getSlow() int {
+	if c.Slow == 0 {
+		return defaultMACDSlow
+	}
+	return c.Slow
+}
+
+func (c *MACDConfiguration) getSignal() int {
+	if c.Signal == 0 {
+		return defaultMACDSignal
+	}
+	return c.Signal
+}
+
+func (c *MACDConfiguration) getInterval() time.Duration {
+	return getIndicatorInterval(c.IntervalMinutes)
+}
+
+// Like the moving averages, the EMAs are calculated over a fixed window so that they don't depend on the amount of history available
+func (c *MACDConfiguration) getBarCount() int {
+	return 2 * (c.getSlow() + c.getSignal()) + c.CrossBars
+}
+
+func (c *MACDConfiguration) getLookback() time.Duration {
+	return time.Duration(c.getBarCount() + 1) * c.getInterval()
+}
+
+func (c *MACDConfiguration) getMACD(records []ohlcRecord, up bool) (macdResult, bool) {
+	bars := getBars(records, c.getInterval())
+	if len(bars) < c.getBarCount() {
+		return macdResult{}, false
+	}
+	closes := []float64{}
+	for _, bar := range bars[len(bars) - c.getBarCount():] {
+		closes = append(closes, bar.close)
+	}
+	fast := getEMASeries(closes, c.getFast())
+	slow := getEMASeries(closes, c.getSlow())
+	line := []float64{}
+	for i := range slow {
+		line = append(line, fast[i + c.getSlow() - c.getFast()] - slow[i])
+	}
+	signal := getEMASeries(line, c.getSignal())
+	histogram := []float64{}
+	for i := range signal {
+		histogram = append(histogram, line[i + c.getSignal() - 1] - signal[i])
+	}
+	last := len(histogram) - 1
+	result := macdResult{
+		Line: line[len(line) - 1],
+		Signal: signal[last],
+		Histogram: histogram[last],
+		Match: true,
+	}
+	switch c.Histogram {
+	case macdPositive:
+		result.Match = result.Histogram > 0
+	case macdNegative:
+		result.Match = result.Histogram < 0
+	}
+	// The signal line has to have been crossed in the direction of the strategy within the last bars
+	if c.CrossBars > 0 {
+		crossed := false
+		for i := last - c.CrossBars + 1; i <= last; i++ {
+			if up && histogram[i - 1] <= 0 && histogram[i] > 0 || !up && histogram[i - 1] >= 0 && histogram[i] < 0 {
+				crossed = true
+			}
+		}
+		result.Match = result.Match && crossed
+	}
+	return result, true
+}
\ No newline at end of file
@@ -4,32 +4,107 @@ import (
 	"encoding/json"
 	"fmt"
 	"flag"
-	"math"
-	"slices"
+	"io"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/encratite/commons"
+	"github.com/expr-lang/expr/vm"
 	"github.com/fatih/color"
 )
 
 const (
 	percent = 100.0
+	exitNoSignals = 0
+	exitSignals = 2
+	exitDataError = 3
 )
 
 type Configuration struct {
+	Fees float64 `yaml:"fees"`
+	Slippage float64 `yaml:"slippage"`
+	AdverseFill float64 `yaml:"adverseFill"`
+	RolloverHour int `yaml:"rolloverHour"`
+	Paper PaperConfiguration `yaml:"paper"`
+	Exchanges map[string]ExchangeConfiguration `yaml:"exchanges"`
+	Risk *RiskConfiguration `yaml:"risk"`
+	Hooks []HookConfiguration `yaml:"hooks"`
+	Notifications NotificationConfiguration `yaml:"notifications"`
+	Sessions map[string]SessionConfiguration `yaml:"sessions"`
+	Timezone string `yaml:"timezone"`
+	Holidays map[string][]string `yaml:"holidays"`
 	Strategies []Strategy `yaml:"strategies"`
 }
 
 type Strategy struct {
 	Name string `yaml:"name"`
 	Currency string `yaml:"currency"`
-	Offset int `yaml:"offset"`
+	Offset MomentumOffset `yaml:"offset"`
 	GreaterThan *float64 `yaml:"greaterThan"`
 	LessThan *float64 `yaml:"lessThan"`
+	MomentumType string `yaml:"momentumType"`
+	MomentumHalfLife *float64 `yaml:"momentumHalfLife"`
+	PriceAbove *float64 `yaml:"priceAbove"`
+	PriceBelow *float64 `yaml:"priceBelow"`
+	Momentum []MomentumConfiguration `yaml:"momentum"`
+	Timeframes []TimeframeConfiguration `yaml:"timeframes"`
 	Weekdays []commons.SerializableWeekday `yaml:"weekdays"`
 	Times []commons.SerializableDuration `yaml:"times"`
+	TimeRanges []TimeRangeConfiguration `yaml:"timeRanges"`
+	MarketTimes []MarketTimeConfiguration `yaml:"marketTimes"`
+	Schedule string `yaml:"schedule"`
+	ExcludeHolidays []string `yaml:"excludeHolidays"`
+	Sessions []string `yaml:"sessions"`
+	Timezone string `yaml:"timezone"`
+	ToleranceMinutes int `yaml:"toleranceMinutes"`
+	AllowGaps bool `yaml:"allowGaps"`
+	Calendar *CalendarConfiguration `yaml:"calendar"`
 	Up bool `yaml:"up"`
+	BreakEven *float64 `yaml:"breakEven"`
+	StopLoss *float64 `yaml:"stopLoss"`
+	TakeProfit *float64 `yaml:"takeProfit"`
+	HoldHours int `yaml:"holdHours"`
+	// Loss of the strategy within a trading day in percent of its position sizes, beyond which it is muted until the next day
+	DailyLossPercent *float64 `yaml:"dailyLossPercent"`
+	Weight *float64 `yaml:"weight"`
+	MinQuoteVolume *float64 `yaml:"minQuoteVolume"`
+	MinTradeCount *int64 `yaml:"minTradeCount"`
+	Funding *FundingConfiguration `yaml:"funding"`
+	OpenInterest *OpenInterestConfiguration `yaml:"openInterest"`
+	Consensus *ConsensusConfiguration `yaml:"consensus"`
+	RSI *RSIConfiguration `yaml:"rsi"`
+	MovingAverages []MovingAverageConfiguration `yaml:"movingAverages"`
+	MACD *MACDConfiguration `yaml:"macd"`
+	Bollinger *BollingerConfiguration `yaml:"bollinger"`
+	Volatility *VolatilityConfiguration `yaml:"volatility"`
+	Volume *VolumeConfiguration `yaml:"volume"`
+	Breakout *BreakoutConfiguration `yaml:"breakout"`
+	ZScore *ZScoreConfiguration `yaml:"zScore"`
+	RelativeStrength *RelativeStrengthConfiguration `yaml:"relativeStrength"`
+	WeekendGap *WeekendGapConfiguration `yaml:"weekendGap"`
+	Donchian *DonchianConfiguration `yaml:"donchian"`
+	EMASlope *EMASlopeConfiguration `yaml:"emaSlope"`
+	Gates []GateConfiguration `yaml:"gates"`
+	VWAP *VWAPConfiguration `yaml:"vwap"`
+	Pattern *PatternConfiguration `yaml:"pattern"`
+	Score *ScoreConfiguration `yaml:"score"`
+	Percentile *PercentileConfiguration `yaml:"percentile"`
+	RealizedVolatility *RealizedVolatilityConfiguration `yaml:"realizedVolatility"`
+	Correlation *CorrelationConfiguration `yaml:"correlation"`
+	Conditions *ConditionConfiguration `yaml:"conditions"`
+	EmailRecipients []string `yaml:"emailRecipients"`
+	Notify []string `yaml:"notify"`
+	Trailing *TrailingConfiguration `yaml:"trailing"`
+	Expression string `yaml:"expression"`
+	Order *OrderConfiguration `yaml:"order"`
+	expression *vm.Program
+	expressionLookback time.Duration
+	location *time.Location
+	schedule *cronSchedule
+	holidays map[string]bool
+	gaps []candleGap
 }
 
 type ohlcRecord struct {
@@ -38,33 +113,276 @@ type ohlcRecord struct {
 	high float64
 	low float64
 	close float64
+	volume float64
 }
 
 var configuration *Configuration
 
+var klinesURL = "https://www.binance.com/api/v3/uiKlines"
+
+// Set while viewers evaluate strategies, their signals are only displayed and never recorded or acted upon
+var previewing bool
+
 func main() {
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		runCommand(os.Args[1], os.Args[2:])
+		return
+	}
 	strategyFilter := flag.String("strategy", "", "Restrict evaluation of strategies to ones whose names match this filter")
+	flag.BoolVar(&paperTrading, "paper", false, "Simulate fills against live prices and track them in a virtual account")
+	flag.BoolVar(&executeOrders, "execute", false, "Place orders on Binance for strategies whose conditions all match")
+	flag.BoolVar(&killSwitch, "kill-switch", false, "Suspend all order placement while still evaluating strategies")
+	flag.StringVar(&reconcileMode, "reconcile", reconcileReport, "How positions and orders that don't match the exchange are handled on startup: report, adopt or close")
+	flag.BoolVar(&dryRun, "dry-run", false, "Go through the execution path and print the orders that would be placed without submitting them")
+	format := flag.String("format", formatConsole, "Output format: console, json, markdown, csv or webhook")
+	flag.BoolVar(&csvHeader, "csv-header", true, "Write a header row in the CSV format, disable it when appending to an existing log file")
+	webhookURL := flag.String("webhook", "", "URL the evaluation results are posted to when using the webhook format")
+	flag.BoolVar(&quietMode, "quiet", false, "Only print strategies whose conditions all match and suppress all other output")
+	flag.BoolVar(&daemonMode, "daemon", false, "Keep running and re-evaluate the strategies at a fixed interval")
+	flag.DurationVar(&daemonInterval, "interval", candleInterval, "Interval between evaluations in daemon mode")
+	flag.StringVar(&metricsAddress, "metrics", "", "Serve Prometheus metrics on this address in daemon mode, e.g. 127.0.0.1:9100")
+	at := flag.String("at", "", "Evaluate the strategies as if the current time were this RFC3339 timestamp, using only the candles available then")
+	flag.IntVar(&sparklineHours, "sparkline", defaultSparklineHours, "Number of hours of closes shown in the sparkline below each strategy, 0 to disable it")
+	next := flag.Bool("next", false, "Show when the weekday and time conditions of each strategy will next be satisfied instead of evaluating them")
 	flag.Parse()
+	if dryRun {
+		executeOrders = true
+	}
+	if *at != "" {
+		setEvaluationTime(*at)
+	}
+	validateSparklineHours()
+	if metricsAddress != "" && !daemonMode {
+		commons.Fatalf("The metrics endpoint is only available in daemon mode")
+	}
 	loadConfiguration()
-	evaluateStrategies(*strategyFilter)
+	if *next {
+		printNextTriggers(*strategyFilter)
+		return
+	}
+	if executeOrders {
+		validateReconcileMode()
+		reconcile(*strategyFilter)
+	}
+	if daemonMode {
+		runDaemon(*strategyFilter, *format, *webhookURL)
+		return
+	}
+	renderer := newRenderer(*format, *webhookURL)
+	signals, failures := evaluateStrategies(*strategyFilter, renderer)
+	os.Exit(getExitCode(signals, failures))
+}
+
+// A signal takes precedence over failures of other strategies since it still has to be acted upon, fatal errors exit with 1
+func getExitCode(signals int, failures int) int {
+	if signals > 0 {
+		return exitSignals
+	} else if failures > 0 {
+		return exitDataError
+	}
+	return exitNoSignals
+}
+
+func runCommand(command string, arguments []string) {
+	switch command {
+	case "backtest":
+		runBacktest(arguments)
+	case "compare":
+		runCompare(arguments)
+	case "optimize":
+		runOptimize(arguments)
+	case "robustness":
+		runRobustness(arguments)
+	case "calibrate":
+		runCalibrate(arguments)
+	case "replay":
+		runReplay(arguments)
+	case "position":
+		runPosition(arguments)
+	case "dataset":
+		runDataset(arguments)
+	case "credentials":
+		runCredentials(arguments)
+	case "journal":
+		runJournal(arguments)
+	case "account":
+		runAccount(arguments)
+	case "manage":
+		runManage(arguments)
+	case "dashboard":
+		runDashboard(arguments)
+	case "serve":
+		runServe(arguments)
+	default:
+		commons.Fatalf("Unknown command: %s", command)
+	}
+}
+
+func parseArguments(flags *flag.FlagSet, arguments []string) []string {
+	positional := []string{}
+	for {
+		flags.Parse(arguments)
+		arguments = flags.Args()
+		if len(arguments) == 0 {
+			break
+		}
+		positional = append(positional, arguments[0])
+		arguments = arguments[1:]
+	}
+	return positional
 }
 
 func loadConfiguration() {
-	configuration = commons.LoadConfiguration[Configuration]("configuration/configuration.yaml")
-	configuration.validate()
+	configuration = loadConfigurationFile("configuration/configuration.yaml")
+}
+
+func loadConfigurationFile(path string) *Configuration {
+	output := commons.LoadConfiguration[Configuration](path)
+	output.validate()
+	return output
 }
 
-func evaluateStrategies(filter string) {
-	fmt.Printf("\n")
+func evaluateStrategies(filter string, renderer Renderer) (int, int) {
+	if quietMode {
+		statusOutput = io.Discard
+	}
+	fmt.Fprintf(statusOutput, "\n")
+	if paperTrading {
+		paper = loadPaperAccount()
+		updatePaperAccount(filter)
+	}
+	if executeOrders {
+		checkRiskGuard()
+		syncEntryOrders(filter)
+		syncProtectiveOrders(filter)
+		if suspensionReason == "" {
+			closeExpiredPositions(filter)
+			updateTrailingStops(filter)
+			updateBreakEvenStops(filter)
+		}
+	}
+	signals := 0
+	failures := 0
+	rendered := 0
 	for _, strategy := range configuration.Strategies {
-		if filter != "" && !strings.Contains(strategy.Name, filter) {
+		if !strategy.matchesFilter(filter) {
 			continue
 		}
-		strategy.evaluate()
+		// Past evaluations are only rendered, they don't trigger any hooks or notifications
+		historical := isHistoricalEvaluation()
+		if !historical {
+			runHooks(hookBeforeEvaluation, *strategy.getEmptyResult())
+		}
+		result, err := strategy.safeEvaluate()
+		if err != nil {
+			result = strategy.getEmptyResult()
+			result.Error = err.Error()
+			if quietMode {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", strategy.Name, result.Error)
+			}
+			if !historical {
+				runHooks(hookError, *result)
+			}
+			failures++
+		} else if !historical && result != nil && result.Suppressed && !result.Duplicate {
+			runHooks(hookSuppressed, *result)
+		} else if !historical && result != nil && result.Signal && !result.Duplicate {
+			runHooks(hookSignal, *result)
+		}
+		recordEvaluation(strategy.Name, result)
+		if result != nil {
+			if result.Signal && !result.Suppressed {
+				signals++
+			}
+			if !historical {
+				sendNotifications(*result)
+			}
+			if !quietMode || result.Signal {
+				renderer.Render(*result)
+				rendered++
+			}
+		}
+	}
+	if !quietMode || rendered > 0 {
+		renderer.Close()
+	}
+	if failures > 0 {
+		fmt.Fprintf(statusOutput, "Failed to evaluate %d strategies\n\n", failures)
+	}
+	if paperTrading {
+		printPaperAccount()
+		paper.save()
 	}
+	printSourceMetrics()
+	return signals, failures
+}
+
+func (s *Strategy) getEmptyResult() *EvaluationResult {
+	return &EvaluationResult{
+		Strategy: s.Name,
+		Currency: s.Currency,
+		Up: s.Up,
+		Time: getNow(),
+	}
+}
+
+func (s *Strategy) matchesFilter(filter string) bool {
+	return filter == "" || strings.Contains(s.Name, filter)
+}
+
+// Errors and panics only affect the strategy being evaluated so that the remaining ones still complete
+func (s *Strategy) safeEvaluate() (result *EvaluationResult, err error) {
+	defer func () {
+		recovered := recover()
+		if recovered != nil {
+			result = nil
+			err = fmt.Errorf("panic during evaluation: %v", recovered)
+		}
+	}()
+	return s.evaluate()
+}
+
+// Evaluates the strategy without emitting signals, hooks or notifications so that viewers don't mark the signals of later runs as duplicates
+func (s *Strategy) preview() (*EvaluationResult, error) {
+	previewing = true
+	defer func () {
+		previewing = false
+	}()
+	return s.safeEvaluate()
 }
 
 func (c *Configuration) validate() {
+	if c.Fees < 0 {
+		commons.Fatalf("Invalid fees")
+	}
+	if c.Slippage < 0 {
+		commons.Fatalf("Invalid slippage")
+	}
+	if c.AdverseFill < 0 || c.AdverseFill > 1 {
+		commons.Fatalf("Invalid adverse fill ratio")
+	}
+	if c.RolloverHour < 0 || c.RolloverHour > 23 {
+		commons.Fatalf("Invalid rollover hour")
+	}
+	c.Paper.validate()
+	c.validateExchanges()
+	if c.Risk != nil {
+		c.Risk.validate()
+	}
+	for _, hook := range c.Hooks {
+		hook.validate()
+	}
+	c.Notifications.validate()
+	sessions := c.getSessions()
+	for i := range c.Strategies {
+		c.Strategies[i].normalizeMomentum()
+		c.Strategies[i].normalizeSessions(sessions)
+		c.Strategies[i].compileExpression()
+		c.Strategies[i].compileSchedule()
+		c.Strategies[i].normalizeMarketTimes()
+		c.Strategies[i].normalizeHolidays(c.Holidays)
+		c.Strategies[i].normalizeTimezone(c.Timezone)
+	}
 	for _, strategy := range c.Strategies {
 		if strategy.Name == "" {
 			commons.Fatalf("Missing strategy name")
@@ -72,23 +390,158 @@ func (c *Configuration) validate() {
 		if strategy.Currency == "" {
 			commons.Fatalf("Missing currency name for strategy %s", strategy.Name)
 		}
-		if strategy.Offset <= 0 {
+		if !strategy.Offset.isValid() {
 			commons.Fatalf("Invalid offset for strategy %s", strategy.Name)
 		}
-		if strategy.GreaterThan == nil && strategy.LessThan == nil {
+		if strategy.GreaterThan == nil && strategy.LessThan == nil && strategy.Conditions == nil && strategy.Expression == "" {
 			commons.Fatalf("Missing momentum constraint for strategy %s", strategy.Name)
 		}
+		strategy.validateMomentumType()
+		for _, t := range strategy.Times {
+			if t.Duration < 0 || t.Duration >= 24 * time.Hour || t.Duration % candleInterval != 0 {
+				commons.Fatalf("Invalid time for strategy %s: %s", strategy.Name, commons.GetTimeOfDayString(t.Duration))
+			}
+		}
+		for _, timeRange := range strategy.TimeRanges {
+			timeRange.validate(strategy.Name)
+		}
+		if strategy.ToleranceMinutes < 0 {
+			commons.Fatalf("Invalid tolerance for strategy %s", strategy.Name)
+		}
+		for _, lookback := range strategy.Momentum {
+			lookback.validate(strategy.Name)
+		}
+		for _, timeframe := range strategy.Timeframes {
+			timeframe.validate(strategy.Name)
+		}
+		if strategy.PriceAbove != nil && *strategy.PriceAbove <= 0 || strategy.PriceBelow != nil && *strategy.PriceBelow <= 0 {
+			commons.Fatalf("Invalid price constraint for strategy %s", strategy.Name)
+		}
+		if strategy.PriceAbove != nil && strategy.PriceBelow != nil && *strategy.PriceAbove >= *strategy.PriceBelow {
+			commons.Fatalf("Lower price bound must be below the upper price bound for strategy %s", strategy.Name)
+		}
+		if strategy.BreakEven != nil && *strategy.BreakEven <= 0 {
+			commons.Fatalf("Invalid break-even threshold for strategy %s", strategy.Name)
+		}
+		if strategy.StopLoss != nil && *strategy.StopLoss <= 0 {
+			commons.Fatalf("Invalid stop-loss for strategy %s", strategy.Name)
+		}
+		if strategy.TakeProfit != nil && *strategy.TakeProfit <= 0 {
+			commons.Fatalf("Invalid take-profit for strategy %s", strategy.Name)
+		}
+		if strategy.HoldHours < 0 {
+			commons.Fatalf("Invalid holding period for strategy %s", strategy.Name)
+		}
+		if strategy.DailyLossPercent != nil && *strategy.DailyLossPercent <= 0 {
+			commons.Fatalf("Invalid daily loss limit for strategy %s", strategy.Name)
+		}
+		if strategy.MinQuoteVolume != nil && *strategy.MinQuoteVolume <= 0 {
+			commons.Fatalf("Invalid minimum quote volume for strategy %s", strategy.Name)
+		}
+		if strategy.MinTradeCount != nil && *strategy.MinTradeCount <= 0 {
+			commons.Fatalf("Invalid minimum trade count for strategy %s", strategy.Name)
+		}
+		if strategy.Calendar != nil {
+			strategy.Calendar.validate(strategy.Name)
+		}
+		if strategy.Funding != nil {
+			strategy.validateFunding()
+		}
+		if strategy.OpenInterest != nil {
+			strategy.validateOpenInterest()
+		}
+		if strategy.Consensus != nil {
+			strategy.Consensus.validate(strategy.Name)
+		}
+		if strategy.RSI != nil {
+			strategy.RSI.validate(strategy.Name)
+		}
+		for _, movingAverage := range strategy.MovingAverages {
+			movingAverage.validate(strategy.Name)
+		}
+		if strategy.MACD != nil {
+			strategy.MACD.validate(strategy.Name)
+		}
+		if strategy.Bollinger != nil {
+			strategy.Bollinger.validate(strategy.Name)
+		}
+		if strategy.Volatility != nil {
+			strategy.Volatility.validate(strategy.Name)
+		}
+		if strategy.Volume != nil {
+			strategy.Volume.validate(strategy.Name)
+		}
+		if strategy.Breakout != nil {
+			strategy.Breakout.validate(strategy.Name)
+		}
+		if strategy.ZScore != nil {
+			strategy.ZScore.validate(strategy.Name)
+		}
+		if strategy.RelativeStrength != nil {
+			strategy.RelativeStrength.validate(strategy.Name)
+		}
+		if strategy.WeekendGap != nil {
+			strategy.WeekendGap.validate(strategy.Name)
+		}
+		if strategy.Donchian != nil {
+			strategy.Donchian.validate(strategy.Name)
+		}
+		if strategy.EMASlope != nil {
+			strategy.EMASlope.validate(strategy.Name)
+		}
+		for _, gate := range strategy.Gates {
+			gate.validate(strategy.Name)
+		}
+		if strategy.VWAP != nil {
+			strategy.VWAP.validate(strategy.Name)
+		}
+		if strategy.Pattern != nil {
+			strategy.Pattern.validate(strategy.Name)
+		}
+		if strategy.Score != nil {
+			strategy.Score.validate(strategy.Name)
+		}
+		if strategy.Percentile != nil {
+			strategy.Percentile.validate(strategy.Name)
+		}
+		if strategy.RealizedVolatility != nil {
+			strategy.RealizedVolatility.validate(strategy.Name)
+		}
+		if strategy.Correlation != nil {
+			strategy.Correlation.validate(strategy.Name)
+		}
+		if strategy.Conditions != nil {
+			strategy.Conditions.validate(strategy.Name)
+		}
+		strategy.validateWarmUp()
+		c.Notifications.validateChannels("strategy " + strategy.Name, strategy.Notify)
+		if strategy.Trailing != nil {
+			strategy.Trailing.validate(strategy.Name)
+		}
+		if strategy.Weight != nil && *strategy.Weight <= 0 {
+			commons.Fatalf("Invalid weight for strategy %s", strategy.Name)
+		}
+		if strategy.Order != nil {
+			strategy.validateOrder()
+		}
 	}
 }
 
-func (s *Strategy) evaluate() {
-	records := loadRecords(s.Currency)
-	now := time.Now().UTC()
-	weekday := now.Weekday()
-	weekdays := []time.Weekday{}
+func (s *Strategy) evaluate() (*EvaluationResult, error) {
+	records, err := s.loadRecords(s.Currency)
+	if err != nil {
+		return nil, err
+	}
+	s.gaps = findGaps(records)
+	records, anomalies := filterRecords(records)
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no candles available for %s", s.Currency)
+	}
+	recordStaleness(providerBinance, s.Currency, records[len(records) - 1].timestamp)
+	now := getNow()
+	localNow := now.In(s.getLocation())
 	weekdayNames := []string{}
 	for _, w := range s.Weekdays {
-		weekdays = append(weekdays, w.Weekday)
 		weekdayNames = append(weekdayNames, fmt.Sprintf("%s", w.Weekday))
 	}
 	timeStrings := []string{}
@@ -96,146 +549,271 @@ func (s *Strategy) evaluate() {
 		timeString := commons.GetTimeOfDayString(t.Duration)
 		timeStrings = append(timeStrings, timeString)
 	}
-	weekdayMatch := slices.Contains(weekdays, weekday)
+	for i := range s.TimeRanges {
+		timeStrings = append(timeStrings, s.TimeRanges[i].String())
+	}
+	for i := range s.MarketTimes {
+		timeStrings = append(timeStrings, s.MarketTimes[i].String())
+	}
+	entryTime, timeMatch := s.getPendingEntry(now)
+	weekdayMatch := timeMatch || s.isEntryDay(localNow)
 	if !weekdayMatch {
-		return
+		return nil, nil
 	}
-	timeMatch := false
-	timeInRange := false
-	for _, t := range s.Times {
-		hours := int(t.Hours())
-		if now.Hour() <= hours {
+	timeInRange := timeMatch
+	for _, t := range s.getEntryTimes(localNow) {
+		if !t.Before(now.Truncate(time.Hour)) {
 			timeInRange = true
 		}
-		if now.Hour() + 1 == hours {
-			timeMatch = true
-			break
-		}
+	}
+	if !timeMatch {
+		entryTime = getEntryWindow(now)
 	}
 	if timeInRange == false {
-		return
+		return nil, nil
 	}
-	momentumTime := now.Add(time.Duration(1 - s.Offset) * time.Hour)
-	truncatedTime := time.Date(
-		momentumTime.Year(),
-		momentumTime.Month(),
-		momentumTime.Day(),
-		momentumTime.Hour(),
-		0,
-		0,
-		0,
-		momentumTime.Location(),
-	)
-	momentumMatch := false
-	momentum := math.NaN()
 	lastIndex := len(records) - 1
 	latestRecord := records[lastIndex]
-	var momentumRecord ohlcRecord
-	foundRecord := false
-	for i := range records {
-		record := records[lastIndex - i]
-		if !record.timestamp.After(truncatedTime) {
-			momentum = (latestRecord.close / record.open - 1.0) * percent
-			match := true
-			if s.GreaterThan != nil {
-				match = match && momentum > *s.GreaterThan
-			}
-			if s.LessThan != nil {
-				match = match && momentum < *s.LessThan
-			}
-			momentumMatch = match
-			momentumRecord = record
-			foundRecord = true
-			break
+	result := &EvaluationResult{
+		Strategy: s.Name,
+		Currency: s.Currency,
+		Weekdays: weekdayNames,
+		Times: timeStrings,
+		Schedule: s.Schedule,
+		Timezone: s.getLocation().String(),
+		Offset: s.Offset.String(),
+		MomentumType: s.MomentumType,
+		GreaterThan: s.GreaterThan,
+		LessThan: s.LessThan,
+		Up: s.Up,
+		CurrentPrice: latestRecord.close,
+		PriceAbove: s.PriceAbove,
+		PriceBelow: s.PriceBelow,
+		PriceMatch: s.getPriceMatch(latestRecord.close),
+		Sparkline: getSparklineCloses(records),
+		Time: now,
+		EntryTime: entryTime,
+		WeekdayMatch: weekdayMatch,
+		TimeMatch: timeMatch,
+		Position: s.getPosition(),
+	}
+	if len(anomalies) > 0 {
+		result.Anomalies = strings.Split(formatAnomalies(anomalies), ", ")
+	}
+	gaps := s.getMomentumGaps(entryTime)
+	result.Gaps = formatGaps(gaps)
+	result.GapMatch = s.AllowGaps || len(gaps) == 0
+	anchorIndex := findRecord(records, s.getAnchorTime(entryTime))
+	if anchorIndex < lastIndex {
+		record := records[anchorIndex]
+		momentum := s.getMomentum(records, anchorIndex, lastIndex)
+		result.Momentum = &momentum
+		result.MomentumMatch = s.getMomentumMatch(momentum)
+		result.MomentumPrice = &record.close
+		result.MomentumTime = &record.timestamp
+		agreements, consensusMatch := s.getConsensus(records, lastIndex, entryTime, momentum)
+		result.ConsensusMatch = consensusMatch
+		if s.Consensus != nil {
+			result.ConsensusAgreements = &agreements
+			result.ConsensusHorizons = len(s.Consensus.Horizons)
 		}
+		result.LookbackMatch = s.getLookbackMatch(records, lastIndex, entryTime, result)
 	}
-	blue := color.New(color.FgBlue).SprintFunc()
-	green := color.New(color.FgGreen).SprintFunc()
-	red := color.New(color.FgRed).SprintFunc()
-	fmt.Printf("%s:\n", s.Name)
-	fmt.Printf("\tCurrency: %s\n", blue(s.Currency))
-	fmt.Printf("\tWeekdays: %s\n", strings.Join(weekdayNames, ", "))
-	fmt.Printf("\tTimes: %s\n", strings.Join(timeStrings, ", "))
-	fmt.Printf("\tMomentum offset: %dh\n", s.Offset)
-	if s.GreaterThan != nil {
-		fmt.Printf("\tGreater than: %.2f%%\n", *s.GreaterThan)
-	}
-	if s.LessThan != nil {
-		fmt.Printf("\tLess than: %.2f%%\n", *s.LessThan)
-	}
-	var sideString string
-	if s.Up {
-		sideString = green("Up")
-	} else {
-		sideString = red("Down")
+	err = s.loadLiveReferences()
+	if err != nil {
+		return nil, err
 	}
-	fmt.Printf("\tSide: %s\n", sideString)
-	fmt.Printf("\tCurrent price: %.4f\n", latestRecord.close)
-	if foundRecord {
-		fmt.Printf("\tMomentum price: %.4f\n", momentumRecord.close)
-		fmt.Printf("\tMomentum time: %s UTC\n", commons.GetTimeString(momentumRecord.timestamp))
-	} else {
-		fmt.Printf("\tMomentum price: %s\n", red("missing"))
+	err = s.loadLiveTimeframes()
+	if err != nil {
+		return nil, err
+	}
+	err = s.loadLivePercentile()
+	if err != nil {
+		return nil, err
 	}
-	fmt.Printf("\tCurrent weekday: %s (%s)\n", weekday, formatBool(weekdayMatch))
-	fmt.Printf("\tCurrent time of day: %02d:%02d UTC (%s)\n", now.Hour(), now.Minute(), formatBool(timeMatch))
-	fmt.Printf("\tCurrent momentum: %+.2f%% (%s)\n", momentum, formatBool(momentumMatch))
-	if weekdayMatch && timeMatch && momentumMatch {
-		fmt.Printf("\n\tAll conditions match, open \"%s\" position\n", sideString)
+	result.TimeframeMatch = s.getTimeframeMatch(entryTime, result)
+	result.IndicatorMatch = s.getIndicatorMatch(records, lastIndex, entryTime, result)
+	conditionMatch := s.getConditionMatch(records, lastIndex, entryTime)
+	if s.Conditions != nil {
+		result.ConditionMatch = &conditionMatch
 	}
-	fmt.Printf("\n")
+	expressionMatch, err := s.getExpressionMatch(records, lastIndex, entryTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate expression: %v", err)
+	}
+	if s.expression != nil {
+		result.ExpressionMatch = &expressionMatch
+	}
+	if weekdayMatch && timeMatch && result.GapMatch && result.PriceMatch && result.ConsensusMatch && result.MomentumMatch && result.LookbackMatch && result.TimeframeMatch && result.IndicatorMatch && conditionMatch && expressionMatch {
+		err := s.checkLiquidity(result)
+		if err != nil {
+			return nil, err
+		}
+		err = s.checkFunding(result)
+		if err != nil {
+			return nil, err
+		}
+		err = s.checkOpenInterest(result)
+		if err != nil {
+			return nil, err
+		}
+		if previewing {
+			result.Signal = true
+			s.suppress(result)
+		} else {
+			s.onSignal(result)
+		}
+	}
+	return result, nil
 }
 
-func loadRecords(currency string) []ohlcRecord {
-	now := time.Now().UTC()
-	unixMilliseconds := now.UnixMilli()
-	url := "https://www.binance.com/api/v3/uiKlines"
-	parameters := map[string]string{
-		"symbol": currency,
-		"interval": "5m",
-		"limit": "1000",
-		"endTime": commons.Int64ToString(unixMilliseconds),
+func (s *Strategy) onSignal(result *EvaluationResult) {
+	price := result.CurrentPrice
+	momentum := *result.Momentum
+	result.Signal = true
+	signal := event{
+		Type: eventSignal,
+		Strategy: s.Name,
+		Currency: s.Currency,
+		Up: s.Up,
+		Price: price,
+		Momentum: momentum,
+		EntryTime: result.getEntryWindow(),
 	}
-	data, err := commons.DownloadJSON[[]json.RawMessage](url, parameters)
+	s.suppress(result)
+	if result.Suppressed {
+		signal.Reason = "suppressed"
+	} else if result.Position != nil {
+		signal.Reason = "positionOpen"
+	}
+	// Orders are still attempted for duplicates since client order IDs prevent them from being submitted twice
+	entryWindow := result.getEntryWindow()
+	if getState().hasSignal(s.Name, entryWindow, signal.Reason) {
+		result.Duplicate = true
+		result.addMessage("Signal for the entry window at %s UTC was already emitted", entryWindow.Format(time.TimeOnly))
+	} else if !isHistoricalEvaluation() {
+		appendEvent(signal)
+	}
+	if signal.Reason != "" {
+		return
+	}
+	costs := 2.0 * (configuration.Fees + getSlippage(s.Currency))
+	result.Costs = &costs
+	if s.BreakEven != nil {
+		trigger, stop := s.getBreakEven(price)
+		result.BreakEvenTrigger = &trigger
+		result.BreakEvenStop = &stop
+	}
+	if paperTrading {
+		s.openPaperPosition(result)
+	}
+	if executeOrders && s.Order != nil {
+		err := s.execute(result)
+		if err != nil {
+			result.addMessage("Order failed: %v", err)
+		}
+	}
+}
+
+func (s *Strategy) suppress(result *EvaluationResult) {
+	if executeOrders && suspensionReason != "" {
+		result.SuppressedBy = suppressedByRiskGuard
+		result.addMessage("Trading suspended: %s", suspensionReason)
+	} else if paperTrading && s.isMuted(paper.getDailyPnL(s.Name), time.Now().UTC()) {
+		result.SuppressedBy = suppressedByLossLimit
+	} else if executeOrders && !paperTrading && s.isMuted(s.getLiveDailyPnL(), time.Now().UTC()) {
+		result.SuppressedBy = suppressedByLossLimit
+	} else if !result.LiquidityMatch {
+		result.SuppressedBy = suppressedByLiquidity
+	} else if !result.FundingMatch {
+		result.SuppressedBy = suppressedByFunding
+	} else if !result.OpenInterestMatch {
+		result.SuppressedBy = suppressedByOpenInterest
+	}
+	result.Suppressed = result.SuppressedBy != ""
+}
+
+func loadRecords(currency string) ([]ohlcRecord, error) {
+	return loadRecentRecords(currency, cacheInterval, candleLimit)
+}
+
+// Strategies that look back further than a single request covers are loaded in multiple pages
+func (s *Strategy) loadRecords(currency string) ([]ohlcRecord, error) {
+	return loadRecentRecords(currency, cacheInterval, max(candleLimit, s.getWarmUpCandles() + 1))
+}
+
+// Pages are requested backwards from the end time until the requested number of candles is available or the history of the symbol runs out
+func loadRecentRecords(currency string, interval string, count int) ([]ohlcRecord, error) {
+	records := []ohlcRecord{}
+	endTime := getCandleEndTime()
+	for len(records) < count {
+		limit := min(count - len(records), candleLimit)
+		parameters := map[string]string{
+			"symbol": currency,
+			"interval": interval,
+			"limit": strconv.Itoa(limit),
+			"endTime": endTime,
+		}
+		page, err := downloadRecords(parameters)
+		if err != nil {
+			return nil, err
+		}
+		records = append(page, records...)
+		if len(page) < limit {
+			break
+		}
+		endTime = commons.Int64ToString(page[0].timestamp.UnixMilli() - 1)
+	}
+	return records, nil
+}
+
+func downloadRecords(parameters map[string]string) ([]ohlcRecord, error) {
+	start := time.Now()
+	data, err := commons.DownloadJSON[[]json.RawMessage](klinesURL, parameters)
+	recordFetch(providerBinance, time.Since(start), err)
 	if err != nil {
-		commons.Fatalf("Failed to download data from Binance: %v", err)
+		return nil, fmt.Errorf("failed to download data from Binance: %v", err)
 	}
 	records := []ohlcRecord{}
 	for _, recordData := range data {
 		fields := []json.RawMessage{}
 		err := json.Unmarshal(recordData, &fields)
 		if err != nil {
-			commons.Fatalf("Failed to unmarshal fields")
+			return nil, fmt.Errorf("failed to unmarshal fields")
+		}
+		if len(fields) < 6 {
+			return nil, fmt.Errorf("unexpected number of fields in candle: %d", len(fields))
 		}
 		var recordUnixMilliseconds int64
 		err = json.Unmarshal(fields[0], &recordUnixMilliseconds)
 		if err != nil {
-			commons.Fatalf("Failed to unmarshal UNIX timestamp")
+			return nil, fmt.Errorf("failed to unmarshal UNIX timestamp")
 		}
 		timestamp := time.UnixMilli(recordUnixMilliseconds).UTC()
-		unmarshalFloat := func (index int) float64 {
+		values := []float64{}
+		for i := 1; i <= 5; i++ {
 			var floatString string
-			err = json.Unmarshal(fields[index], &floatString)
+			err = json.Unmarshal(fields[i], &floatString)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal price or volume")
+			}
+			value, err := strconv.ParseFloat(floatString, 64)
 			if err != nil {
-				commons.Fatalf("Failed to unmarshal UNIX timestamp")
+				return nil, fmt.Errorf("invalid price or volume: %s", floatString)
 			}
-			value := commons.MustParseFloat(floatString)
-			return value
+			values = append(values, value)
 		}
-		open := unmarshalFloat(1)
-		high := unmarshalFloat(2)
-		low := unmarshalFloat(3)
-		close := unmarshalFloat(4)
 		record := ohlcRecord{
 			timestamp: timestamp,
-			open: open,
-			high: high,
-			low: low,
-			close: close,
+			open: values[0],
+			high: values[1],
+			low: values[2],
+			close: values[3],
+			volume: values[4],
 		}
 		records = append(records, record)
 	}
-	return records
+	return records, nil
 }
 
 func formatBool(value bool) string {
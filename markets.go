@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	marketOpen = "open"
+	marketClose = "close"
+)
+
+type marketHours struct {
+	timezone string
+	open time.Duration
+	close time.Duration
+}
+
+// Regular trading hours in the local time of each exchange
+var markets = map[string]marketHours{
+	"nyse": {"America/New_York", 9 * time.Hour + 30 * time.Minute, 16 * time.Hour},
+	"cme": {"America/Chicago", 8 * time.Hour + 30 * time.Minute, 15 * time.Hour},
+	"lse": {"Europe/London", 8 * time.Hour, 16 * time.Hour + 30 * time.Minute},
+	"xetra": {"Europe/Berlin", 9 * time.Hour, 17 * time.Hour + 30 * time.Minute},
+	"tse": {"Asia/Tokyo", 9 * time.Hour, 15 * time.Hour},
+	"hkex": {"Asia/Hong_Kong", 9 * time.Hour + 30 * time.Minute, 16 * time.Hour},
+}
+
+// Entry times relative to the open or close of an exchange follow its DST transitions
+type MarketTimeConfiguration struct {
+	Market string `yaml:"market"`
+	Event string `yaml:"event"`
+	OffsetMinutes int `yaml:"offsetMinutes"`
+	location *time.Location
+}
+
+func (s *Strategy) normalizeMarketTimes() {
+	for i := range s.MarketTimes {
+		c := &s.MarketTimes[i]
+		hours, exists := markets[c.Market]
+		if !exists {
+			commons.Fatalf("Unknown market in strategy %s: %s", s.Name, c.Market)
+		}
+		if c.Event != marketOpen && c.Event != marketClose {
+			commons.Fatalf("Invalid market event in strategy %s: %s", s.Name, c.Event)
+		}
+		if c.getTimeOfDay(hours) % candleInterval != 0 {
+			commons.Fatalf("Market time of strategy %s doesn't line up with the candles", s.Name)
+		}
+		location, err := time.LoadLocation(hours.timezone)
+		if err != nil {
+			commons.Fatalf("Failed to load timezone %s: %v", hours.timezone, err)
+		}
+		c.location = location
+	}
+}
+
+func (c *MarketTimeConfiguration) getTimeOfDay(hours marketHours) time.Duration {
+	timeOfDay := hours.open
+	if c.Event == marketClose {
+		timeOfDay = hours.close
+	}
+	return timeOfDay + time.Duration(c.OffsetMinutes) * time.Minute
+}
+
+// The exchange's local clock on the same calendar day as the entry day of the strategy
+func (c *MarketTimeConfiguration) getEntryTime(day time.Time) time.Time {
+	timeOfDay := c.getTimeOfDay(markets[c.Market])
+	midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	local := midnight.Add(timeOfDay)
+	return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), 0, 0, c.location).UTC()
+}
+
+func (c *MarketTimeConfiguration) String() string {
+	if c.OffsetMinutes == 0 {
+		return fmt.Sprintf("%s %s", c.Market, c.Event)
+	}
+	return fmt.Sprintf("%s %s %+dm", c.Market, c.Event, c.OffsetMinutes)
+}
\ No newline at end of file
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+const (
+	providerBinance = "Binance"
+	maxStaleness = 2 * candleInterval
+)
+
+type sourceMetrics struct {
+	requests int
+	failures int
+	totalLatency time.Duration
+	maxLatency time.Duration
+	staleness time.Duration
+	stalenessSymbol string
+}
+
+var (
+	sourceMetricsMutex sync.Mutex
+	dataSourceMetrics = map[string]*sourceMetrics{}
+)
+
+func getSourceMetrics(provider string) *sourceMetrics {
+	metrics, exists := dataSourceMetrics[provider]
+	if !exists {
+		metrics = &sourceMetrics{}
+		dataSourceMetrics[provider] = metrics
+	}
+	return metrics
+}
+
+func recordFetch(provider string, latency time.Duration, err error) {
+	sourceMetricsMutex.Lock()
+	defer sourceMetricsMutex.Unlock()
+	metrics := getSourceMetrics(provider)
+	metrics.requests++
+	if err != nil {
+		metrics.failures++
+	}
+	metrics.totalLatency += latency
+	metrics.maxLatency = max(metrics.maxLatency, latency)
+}
+
+// Staleness is the age of the most recent candle, the worst value across all symbols is kept
+func recordStaleness(provider string, symbol string, latest time.Time) {
+	sourceMetricsMutex.Lock()
+	defer sourceMetricsMutex.Unlock()
+	metrics := getSourceMetrics(provider)
+	staleness := getNow().Sub(latest)
+	if staleness > metrics.staleness {
+		metrics.staleness = staleness
+		metrics.stalenessSymbol = symbol
+	}
+}
+
+func printSourceMetrics() {
+	sourceMetricsMutex.Lock()
+	defer sourceMetricsMutex.Unlock()
+	if len(dataSourceMetrics) == 0 {
+		return
+	}
+	red := color.New(color.FgRed).SprintFunc()
+	providers := []string{}
+	for provider := range dataSourceMetrics {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	fmt.Fprintf(statusOutput, "Data sources:\n")
+	for _, provider := range providers {
+		metrics := dataSourceMetrics[provider]
+		meanLatency := metrics.totalLatency / time.Duration(max(metrics.requests, 1))
+		fmt.Fprintf(statusOutput, "\t%s:\n", provider)
+		fmt.Fprintf(statusOutput, "\t\tRequests: %d (%d failed)\n", metrics.requests, metrics.failures)
+		fmt.Fprintf(statusOutput, "\t\tLatency: %s mean, %s max\n", meanLatency.Round(time.Millisecond), metrics.maxLatency.Round(time.Millisecond))
+		if metrics.stalenessSymbol != "" {
+			stalenessString := fmt.Sprintf("%s (%s)", metrics.staleness.Round(time.Second), metrics.stalenessSymbol)
+			if metrics.staleness > maxStaleness {
+				stalenessString = red(stalenessString)
+			}
+			fmt.Fprintf(statusOutput, "\t\tStaleness: %s\n", stalenessString)
+		}
+	}
+	fmt.Fprintf(statusOutput, "\n")
+}
\ No newline at end of file
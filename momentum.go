@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/encratite/commons"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	momentumSimple = "simple"
+	momentumEWMA = "ewma"
+)
+
+// Momentum windows are specified either as a number of hours or as a duration like "90m", "36h" or "7d"
+type MomentumOffset struct {
+	time.Duration
+}
+
+type MomentumConfiguration struct {
+	Offset MomentumOffset `yaml:"offset"`
+	GreaterThan *float64 `yaml:"greaterThan"`
+	LessThan *float64 `yaml:"lessThan"`
+}
+
+type lookbackResult struct {
+	Offset string `json:"offset"`
+	Momentum *float64 `json:"momentum,omitempty"`
+	Match bool `json:"match"`
+}
+
+// Strategies that only specify a list of momentum constraints use the first one as their primary momentum
+func (s *Strategy) normalizeMomentum() {
+	if s.Offset.Duration != 0 || len(s.Momentum) == 0 {
+		return
+	}
+	primary := s.Momentum[0]
+	s.Offset = primary.Offset
+	s.GreaterThan = primary.GreaterThan
+	s.LessThan = primary.LessThan
+	s.Momentum = s.Momentum[1:]
+}
+
+func (o *MomentumOffset) UnmarshalYAML(value *yaml.Node) error {
+	hours, err := strconv.Atoi(value.Value)
+	if err == nil {
+		o.Duration = time.Duration(hours) * time.Hour
+		return nil
+	}
+	days, isDays := strings.CutSuffix(value.Value, "d")
+	if isDays {
+		count, err := strconv.Atoi(days)
+		if err != nil {
+			return fmt.Errorf("invalid momentum offset: %s", value.Value)
+		}
+		o.Duration = time.Duration(count) * 24 * time.Hour
+		return nil
+	}
+	duration, err := time.ParseDuration(value.Value)
+	if err != nil {
+		return fmt.Errorf("invalid momentum offset: %s", value.Value)
+	}
+	o.Duration = duration
+	return nil
+}
+
+func (o MomentumOffset) isValid() bool {
+	return o.Duration > 0 && o.Duration % candleInterval == 0
+}
+
+// Hours of candles required to cover the offset
+func (o MomentumOffset) getHours() int {
+	return int(math.Ceil(o.Hours()))
+}
+
+func (o MomentumOffset) String() string {
+	switch {
+	case o.Duration % (24 * time.Hour) == 0:
+		return fmt.Sprintf("%dd", int(o.Hours() / 24))
+	case o.Duration % time.Hour == 0:
+		return fmt.Sprintf("%dh", int(o.Hours()))
+	default:
+		return fmt.Sprintf("%dm", int(o.Minutes()))
+	}
+}
+
+func (c *MomentumConfiguration) validate(strategy string) {
+	if !c.Offset.isValid() {
+		commons.Fatalf("Invalid momentum offset for strategy %s", strategy)
+	}
+	if c.GreaterThan == nil && c.LessThan == nil {
+		commons.Fatalf("Missing momentum constraint for strategy %s", strategy)
+	}
+}
+
+func (c *MomentumConfiguration) matches(momentum float64) bool {
+	match := true
+	if c.GreaterThan != nil {
+		match = match && momentum > *c.GreaterThan
+	}
+	if c.LessThan != nil {
+		match = match && momentum < *c.LessThan
+	}
+	return match
+}
+
+func (s *Strategy) validateMomentumType() {
+	switch s.MomentumType {
+	case "", momentumSimple, momentumEWMA:
+	default:
+		commons.Fatalf("Unknown momentum type for strategy %s: %s", s.Name, s.MomentumType)
+	}
+	if s.MomentumHalfLife != nil && *s.MomentumHalfLife <= 0 {
+		commons.Fatalf("Invalid momentum half-life for strategy %s", s.Name)
+	}
+}
+
+// The half-life of the weights in hours defaults to half of the momentum window
+func (s *Strategy) getMomentumHalfLife() float64 {
+	if s.MomentumHalfLife != nil {
+		return *s.MomentumHalfLife
+	}
+	return s.Offset.Hours() / 2.0
+}
+
+// The EWMA momentum is the exponentially weighted mean of the candle returns in the window scaled to its length, so that it matches the simple momentum for evenly distributed returns
+func (s *Strategy) getMomentum(records []ohlcRecord, anchorIndex int, latestIndex int) float64 {
+	if s.MomentumType != momentumEWMA {
+		return (records[latestIndex].close / records[anchorIndex].open - 1.0) * percent
+	}
+	decay := math.Pow(0.5, 1.0 / (s.getMomentumHalfLife() * float64(candlesPerHour)))
+	weight := 1.0
+	weightedSum := 0.0
+	totalWeight := 0.0
+	for i := latestIndex; i >= anchorIndex; i-- {
+		previous := records[anchorIndex].open
+		if i > anchorIndex {
+			previous = records[i - 1].close
+		}
+		weightedSum += weight * (records[i].close / previous - 1.0)
+		totalWeight += weight
+		weight *= decay
+	}
+	candles := float64(latestIndex - anchorIndex + 1)
+	return weightedSum / totalWeight * candles * percent
+}
+
+func getAnchoredMomentum(records []ohlcRecord, latestIndex int, entryTime time.Time, offset time.Duration) (float64, bool) {
+	anchorIndex := findRecord(records, entryTime.Add(-offset))
+	if anchorIndex >= latestIndex {
+		return 0, false
+	}
+	return (records[latestIndex].close / records[anchorIndex].open - 1.0) * percent, true
+}
+
+// The additional lookbacks are anchored to the entry window in the same way as the consensus horizons
+func (s *Strategy) getLookbackMatch(records []ohlcRecord, latestIndex int, entryTime time.Time, result *EvaluationResult) bool {
+	match := true
+	for _, lookback := range s.Momentum {
+		lookbackMatch := false
+		output := lookbackResult{
+			Offset: lookback.Offset.String(),
+		}
+		momentum, ok := getAnchoredMomentum(records, latestIndex, entryTime, lookback.Offset.Duration)
+		if ok {
+			lookbackMatch = lookback.matches(momentum)
+			output.Momentum = &momentum
+		}
+		output.Match = lookbackMatch
+		match = match && lookbackMatch
+		if result != nil {
+			result.Lookbacks = append(result.Lookbacks, output)
+		}
+	}
+	return match
+}
\ No newline at end of file
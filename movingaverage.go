@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	movingAverageSMA = "sma"
+	movingAverageEMA = "ema"
+	movingAverageAbove = "above"
+	movingAverageBelow = "below"
+)
+
+type MovingAverageConfiguration struct {
+	Type string `yaml:"type"`
+	Fast int `yaml:"fast"`
+	Slow int `yaml:"slow"`
+	Direction string `yaml:"direction"`
+	IntervalMinutes int `yaml:"intervalMinutes"`
+}
+
+type movingAverageResult struct {
+	Name string `json:"name"`
+	Fast float64 `json:"fast"`
+	Slow float64 `json:"slow"`
+	Match bool `json:"match"`
+}
+
+func (c *MovingAverageConfiguration) validate(strategy string) {
+	if c.Type != movingAverageSMA && c.Type != movingAverageEMA {
+		commons.Fatalf("Unknown moving average type for strategy %s: %s", strategy, c.Type)
+	}
+	if c.Fast <= 0 || c.Slow <= c.Fast {
+		commons.Fatalf("Invalid moving average periods for strategy %s", strategy)
+	}
+	if c.Direction != "" && c.Direction != movingAverageAbove && c.Direction != movingAverageBelow {
+		commons.Fatalf("Unknown moving average direction for strategy %s: %s", strategy, c.Direction)
+	}
+	validateIndicatorInterval(c.IntervalMinutes, "moving average", strategy)
+}
+
+func (c *MovingAverageConfiguration) getInterval() time.Duration {
+	return getIndicatorInterval(c.IntervalMinutes)
+}
+
+// The EMA is seeded with the SMA of the first half of a window of twice its period so that it doesn't depend on the amount of history available
+func (c *MovingAverageConfiguration) getBarCount() int {
+	if c.Type == movingAverageEMA {
+		return 2 * c.Slow
+	}
+	return c.Slow
+}
+
+func (c *MovingAverageConfiguration) getLookback() time.Duration {
+	return time.Duration(c.getBarCount() + 1) * c.getInterval()
+}
+
+func (c *MovingAverageConfiguration) getName() string {
+	name := strings.ToUpper(c.Type)
+	return fmt.Sprintf("%s%d/%s%d (%dm)", name, c.Fast, name, c.Slow, int(c.getInterval().Minutes()))
+}
+
+// Without an explicit direction the fast average has to be on the side of the slow one that the strategy is betting on
+func (s *Strategy) getMovingAverageMatch(c *MovingAverageConfiguration, fast float64, slow float64) bool {
+	above := s.Up
+	if c.Direction != "" {
+		above = c.Direction == movingAverageAbove
+	}
+	if above {
+		return fast > slow
+	} else {
+		return fast < slow
+	}
+}
+
+func (c *MovingAverageConfiguration) getAverages(records []ohlcRecord) (float64, float64, bool) {
+	bars := getBars(records, c.getInterval())
+	if len(bars) < c.getBarCount() {
+		return 0, 0, false
+	}
+	closes := []float64{}
+	for _, bar := range bars[len(bars) - c.getBarCount():] {
+		closes = append(closes, bar.close)
+	}
+	var average func ([]float64, int) float64
+	if c.Type == movingAverageEMA {
+		average = getEMA
+	} else {
+		average = getSMA
+	}
+	return average(closes, c.Fast), average(closes, c.Slow), true
+}
+
+func getSMA(values []float64, period int) float64 {
+	total := 0.0
+	for _, value := range values[len(values) - period:] {
+		total += value
+	}
+	return total / float64(period)
+}
+
+func getEMA(values []float64, period int) float64 {
+	series := getEMASeries(values[len(values) - 2 * period:], period)
+	return series[len(series) - 1]
+}
+
+// The first element of the series corresponds to the value at the index of the period minus one, which is seeded with the SMA
+func getEMASeries(values []float64, period int) []float64 {
+	ema := getSMA(values[:period], period)
+	series := []float64{ema}
+	alpha := 2.0 / float64(period + 1)
+	for _, value := range values[period:] {
+		ema = alpha * value + (1.0 - alpha) * ema
+		series = append(series, ema)
+	}
+	return series
+}
\ No newline at end of file
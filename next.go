@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+// Strategies whose entry times don't occur within a year are reported as never triggering
+const nextTriggerDays = 366
+
+func printNextTriggers(filter string) {
+	now := getNow()
+	fmt.Printf("\n")
+	for i := range configuration.Strategies {
+		strategy := &configuration.Strategies[i]
+		if !strategy.matchesFilter(filter) {
+			continue
+		}
+		strategy.printNextTrigger(now)
+	}
+}
+
+func (s *Strategy) getNextEntry(now time.Time) (time.Time, bool) {
+	for _, day := range s.getLocalDays(now.AddDate(0, 0, -1), now.AddDate(0, 0, nextTriggerDays)) {
+		for _, entryTime := range s.getEntryTimes(day) {
+			if entryTime.After(now) {
+				return entryTime, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func (s *Strategy) printNextTrigger(now time.Time) {
+	fmt.Printf("%s (%s):\n", s.Name, s.Currency)
+	entryTime, exists := s.getNextEntry(now)
+	if !exists {
+		fmt.Printf("\tNo entry within the next %d days\n\n", nextTriggerDays)
+		return
+	}
+	local := entryTime.In(s.getLocation())
+	fmt.Printf("\tNext entry: %s UTC (%s %s) in %s\n", commons.GetTimeString(entryTime), local.Weekday(), local.Format("15:04 MST"), entryTime.Sub(now).Truncate(time.Minute))
+	fmt.Printf("\tEvaluation window opens: %s UTC\n", commons.GetTimeString(entryTime.Add(-s.getTolerance())))
+	anchorTime := s.getAnchorTime(entryTime)
+	fmt.Printf("\tMomentum anchor: %s UTC (%s)\n", commons.GetTimeString(anchorTime), s.Offset)
+	// Once the anchor candle exists the thresholds translate into prices that have to hold at the entry
+	anchorPrice := 0.0
+	if !anchorTime.After(now) {
+		records, err := s.loadRecords(s.Currency)
+		if err == nil {
+			records, _ = filterRecords(records)
+			anchorIndex := findRecord(records, anchorTime)
+			if anchorIndex < len(records) {
+				anchorPrice = records[anchorIndex].open
+			}
+		}
+	}
+	printThreshold := func (label string, threshold *float64) {
+		if threshold == nil {
+			return
+		}
+		if anchorPrice > 0 && s.MomentumType != momentumEWMA {
+			fmt.Printf("\tMomentum %s %+.2f%% (price %s %.4f)\n", label, *threshold, label, anchorPrice * (1.0 + *threshold / percent))
+		} else {
+			fmt.Printf("\tMomentum %s %+.2f%%\n", label, *threshold)
+		}
+	}
+	printThreshold("above", s.GreaterThan)
+	printThreshold("below", s.LessThan)
+	fmt.Printf("\n")
+}
\ No newline at end of file
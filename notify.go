@@ -0,0 +1,364 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+type NotificationConfiguration struct {
+	NearMisses bool `yaml:"nearMisses"`
+	NearMissTolerance *float64 `yaml:"nearMissTolerance"`
+	Default []string `yaml:"default"`
+	Desktop bool `yaml:"desktop"`
+	Telegram *TelegramConfiguration `yaml:"telegram"`
+	Slack *SlackConfiguration `yaml:"slack"`
+	Email *EmailConfiguration `yaml:"email"`
+	Webhook *WebhookConfiguration `yaml:"webhook"`
+	SMS *SMSConfiguration `yaml:"sms"`
+}
+
+type notification struct {
+	Result EvaluationResult
+	NearMiss bool
+	Distance float64
+	Exit *exitNotification
+	Stop *stopNotification
+}
+
+type exitNotification struct {
+	EntryTime time.Time `json:"entryTime"`
+	EntryPrice float64 `json:"entryPrice"`
+	Price float64 `json:"price"`
+	Reason string `json:"reason"`
+	Returns float64 `json:"returns"`
+}
+
+type stopNotification struct {
+	Previous float64 `json:"previous,omitempty"`
+	Price float64 `json:"price"`
+	Reason string `json:"reason"`
+}
+
+type templateData struct {
+	EvaluationResult
+	Type string
+	Title string
+	NearMiss bool
+	Distance float64
+	Exit *exitNotification
+	Stop *stopNotification
+}
+
+type notifier interface {
+	getName() string
+	notifiesErrors() bool
+	send(n notification) error
+}
+
+var nearMissWindows = map[string]time.Time{}
+
+func (c *NotificationConfiguration) validate() {
+	if c.NearMissTolerance != nil && *c.NearMissTolerance <= 0 {
+		commons.Fatalf("Invalid near miss tolerance")
+	}
+	if c.Telegram != nil {
+		c.Telegram.validate()
+	}
+	if c.Slack != nil {
+		c.Slack.validate()
+	}
+	if c.Email != nil {
+		c.Email.validate()
+	}
+	if c.Webhook != nil {
+		c.Webhook.validate()
+	}
+	if c.SMS != nil {
+		c.SMS.validate()
+	}
+	c.validateChannels("the default routing", c.Default)
+}
+
+func (c *NotificationConfiguration) validateChannels(owner string, channels []string) {
+	for _, channel := range channels {
+		found := false
+		for _, notifier := range c.getNotifiers() {
+			if getChannelName(notifier) == channel {
+				found = true
+			}
+		}
+		if !found {
+			commons.Fatalf("Unknown or unconfigured notification channel in %s: %s", owner, channel)
+		}
+	}
+}
+
+func getChannelName(n notifier) string {
+	return strings.ToLower(n.getName())
+}
+
+// Strategies without channels of their own use the default routing, which in turn defaults to all configured channels
+func (c *NotificationConfiguration) getChannels(strategy string) []string {
+	s := findStrategy(strategy)
+	if s != nil && len(s.Notify) > 0 {
+		return s.Notify
+	}
+	return c.Default
+}
+
+// Pointer fields such as the momentum have to be dereferenced with "value" before they can be formatted
+func parseTemplate(channel string, text string) *template.Template {
+	if text == "" {
+		return nil
+	}
+	functions := template.FuncMap{
+		"value": func (input any) any {
+			value := reflect.ValueOf(input)
+			if value.Kind() == reflect.Pointer {
+				if value.IsNil() {
+					return ""
+				}
+				return value.Elem().Interface()
+			}
+			return input
+		},
+		"percent": func (value float64) float64 {
+			return value * percent
+		},
+	}
+	t, err := template.New(channel).Funcs(functions).Parse(text)
+	if err != nil {
+		commons.Fatalf("Invalid %s notification template: %v", channel, err)
+	}
+	return t
+}
+
+// Falls back to the default text of the channel if no template was configured
+func (n *notification) format(t *template.Template, getDefault func () string) (string, error) {
+	if t == nil {
+		return getDefault(), nil
+	}
+	data := templateData{
+		EvaluationResult: n.Result,
+		Type: n.getType(),
+		Title: n.getTitle(),
+		NearMiss: n.NearMiss,
+		Distance: n.Distance,
+		Exit: n.Exit,
+		Stop: n.Stop,
+	}
+	var builder strings.Builder
+	err := t.Execute(&builder, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render template: %v", err)
+	}
+	return builder.String(), nil
+}
+
+func (c *NotificationConfiguration) getNotifiers() []notifier {
+	notifiers := []notifier{}
+	if c.Telegram != nil {
+		notifiers = append(notifiers, c.Telegram)
+	}
+	if c.Slack != nil {
+		notifiers = append(notifiers, c.Slack)
+	}
+	if c.Email != nil {
+		notifiers = append(notifiers, c.Email)
+	}
+	if c.Webhook != nil {
+		notifiers = append(notifiers, c.Webhook)
+	}
+	if c.SMS != nil {
+		notifiers = append(notifiers, c.SMS)
+	}
+	if c.Desktop {
+		notifiers = append(notifiers, desktopNotifier{})
+	}
+	return notifiers
+}
+
+// Returns by how many percentage points the momentum missed the thresholds
+func getMomentumDistance(result EvaluationResult) float64 {
+	momentum := *result.Momentum
+	distance := 0.0
+	if result.GreaterThan != nil && momentum <= *result.GreaterThan {
+		distance = math.Max(distance, *result.GreaterThan - momentum)
+	}
+	if result.LessThan != nil && momentum >= *result.LessThan {
+		distance = math.Max(distance, momentum - *result.LessThan)
+	}
+	return distance
+}
+
+// Near misses are evaluations in a matching entry window where only the momentum condition failed, optionally by no more than the tolerance
+func isNearMiss(result EvaluationResult) bool {
+	if result.Signal || !result.WeekdayMatch || !result.TimeMatch || result.Momentum == nil || result.MomentumMatch {
+		return false
+	}
+	tolerance := configuration.Notifications.NearMissTolerance
+	return tolerance == nil || getMomentumDistance(result) <= *tolerance
+}
+
+// Notification failures are reported but never interrupt the evaluation of strategies
+func sendNotifications(result EvaluationResult) {
+	if result.Duplicate {
+		return
+	}
+	n := notification{
+		Result: result,
+	}
+	if result.Error == "" && !result.Signal {
+		if !configuration.Notifications.NearMisses || !isNearMiss(result) {
+			return
+		}
+		// Near misses aren't recorded in the event log, a daemon only reports them once per entry window
+		entryWindow := result.getEntryWindow()
+		if nearMissWindows[result.Strategy].Equal(entryWindow) {
+			return
+		}
+		nearMissWindows[result.Strategy] = entryWindow
+		n.NearMiss = true
+		n.Distance = getMomentumDistance(result)
+	}
+	n.send()
+}
+
+func (s *Strategy) sendExitNotification(p *position, price float64, reason string) {
+	result := s.getEmptyResult()
+	n := notification{
+		Result: *result,
+		Exit: &exitNotification{
+			EntryTime: p.EntryTime,
+			EntryPrice: p.EntryPrice,
+			Price: price,
+			Reason: reason,
+			Returns: s.getReturns(p.EntryPrice, price),
+		},
+	}
+	n.send()
+}
+
+func (s *Strategy) sendStopNotification(previous float64, price float64, reason string) {
+	result := s.getEmptyResult()
+	n := notification{
+		Result: *result,
+		Stop: &stopNotification{
+			Previous: previous,
+			Price: price,
+			Reason: reason,
+		},
+	}
+	n.send()
+}
+
+func (n *notification) send() {
+	c := &configuration.Notifications
+	channels := c.getChannels(n.Result.Strategy)
+	for _, notifier := range c.getNotifiers() {
+		if n.Result.Error != "" && !notifier.notifiesErrors() {
+			continue
+		}
+		if len(channels) > 0 && !slices.Contains(channels, getChannelName(notifier)) {
+			continue
+		}
+		// Desktop notifications are only useful while the daemon is running in the background
+		_, desktop := notifier.(desktopNotifier)
+		if desktop && !daemonMode {
+			continue
+		}
+		err := notifier.send(*n)
+		if err != nil {
+			fmt.Fprintf(statusOutput, "%s: %s notification failed: %v\n", n.Result.Strategy, notifier.getName(), err)
+		}
+	}
+}
+
+func (n *notification) getTitle() string {
+	r := n.Result
+	kind := "Signal"
+	if n.Result.Error != "" {
+		kind = "Error"
+	} else if n.NearMiss {
+		kind = "Near miss"
+	} else if n.Exit != nil {
+		kind = "Exit"
+	} else if n.Stop != nil {
+		kind = "Stop moved"
+	}
+	return fmt.Sprintf("%s: %s %s (%s)", kind, r.Currency, r.getSide(), r.Strategy)
+}
+
+// Single line summary for channels where messages should stay short
+func (n *notification) getCompactText() string {
+	r := n.Result
+	if n.Result.Error != "" {
+		return fmt.Sprintf("%s: %s", n.getTitle(), n.Result.Error)
+	}
+	if n.Exit != nil {
+		return fmt.Sprintf("%s at %.4f (%s), returns %+.2f%%", n.getTitle(), n.Exit.Price, n.Exit.Reason, n.Exit.Returns * percent)
+	}
+	if n.Stop != nil {
+		return fmt.Sprintf("%s to %.4f (%s)", n.getTitle(), n.Stop.Price, n.Stop.Reason)
+	}
+	text := fmt.Sprintf("%s at %.4f", n.getTitle(), r.CurrentPrice)
+	if r.Momentum != nil {
+		text += fmt.Sprintf(", momentum %+.2f%%", *r.Momentum)
+	}
+	if n.NearMiss {
+		text += fmt.Sprintf(", missed by %.2f%%", n.Distance)
+	}
+	if r.Suppressed {
+		text += fmt.Sprintf(", suppressed by %s", r.SuppressedBy)
+	}
+	return text
+}
+
+func (n *notification) getText() string {
+	r := n.Result
+	var builder strings.Builder
+	builder.WriteString(n.getTitle())
+	if r.Error != "" {
+		fmt.Fprintf(&builder, "\n%s", r.Error)
+		return builder.String()
+	}
+	if n.Exit != nil {
+		fmt.Fprintf(&builder, "\nEntry: %.4f at %s UTC", n.Exit.EntryPrice, commons.GetTimeString(n.Exit.EntryTime))
+		fmt.Fprintf(&builder, "\nExit: %.4f (%s)", n.Exit.Price, n.Exit.Reason)
+		fmt.Fprintf(&builder, "\nReturns: %+.2f%%", n.Exit.Returns * percent)
+		return builder.String()
+	}
+	if n.Stop != nil {
+		fmt.Fprintf(&builder, "\nStop: %.4f to %.4f (%s)", n.Stop.Previous, n.Stop.Price, n.Stop.Reason)
+		return builder.String()
+	}
+	fmt.Fprintf(&builder, "\nPrice: %.4f", r.CurrentPrice)
+	if r.Momentum != nil {
+		fmt.Fprintf(&builder, "\nMomentum: %+.2f%% over %s", *r.Momentum, r.Offset)
+	}
+	if r.GreaterThan != nil {
+		fmt.Fprintf(&builder, "\nGreater than: %.2f%%", *r.GreaterThan)
+	}
+	if r.LessThan != nil {
+		fmt.Fprintf(&builder, "\nLess than: %.2f%%", *r.LessThan)
+	}
+	if n.NearMiss {
+		fmt.Fprintf(&builder, "\nMissed the threshold by %.2f%%", n.Distance)
+	}
+	if r.Suppressed {
+		fmt.Fprintf(&builder, "\nSuppressed by %s", r.SuppressedBy)
+	} else if r.Position != nil && !n.NearMiss {
+		builder.WriteString("\nPosition already open")
+	}
+	for _, message := range r.Messages {
+		fmt.Fprintf(&builder, "\n%s", message)
+	}
+	return builder.String()
+}
\ No newline at end of file
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	maxOpenInterestHours = 499
+)
+
+type OpenInterestConfiguration struct {
+	Hours int `yaml:"hours"`
+	GreaterThan *float64 `yaml:"greaterThan"`
+	LessThan *float64 `yaml:"lessThan"`
+}
+
+type binanceOpenInterest struct {
+	SumOpenInterest string `json:"sumOpenInterest"`
+}
+
+func (s *Strategy) validateOpenInterest() {
+	c := s.OpenInterest
+	s.requireFutures("Open interest condition")
+	if c.Hours <= 0 || c.Hours > maxOpenInterestHours {
+		commons.Fatalf("Invalid open interest period for strategy %s", s.Name)
+	}
+	if c.GreaterThan == nil && c.LessThan == nil {
+		commons.Fatalf("Missing open interest constraint for strategy %s", s.Name)
+	}
+}
+
+// Binance only provides the open interest history of the last 30 days so it can't be backtested
+func getOpenInterestChange(symbol string, hours int) (float64, error) {
+	url := fmt.Sprintf("%s/futures/data/openInterestHist", binanceFuturesURL)
+	parameters := map[string]string{
+		"symbol": symbol,
+		"period": "1h",
+		"limit": strconv.Itoa(hours + 1),
+	}
+	start := time.Now()
+	history, err := commons.DownloadJSON[[]binanceOpenInterest](url, parameters)
+	recordFetch(providerBinance, time.Since(start), err)
+	if err != nil {
+		return 0, fmt.Errorf("failed to download open interest history from Binance: %v", err)
+	}
+	if len(history) < hours + 1 {
+		return 0, fmt.Errorf("open interest history of %s only contains %d entries", symbol, len(history))
+	}
+	first, err := strconv.ParseFloat(history[0].SumOpenInterest, 64)
+	if err != nil || first <= 0 {
+		return 0, fmt.Errorf("invalid open interest: %s", history[0].SumOpenInterest)
+	}
+	last, err := strconv.ParseFloat(history[len(history) - 1].SumOpenInterest, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid open interest: %s", history[len(history) - 1].SumOpenInterest)
+	}
+	return (last / first - 1.0) * percent, nil
+}
+
+func (s *Strategy) checkOpenInterest(result *EvaluationResult) error {
+	result.OpenInterestMatch = true
+	if s.OpenInterest == nil {
+		return nil
+	}
+	if isHistoricalEvaluation() {
+		result.addMessage("The open interest is only available live and was not checked")
+		return nil
+	}
+	change, err := getOpenInterestChange(s.Currency, s.OpenInterest.Hours)
+	if err != nil {
+		return err
+	}
+	result.OpenInterestChange = &change
+	if s.OpenInterest.GreaterThan != nil && change <= *s.OpenInterest.GreaterThan {
+		result.OpenInterestMatch = false
+	}
+	if s.OpenInterest.LessThan != nil && change >= *s.OpenInterest.LessThan {
+		result.OpenInterestMatch = false
+	}
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	fitnessSharpe = "sharpe"
+	fitnessReturnDrawdown = "return-drawdown"
+	tournamentSize = 3
+	mutationScale = 0.1
+	minimumDrawdown = 0.01
+)
+
+type optimizerParameter struct {
+	name string
+	minimum float64
+	maximum float64
+	integer bool
+	get func (*Strategy) float64
+	set func (*Strategy, float64)
+}
+
+type optimizerIndividual struct {
+	genome []float64
+	fitness float64
+}
+
+type optimizer struct {
+	seed *Strategy
+	parameters []optimizerParameter
+	records []ohlcRecord
+	from time.Time
+	to time.Time
+	fitness string
+	minTrades int
+	mutationRate float64
+}
+
+func runOptimize(arguments []string) {
+	flags := flag.NewFlagSet("optimize", flag.ExitOnError)
+	strategyName := flags.String("strategy", "", "Name of the strategy to optimize")
+	fromString := flags.String("from", "", "Start date of the backtests (YYYY-MM-DD)")
+	toString := flags.String("to", "", "End date of the backtests (YYYY-MM-DD), defaults to today")
+	population := flags.Int("population", 50, "Number of individuals per generation")
+	generations := flags.Int("generations", 20, "Number of generations to evolve")
+	mutationRate := flags.Float64("mutation", 0.2, "Probability of each parameter being mutated in offspring")
+	fitness := flags.String("fitness", fitnessSharpe, "Fitness function, either \"sharpe\" or \"return-drawdown\"")
+	minTrades := flags.Int("minTrades", 20, "Minimum number of trades for a parameter set to be considered")
+	addBacktestFlags(flags)
+	flags.Parse(arguments)
+	from, to := parseDateRange(*fromString, *toString)
+	if *fitness != fitnessSharpe && *fitness != fitnessReturnDrawdown {
+		commons.Fatalf("Unknown fitness function: %s", *fitness)
+	}
+	if *population < 2 || *generations < 1 {
+		commons.Fatalf("Invalid population or generation count")
+	}
+	loadConfiguration()
+	var seed *Strategy
+	for i := range configuration.Strategies {
+		if configuration.Strategies[i].Name == *strategyName {
+			seed = &configuration.Strategies[i]
+		}
+	}
+	if seed == nil {
+		commons.Fatalf("Unknown strategy: %s", *strategyName)
+	}
+	if seed.HoldHours <= 0 {
+		commons.Fatalf("Missing holding period for strategy %s", seed.Name)
+	}
+	o := optimizer{
+		seed: seed,
+		parameters: getOptimizerParameters(seed),
+		from: from,
+		to: to,
+		fitness: *fitness,
+		minTrades: *minTrades,
+		mutationRate: *mutationRate,
+	}
+	o.loadRecords()
+	best := o.run(*population, *generations)
+	o.print(best)
+}
+
+func getOptimizerParameters(s *Strategy) []optimizerParameter {
+	parameters := []optimizerParameter{
+		{
+			name: "offset",
+			minimum: 1,
+			maximum: math.Max(2.0 * s.Offset.Hours(), 24),
+			integer: true,
+			get: func (s *Strategy) float64 {
+				return math.Round(s.Offset.Hours())
+			},
+			set: func (s *Strategy, value float64) {
+				s.Offset = MomentumOffset{time.Duration(value) * time.Hour}
+			},
+		},
+		{
+			name: "holdHours",
+			minimum: 1,
+			maximum: math.Max(2.0 * float64(s.HoldHours), 24),
+			integer: true,
+			get: func (s *Strategy) float64 {
+				return float64(s.HoldHours)
+			},
+			set: func (s *Strategy, value float64) {
+				s.HoldHours = int(value)
+			},
+		},
+	}
+	addThreshold := func (name string, field func (*Strategy) **float64) {
+		value := *field(s)
+		if value == nil {
+			return
+		}
+		width := math.Max(2.0 * math.Abs(*value), 2.0)
+		parameter := getPointerParameter(name, *value - width, *value + width, field)
+		parameters = append(parameters, parameter)
+	}
+	addPercentage := func (name string, field func (*Strategy) **float64) {
+		value := *field(s)
+		if value == nil {
+			return
+		}
+		parameter := getPointerParameter(name, 0.1, math.Max(3.0 * *value, 1.0), field)
+		parameters = append(parameters, parameter)
+	}
+	addThreshold("greaterThan", func (s *Strategy) **float64 { return &s.GreaterThan })
+	addThreshold("lessThan", func (s *Strategy) **float64 { return &s.LessThan })
+	addPercentage("stopLoss", func (s *Strategy) **float64 { return &s.StopLoss })
+	addPercentage("takeProfit", func (s *Strategy) **float64 { return &s.TakeProfit })
+	addPercentage("breakEven", func (s *Strategy) **float64 { return &s.BreakEven })
+	return parameters
+}
+
+func getPointerParameter(name string, minimum float64, maximum float64, field func (*Strategy) **float64) optimizerParameter {
+	return optimizerParameter{
+		name: name,
+		minimum: minimum,
+		maximum: maximum,
+		get: func (s *Strategy) float64 {
+			return **field(s)
+		},
+		set: func (s *Strategy, value float64) {
+			*field(s) = &value
+		},
+	}
+}
+
+func (o *optimizer) loadRecords() {
+	maxOffset := 0.0
+	maxHold := 0.0
+	for _, parameter := range o.parameters {
+		switch parameter.name {
+		case "offset":
+			maxOffset = parameter.maximum
+		case "holdHours":
+			maxHold = parameter.maximum
+		}
+	}
+	maxOffset = math.Max(maxOffset, float64(o.seed.getLookbackHours()))
+	from := o.from.Add(-time.Duration(maxOffset) * time.Hour)
+	to := o.to.Add(time.Duration(maxHold + 1) * time.Hour)
+	records := loadHistoricalRecords(o.seed.Currency, from, to)
+	o.seed.gaps = findGaps(records)
+	o.seed.loadHistoricalReferences(from, o.to.Add(time.Hour))
+	o.seed.loadHistoricalTimeframes(o.from, o.to.Add(time.Hour))
+	o.seed.loadHistoricalPercentile(o.from, o.to.Add(time.Hour))
+	o.records, _ = filterRecords(records)
+}
+
+func (o *optimizer) run(population int, generations int) optimizerIndividual {
+	seedGenome := []float64{}
+	for _, parameter := range o.parameters {
+		seedGenome = append(seedGenome, parameter.get(o.seed))
+	}
+	individuals := []optimizerIndividual{o.evaluate(seedGenome)}
+	for len(individuals) < population {
+		genome := o.mutate(seedGenome, 1.0)
+		individuals = append(individuals, o.evaluate(genome))
+	}
+	for generation := 1; generation <= generations; generation++ {
+		sort.Slice(individuals, func (i, j int) bool {
+			return individuals[i].fitness > individuals[j].fitness
+		})
+		fmt.Printf("Generation %d: best fitness %.4f\n", generation, individuals[0].fitness)
+		offspring := []optimizerIndividual{individuals[0]}
+		for len(offspring) < population {
+			a := o.tournament(individuals)
+			b := o.tournament(individuals)
+			genome := o.crossover(a.genome, b.genome)
+			genome = o.mutate(genome, o.mutationRate)
+			offspring = append(offspring, o.evaluate(genome))
+		}
+		individuals = offspring
+	}
+	sort.Slice(individuals, func (i, j int) bool {
+		return individuals[i].fitness > individuals[j].fitness
+	})
+	return individuals[0]
+}
+
+func (o *optimizer) getStrategy(genome []float64) Strategy {
+	strategy := *o.seed
+	for i, parameter := range o.parameters {
+		parameter.set(&strategy, genome[i])
+	}
+	return strategy
+}
+
+func (o *optimizer) evaluate(genome []float64) optimizerIndividual {
+	strategy := o.getStrategy(genome)
+	result := strategy.backtestRecords(o.records, o.from, o.to)
+	fitness := math.Inf(-1)
+	if len(result.trades) >= o.minTrades {
+		switch o.fitness {
+		case fitnessSharpe:
+			fitness = result.sharpeRatio()
+		case fitnessReturnDrawdown:
+			drawdown := math.Max(math.Abs(result.maxDrawdown()), minimumDrawdown)
+			fitness = result.totalReturn() / drawdown
+		}
+		if math.IsNaN(fitness) {
+			fitness = math.Inf(-1)
+		}
+	}
+	return optimizerIndividual{
+		genome: genome,
+		fitness: fitness,
+	}
+}
+
+func (o *optimizer) tournament(individuals []optimizerIndividual) optimizerIndividual {
+	best := individuals[rand.IntN(len(individuals))]
+	for i := 1; i < tournamentSize; i++ {
+		candidate := individuals[rand.IntN(len(individuals))]
+		if candidate.fitness > best.fitness {
+			best = candidate
+		}
+	}
+	return best
+}
+
+func (o *optimizer) crossover(a []float64, b []float64) []float64 {
+	genome := make([]float64, len(a))
+	for i := range genome {
+		if rand.IntN(2) == 0 {
+			genome[i] = a[i]
+		} else {
+			genome[i] = b[i]
+		}
+	}
+	return genome
+}
+
+func (o *optimizer) mutate(genome []float64, rate float64) []float64 {
+	output := make([]float64, len(genome))
+	for i, parameter := range o.parameters {
+		value := genome[i]
+		if rand.Float64() < rate {
+			value += rand.NormFloat64() * mutationScale * (parameter.maximum - parameter.minimum)
+		}
+		value = math.Min(math.Max(value, parameter.minimum), parameter.maximum)
+		if parameter.integer {
+			value = math.Round(value)
+		}
+		output[i] = value
+	}
+	return output
+}
+
+func (o *optimizer) print(best optimizerIndividual) {
+	fmt.Printf("\nBest parameters for %s (%s fitness %.4f):\n", o.seed.Name, o.fitness, best.fitness)
+	for i, parameter := range o.parameters {
+		original := parameter.get(o.seed)
+		if parameter.integer {
+			fmt.Printf("\t%s: %d (was %d)\n", parameter.name, int(best.genome[i]), int(original))
+		} else {
+			fmt.Printf("\t%s: %.2f (was %.2f)\n", parameter.name, best.genome[i], original)
+		}
+	}
+	strategy := o.getStrategy(best.genome)
+	result := strategy.backtestRecords(o.records, o.from, o.to)
+	fmt.Printf("\n")
+	result.print()
+}
\ No newline at end of file
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	clientOrderPrefix = "cg"
+	clientOrderHashLength = 30
+	binanceOrderNotFound = -2013
+)
+
+// Signals evaluated during an hour are entered at the start of the next one
+func getEntryWindow(t time.Time) time.Time {
+	return t.Truncate(time.Hour).Add(time.Hour)
+}
+
+// The same strategy and entry window always map to the same ID, so a restarted run can detect orders it already submitted
+func getClientOrderID(strategy string, purpose string, window time.Time) string {
+	input := fmt.Sprintf("%s|%s|%d", strategy, purpose, window.Unix())
+	hash := sha256.Sum256([]byte(input))
+	return clientOrderPrefix + hex.EncodeToString(hash[:])[:clientOrderHashLength]
+}
+
+// Returns nil without an error if the exchange has no order with the specified client order ID
+func (c *binanceClient) findOrder(symbol string, clientOrderID string) (*binanceOrderResponse, error) {
+	var response binanceOrderResponse
+	parameters := url.Values{}
+	parameters.Set("symbol", symbol)
+	parameters.Set("origClientOrderId", clientOrderID)
+	path := "/api/v3/order"
+	if c.futures {
+		path = "/fapi/v1/order"
+	}
+	err := c.signedRequest(http.MethodGet, path, parameters, &response)
+	var apiError *binanceError
+	if errors.As(err, &apiError) && apiError.Code == binanceOrderNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (s *engineState) hasClientOrder(clientOrderID string) bool {
+	for _, order := range s.orders {
+		if order.ClientOrderID == clientOrderID {
+			return true
+		}
+	}
+	for _, fill := range s.fills {
+		if fill.ClientOrderID == clientOrderID {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks both the local event log and the exchange since a crash may have occurred before the order event was written
+func (s *Strategy) isSubmitted(executor Executor, clientOrderID string) (bool, string, error) {
+	if getState().hasClientOrder(clientOrderID) {
+		return true, "recorded in the event log", nil
+	}
+	existing, err := executor.FindOrder(s.Currency, clientOrderID)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to check for existing order: %v", err)
+	}
+	if existing != nil {
+		return true, existing.Status, nil
+	}
+	return false, "", nil
+}
\ No newline at end of file
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/encratite/commons"
+	"github.com/fatih/color"
+)
+
+const (
+	paperFile = "paper.json"
+	defaultPaperBalance = 10000.0
+	defaultPaperPositionSize = 10.0
+)
+
+type PaperConfiguration struct {
+	Balance float64 `yaml:"balance"`
+	PositionSize float64 `yaml:"positionSize"`
+}
+
+type paperPosition struct {
+	Currency string `json:"currency"`
+	Up bool `json:"up"`
+	EntryTime time.Time `json:"entryTime"`
+	EntryPrice float64 `json:"entryPrice"`
+	Quantity float64 `json:"quantity"`
+}
+
+type paperTrade struct {
+	Strategy string `json:"strategy"`
+	Currency string `json:"currency"`
+	Up bool `json:"up"`
+	EntryTime time.Time `json:"entryTime"`
+	ExitTime time.Time `json:"exitTime"`
+	EntryPrice float64 `json:"entryPrice"`
+	ExitPrice float64 `json:"exitPrice"`
+	Quantity float64 `json:"quantity"`
+	Returns float64 `json:"returns"`
+	PnL float64 `json:"pnl"`
+	Reason string `json:"reason"`
+}
+
+type paperAccount struct {
+	Balance float64 `json:"balance"`
+	Positions map[string]paperPosition `json:"positions"`
+	Trades []paperTrade `json:"trades"`
+}
+
+var (
+	paperTrading bool
+	paper *paperAccount
+)
+
+func (c *PaperConfiguration) validate() {
+	if c.Balance < 0 {
+		commons.Fatalf("Invalid paper trading balance")
+	}
+	if c.PositionSize < 0 || c.PositionSize > percent {
+		commons.Fatalf("Invalid paper trading position size")
+	}
+}
+
+func loadPaperAccount() *paperAccount {
+	balance := configuration.Paper.Balance
+	if balance == 0 {
+		balance = defaultPaperBalance
+	}
+	account := &paperAccount{
+		Balance: balance,
+		Positions: map[string]paperPosition{},
+	}
+	readJSON(filepath.Join(dataDirectory, paperFile), account)
+	if account.Positions == nil {
+		account.Positions = map[string]paperPosition{}
+	}
+	return account
+}
+
+func (a *paperAccount) save() {
+	writeJSON(filepath.Join(dataDirectory, paperFile), a)
+}
+
+func (a *paperAccount) getDailyPnL(strategy string) dailyPnL {
+	pnl := newDailyPnL()
+	for _, trade := range a.Trades {
+		if trade.Strategy == strategy {
+			pnl.add(trade.ExitTime, trade.Returns)
+		}
+	}
+	return pnl
+}
+
+func (s *Strategy) getPaperQuantity(price float64) float64 {
+	if s.Order != nil && s.Order.Quantity != nil {
+		return *s.Order.Quantity
+	}
+	if s.Order != nil && s.Order.QuoteQuantity != nil {
+		return *s.Order.QuoteQuantity / price
+	}
+	positionSize := configuration.Paper.PositionSize
+	if s.Order != nil && s.Order.BalancePercentage != nil {
+		positionSize = *s.Order.BalancePercentage
+	}
+	if positionSize == 0 {
+		positionSize = defaultPaperPositionSize
+	}
+	return paper.Balance * positionSize / percent / price
+}
+
+func (s *Strategy) openPaperPosition(result *EvaluationResult) {
+	_, exists := paper.Positions[s.Name]
+	if exists {
+		result.addMessage("Paper position already open")
+		return
+	}
+	fillPrice := s.getSlippageFill(result.CurrentPrice, true)
+	quantity := s.getPaperQuantity(fillPrice)
+	position := paperPosition{
+		Currency: s.Currency,
+		Up: s.Up,
+		EntryTime: time.Now().UTC(),
+		EntryPrice: fillPrice,
+		Quantity: quantity,
+	}
+	paper.Positions[s.Name] = position
+	result.addMessage("Opened paper position: %s at %.4f", formatDecimal(quantity), fillPrice)
+}
+
+// Exits are checked against all candles since the entry so that stops hit between runs aren't missed
+func (s *Strategy) updatePaperPosition() error {
+	position, exists := paper.Positions[s.Name]
+	if !exists {
+		return nil
+	}
+	records, err := loadRecords(s.Currency)
+	if err != nil {
+		return err
+	}
+	records, _ = filterRecords(records)
+	if len(records) == 0 {
+		return fmt.Errorf("no candles available for %s", s.Currency)
+	}
+	stopPrice := s.getPaperStop(position)
+	takeProfitPrice := -1.0
+	if s.TakeProfit != nil {
+		takeProfitPrice = s.getExitPrice(position.EntryPrice, *s.TakeProfit)
+	}
+	exitTime := time.Time{}
+	if s.HoldHours > 0 {
+		exitTime = position.EntryTime.Add(time.Duration(s.HoldHours) * time.Hour)
+	}
+	for _, record := range records {
+		if record.timestamp.Before(position.EntryTime.Truncate(candleInterval)) {
+			continue
+		}
+		if stopPrice > 0 && s.hitStop(record, stopPrice) {
+			s.closePaperPosition(position, record.timestamp, s.getStopFill(record, stopPrice), exitStopLoss)
+			return nil
+		}
+		if takeProfitPrice > 0 && s.hitTakeProfit(record, takeProfitPrice) {
+			s.closePaperPosition(position, record.timestamp, takeProfitPrice, exitTakeProfit)
+			return nil
+		}
+	}
+	now := time.Now().UTC()
+	if !exitTime.IsZero() && !now.Before(exitTime) {
+		latest := records[len(records) - 1]
+		s.closePaperPosition(position, now, latest.close, exitHold)
+	}
+	return nil
+}
+
+func (s *Strategy) getPaperStop(position paperPosition) float64 {
+	if s.StopLoss == nil {
+		return -1.0
+	}
+	return s.getExitPrice(position.EntryPrice, -*s.StopLoss)
+}
+
+func (s *Strategy) closePaperPosition(position paperPosition, exitTime time.Time, price float64, reason string) {
+	exitPrice := price
+	if reason != exitTakeProfit {
+		exitPrice = s.getSlippageFill(price, false)
+	}
+	returns := s.getReturns(position.EntryPrice, exitPrice)
+	pnl := returns * position.EntryPrice * position.Quantity
+	trade := paperTrade{
+		Strategy: s.Name,
+		Currency: s.Currency,
+		Up: s.Up,
+		EntryTime: position.EntryTime,
+		ExitTime: exitTime,
+		EntryPrice: position.EntryPrice,
+		ExitPrice: exitPrice,
+		Quantity: position.Quantity,
+		Returns: returns,
+		PnL: pnl,
+		Reason: reason,
+	}
+	paper.Balance += pnl
+	paper.Trades = append(paper.Trades, trade)
+	delete(paper.Positions, s.Name)
+	fmt.Fprintf(statusOutput, "%s: closed paper position at %.4f (%s), P&L %+.2f\n", s.Name, exitPrice, reason, pnl)
+}
+
+func updatePaperAccount(filter string) {
+	for i := range configuration.Strategies {
+		strategy := &configuration.Strategies[i]
+		if !strategy.matchesFilter(filter) {
+			continue
+		}
+		err := strategy.updatePaperPosition()
+		if err != nil {
+			fmt.Fprintf(statusOutput, "%s: failed to update paper position: %v\n", strategy.Name, err)
+		}
+	}
+}
+
+func printPaperAccount() {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	formatPnL := func (pnl float64) string {
+		output := fmt.Sprintf("%+.2f", pnl)
+		if pnl >= 0 {
+			return green(output)
+		} else {
+			return red(output)
+		}
+	}
+	realized := 0.0
+	for _, trade := range paper.Trades {
+		realized += trade.PnL
+	}
+	fmt.Fprintf(statusOutput, "Paper account:\n")
+	fmt.Fprintf(statusOutput, "\tBalance: %.2f\n", paper.Balance)
+	fmt.Fprintf(statusOutput, "\tRealized P&L: %s (%d trades)\n", formatPnL(realized), len(paper.Trades))
+	strategies := []string{}
+	for strategy := range paper.Positions {
+		strategies = append(strategies, strategy)
+	}
+	sort.Strings(strategies)
+	for _, strategy := range strategies {
+		position := paper.Positions[strategy]
+		fmt.Fprintf(statusOutput, "\t%s: %s %s at %.4f since %s UTC\n", strategy, position.Currency, formatDecimal(position.Quantity), position.EntryPrice, commons.GetTimeString(position.EntryTime))
+	}
+	fmt.Fprintf(statusOutput, "\n")
+}
\ No newline at end of file
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	patternEngulfing = "engulfing"
+	patternHammer = "hammer"
+	patternDoji = "doji"
+	patternInsideBar = "insideBar"
+	dojiBodyRatio = 0.1
+	hammerShadowRatio = 2.0
+)
+
+type PatternConfiguration struct {
+	Patterns []string `yaml:"patterns"`
+	Candles int `yaml:"candles"`
+	IntervalMinutes int `yaml:"intervalMinutes"`
+}
+
+func (c *PatternConfiguration) validate(strategy string) {
+	if len(c.Patterns) == 0 {
+		commons.Fatalf("Missing candle patterns for strategy %s", strategy)
+	}
+	for _, pattern := range c.Patterns {
+		if !slices.Contains([]string{patternEngulfing, patternHammer, patternDoji, patternInsideBar}, pattern) {
+			commons.Fatalf("Unknown candle pattern for strategy %s: %s", strategy, pattern)
+		}
+	}
+	if c.Candles < 0 {
+		commons.Fatalf("Invalid number of pattern candles for strategy %s", strategy)
+	}
+	validateIndicatorInterval(c.IntervalMinutes, "candle pattern", strategy)
+}
+
+func (c *PatternConfiguration) getCandles() int {
+	if c.Candles == 0 {
+		return 1
+	}
+	return c.Candles
+}
+
+func (c *PatternConfiguration) getLookback() time.Duration {
+	return time.Duration(c.getCandles() + 2) * getIndicatorInterval(c.IntervalMinutes)
+}
+
+// Returns the first of the patterns found in the most recent closed bars, a bar that is still being formed by the latest candle is skipped
+func (c *PatternConfiguration) findPattern(records []ohlcRecord, up bool) (string, bool) {
+	interval := getIndicatorInterval(c.IntervalMinutes)
+	bars := getBars(records, interval)
+	latest := records[len(records) - 1]
+	if len(bars) > 0 && latest.timestamp.Add(candleInterval).Before(bars[len(bars) - 1].timestamp.Add(interval)) {
+		bars = bars[:len(bars) - 1]
+	}
+	if len(bars) < c.getCandles() + 1 {
+		return "", false
+	}
+	for i := len(bars) - c.getCandles(); i < len(bars); i++ {
+		for _, pattern := range c.Patterns {
+			if matchesPattern(pattern, bars[i - 1], bars[i], up) {
+				return pattern, true
+			}
+		}
+	}
+	return "", true
+}
+
+// Engulfing bars and hammers are directional and have to point in the direction of the strategy
+func matchesPattern(pattern string, previous ohlcRecord, bar ohlcRecord, up bool) bool {
+	body := math.Abs(bar.close - bar.open)
+	barRange := bar.high - bar.low
+	upperShadow := bar.high - math.Max(bar.open, bar.close)
+	lowerShadow := math.Min(bar.open, bar.close) - bar.low
+	switch pattern {
+	case patternEngulfing:
+		if up {
+			return previous.close < previous.open && bar.close > bar.open && bar.open <= previous.close && bar.close >= previous.open
+		} else {
+			return previous.close > previous.open && bar.close < bar.open && bar.open >= previous.close && bar.close <= previous.open
+		}
+	case patternHammer:
+		if !up {
+			upperShadow, lowerShadow = lowerShadow, upperShadow
+		}
+		return barRange > 0 && lowerShadow >= hammerShadowRatio * body && upperShadow <= body
+	case patternDoji:
+		return barRange > 0 && body <= dojiBodyRatio * barRange
+	case patternInsideBar:
+		return bar.high < previous.high && bar.low > previous.low
+	}
+	return false
+}
\ No newline at end of file
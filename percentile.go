@@ -0,0 +1,143 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	percentileInterval = "1h"
+	// Candles of the hour in progress, which isn't part of the hourly series yet
+	percentileWindow = time.Hour
+)
+
+type PercentileConfiguration struct {
+	Offset int `yaml:"offset"`
+	Hours int `yaml:"hours"`
+	GreaterThan *float64 `yaml:"greaterThan"`
+	LessThan *float64 `yaml:"lessThan"`
+}
+
+var (
+	percentileRecords = map[string]referenceSeries{}
+	percentileLock sync.Mutex
+)
+
+func (c *PercentileConfiguration) validate(strategy string) {
+	if c.Offset <= 0 {
+		commons.Fatalf("Invalid percentile rank offset for strategy %s", strategy)
+	}
+	if c.Hours < 2 {
+		commons.Fatalf("Invalid percentile rank period for strategy %s", strategy)
+	}
+	if c.Hours + c.Offset >= maxCandlePages * candleLimit {
+		commons.Fatalf("Percentile rank period of strategy %s exceeds the %d hours available", strategy, maxCandlePages * candleLimit - 1)
+	}
+	if c.GreaterThan == nil && c.LessThan == nil {
+		commons.Fatalf("Missing percentile rank constraint for strategy %s", strategy)
+	}
+	for _, threshold := range []*float64{c.GreaterThan, c.LessThan} {
+		if threshold != nil && (*threshold < 0 || *threshold > percent) {
+			commons.Fatalf("Invalid percentile rank threshold for strategy %s", strategy)
+		}
+	}
+}
+
+func (c *PercentileConfiguration) getLookback() time.Duration {
+	return time.Duration(c.Hours + c.Offset) * time.Hour
+}
+
+func (s *Strategy) getPercentileLookback() time.Duration {
+	lookback := time.Duration(0)
+	if s.Percentile != nil {
+		lookback = s.Percentile.getLookback()
+	}
+	if s.Conditions != nil {
+		lookback = max(lookback, s.Conditions.getPercentileLookback())
+	}
+	return lookback
+}
+
+// Trailing periods of several months exceed the 5m candles of an evaluation, so they are covered by a separate hourly series
+func (s *Strategy) loadLivePercentile() error {
+	lookback := s.getPercentileLookback()
+	if lookback == 0 {
+		return nil
+	}
+	records, err := loadRecentRecords(s.Currency, percentileInterval, int(lookback / time.Hour) + 1)
+	if err != nil {
+		return err
+	}
+	percentileLock.Lock()
+	percentileRecords[s.Currency] = referenceSeries{
+		records: records,
+	}
+	percentileLock.Unlock()
+	return nil
+}
+
+func (s *Strategy) loadHistoricalPercentile(from time.Time, to time.Time) {
+	lookback := s.getPercentileLookback()
+	if lookback == 0 {
+		return
+	}
+	seriesFrom := from.Add(-lookback - time.Hour)
+	percentileLock.Lock()
+	series, exists := percentileRecords[s.Currency]
+	percentileLock.Unlock()
+	if exists && !series.from.IsZero() && !series.from.After(seriesFrom) && !series.to.Before(to) {
+		return
+	}
+	records := loadHistoricalInterval(s.Currency, percentileInterval, seriesFrom, to)
+	percentileLock.Lock()
+	percentileRecords[s.Currency] = referenceSeries{
+		from: seriesFrom,
+		to: to,
+		records: records,
+	}
+	percentileLock.Unlock()
+}
+
+// Closed bars are taken from the hourly series while the bar of the latest candle is aggregated from the window so that it doesn't include later candles
+func getPercentileBars(currency string, window []ohlcRecord) []ohlcRecord {
+	current := getHourlyBars(window)
+	if len(current) == 0 {
+		return nil
+	}
+	latest := current[len(current) - 1]
+	percentileLock.Lock()
+	records := percentileRecords[currency].records
+	percentileLock.Unlock()
+	bars := records[:findRecord(records, latest.timestamp)]
+	return append(bars[:len(bars):len(bars)], latest)
+}
+
+// Percentage of the momentum values at the end of each hour of the trailing period that are below the latest one
+func (c *PercentileConfiguration) getPercentileRank(currency string, window []ohlcRecord) (float64, bool, bool) {
+	bars := getPercentileBars(currency, window)
+	if len(bars) < c.Hours + c.Offset - 1 {
+		return 0, false, false
+	}
+	values := []float64{}
+	for i := len(bars) - c.Hours; i < len(bars); i++ {
+		values = append(values, (bars[i].close / bars[i - c.Offset + 1].open - 1.0) * percent)
+	}
+	latest := values[len(values) - 1]
+	below := 0
+	for _, value := range values[:len(values) - 1] {
+		if value < latest {
+			below++
+		}
+	}
+	rank := float64(below) / float64(len(values) - 1) * percent
+	match := true
+	if c.GreaterThan != nil {
+		match = match && rank > *c.GreaterThan
+	}
+	if c.LessThan != nil {
+		match = match && rank < *c.LessThan
+	}
+	return rank, match, true
+}
\ No newline at end of file
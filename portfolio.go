@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+type portfolioEntry struct {
+	result *backtestResult
+	trade *backtestTrade
+}
+
+type portfolioPosition struct {
+	portfolioEntry
+	size float64
+}
+
+type equitySample struct {
+	timestamp time.Time
+	equity float64
+}
+
+type portfolioResult struct {
+	from time.Time
+	to time.Time
+	strategies int
+	trades int
+	skipped int
+	equity []equitySample
+}
+
+func (s *Strategy) getWeight() float64 {
+	if s.Weight == nil {
+		return 1.0
+	}
+	return *s.Weight
+}
+
+func (s *Strategy) getDirection() float64 {
+	if s.Up {
+		return 1.0
+	} else {
+		return -1.0
+	}
+}
+
+func backtestPortfolio(results []backtestResult, from time.Time, to time.Time) portfolioResult {
+	totalWeight := 0.0
+	entries := []portfolioEntry{}
+	for i := range results {
+		result := &results[i]
+		totalWeight += result.strategy.getWeight()
+		for j := range result.trades {
+			entry := portfolioEntry{
+				result: result,
+				trade: &result.trades[j],
+			}
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func (i, j int) bool {
+		return entries[i].trade.entryTime.Before(entries[j].trade.entryTime)
+	})
+	portfolio := portfolioResult{
+		from: from,
+		to: to,
+		strategies: len(results),
+	}
+	cash := 1.0
+	positions := []portfolioPosition{}
+	nextEntry := 0
+	for t := from; t.Before(to) || len(positions) > 0 || nextEntry < len(entries); t = t.Add(time.Hour) {
+		openPositions := []portfolioPosition{}
+		for _, position := range positions {
+			if !position.trade.exitTime.After(t) {
+				cash += position.size * position.trade.returns
+			} else {
+				openPositions = append(openPositions, position)
+			}
+		}
+		positions = openPositions
+		for nextEntry < len(entries) && !entries[nextEntry].trade.entryTime.After(t) {
+			entry := entries[nextEntry]
+			nextEntry++
+			strategy := entry.result.strategy
+			equity := getPortfolioEquity(positions, cash, t)
+			size := strategy.getWeight() / totalWeight * equity
+			size = math.Min(size, getAvailableCash(positions, strategy, equity))
+			if size <= 0 {
+				portfolio.skipped++
+				continue
+			}
+			position := portfolioPosition{
+				portfolioEntry: entry,
+				size: size,
+			}
+			positions = append(positions, position)
+			portfolio.trades++
+		}
+		sample := equitySample{
+			timestamp: t,
+			equity: getPortfolioEquity(positions, cash, t),
+		}
+		portfolio.equity = append(portfolio.equity, sample)
+	}
+	return portfolio
+}
+
+func getPortfolioEquity(positions []portfolioPosition, cash float64, timestamp time.Time) float64 {
+	equity := cash
+	for _, position := range positions {
+		records := position.result.records
+		index := findRecord(records, timestamp) - 1
+		if index < 0 {
+			continue
+		}
+		price := records[index].close
+		equity += position.size * position.result.strategy.getReturns(position.trade.entryPrice, price)
+	}
+	return equity
+}
+
+// Positions in the same currency are netted, so a trade against the current net exposure frees up cash
+func getAvailableCash(positions []portfolioPosition, strategy *Strategy, equity float64) float64 {
+	exposures := map[string]float64{}
+	for _, position := range positions {
+		positionStrategy := position.result.strategy
+		exposures[positionStrategy.Currency] += positionStrategy.getDirection() * position.size
+	}
+	exposure := 0.0
+	for _, netExposure := range exposures {
+		exposure += math.Abs(netExposure)
+	}
+	available := equity - exposure
+	netExposure := exposures[strategy.Currency]
+	if netExposure * strategy.getDirection() < 0 {
+		available += 2.0 * math.Abs(netExposure)
+	}
+	return available
+}
+
+func (p *portfolioResult) getEquityCurve() []float64 {
+	curve := []float64{}
+	for _, sample := range p.equity {
+		curve = append(curve, sample.equity)
+	}
+	return curve
+}
+
+func (p *portfolioResult) totalReturn() float64 {
+	if len(p.equity) == 0 {
+		return 0
+	}
+	return p.equity[len(p.equity) - 1].equity - 1.0
+}
+
+// Annualized from daily changes in equity
+func (p *portfolioResult) sharpeRatio() float64 {
+	returns := []float64{}
+	hoursPerDay := 24
+	for i := hoursPerDay; i < len(p.equity); i += hoursPerDay {
+		returns = append(returns, p.equity[i].equity / p.equity[i - hoursPerDay].equity - 1.0)
+	}
+	if len(returns) < 2 {
+		return math.NaN()
+	}
+	deviation := getStandardDeviation(returns)
+	if deviation == 0 {
+		return math.NaN()
+	}
+	return getMean(returns) / deviation * math.Sqrt(365.0)
+}
+
+func (p *portfolioResult) print() {
+	fmt.Printf("Portfolio:\n")
+	fmt.Printf("\tStrategies: %d\n", p.strategies)
+	fmt.Printf("\tPeriod: %s - %s\n", p.from.Format(time.DateOnly), p.to.Format(time.DateOnly))
+	fmt.Printf("\tTrades: %d\n", p.trades)
+	if p.skipped > 0 {
+		fmt.Printf("\tSkipped trades (insufficient cash): %d\n", p.skipped)
+	}
+	fmt.Printf("\tTotal return: %+.2f%%\n", p.totalReturn() * percent)
+	fmt.Printf("\tMax drawdown: %.2f%%\n", getMaxDrawdown(p.getEquityCurve()) * percent)
+	fmt.Printf("\tSharpe ratio: %.2f\n", p.sharpeRatio())
+	fmt.Printf("\n")
+}
\ No newline at end of file
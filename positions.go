@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/encratite/commons"
+)
+
+var currentState *engineState
+
+// The state is derived from the event log once per run and kept up to date as new events are appended
+func getState() *engineState {
+	if currentState == nil {
+		state := replayEvents(loadEvents(), evaluationTime)
+		currentState = &state
+	}
+	return currentState
+}
+
+func (s *Strategy) getPosition() *position {
+	p, exists := getState().positions[s.Name]
+	if !exists {
+		return nil
+	}
+	return p
+}
+
+func runPosition(arguments []string) {
+	flags := flag.NewFlagSet("position", flag.ExitOnError)
+	strategyName := flags.String("strategy", "", "Name of the strategy the position belongs to")
+	price := flags.Float64("price", 0, "Entry or exit price of the position")
+	quantity := flags.Float64("quantity", 0, "Size of the position in units of the base currency")
+	positional := parseArguments(flags, arguments)
+	if len(positional) != 1 {
+		commons.Fatalf("Usage: coinage position <open|close|list> [-strategy <name>] [-price <price>] [-quantity <quantity>]")
+	}
+	loadConfiguration()
+	action := positional[0]
+	if action == "list" {
+		fmt.Printf("\n")
+		getState().print()
+		return
+	}
+	var strategy *Strategy
+	for i := range configuration.Strategies {
+		if configuration.Strategies[i].Name == *strategyName {
+			strategy = &configuration.Strategies[i]
+		}
+	}
+	if strategy == nil {
+		commons.Fatalf("Unknown strategy: %s", *strategyName)
+	}
+	if *price <= 0 {
+		commons.Fatalf("Invalid price")
+	}
+	existing := strategy.getPosition()
+	switch action {
+	case "open":
+		if existing != nil {
+			commons.Fatalf("Strategy %s already holds a position", strategy.Name)
+		}
+		if *quantity <= 0 {
+			commons.Fatalf("Invalid quantity")
+		}
+		appendEvent(event{
+			Type: eventPositionOpened,
+			Strategy: strategy.Name,
+			Currency: strategy.Currency,
+			Up: strategy.Up,
+			Price: *price,
+			Quantity: *quantity,
+			Reason: "manual",
+		})
+		fmt.Printf("Opened position for %s: %s at %.4f\n", strategy.Name, formatDecimal(*quantity), *price)
+	case "close":
+		if existing == nil {
+			commons.Fatalf("Strategy %s doesn't hold a position", strategy.Name)
+		}
+		appendEvent(event{
+			Type: eventPositionClosed,
+			Strategy: strategy.Name,
+			Currency: strategy.Currency,
+			Up: strategy.Up,
+			Price: *price,
+			Quantity: existing.Quantity,
+			Reason: "manual",
+		})
+		returns := strategy.getReturns(existing.EntryPrice, *price)
+		fmt.Printf("Closed position for %s at %.4f, returns %+.2f%%\n", strategy.Name, *price, returns * percent)
+	default:
+		commons.Fatalf("Unknown position action: %s", action)
+	}
+}
\ No newline at end of file
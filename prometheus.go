@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+type conditionMetric struct {
+	name string
+	match bool
+}
+
+type strategyMetrics struct {
+	result *EvaluationResult
+	evaluated time.Time
+	signals int
+	errors int
+}
+
+var (
+	metricsAddress string
+	strategyMetricsMutex sync.Mutex
+	evaluationMetrics = map[string]*strategyMetrics{}
+)
+
+// Strategies outside of their entry windows aren't evaluated, so their gauges are removed rather than left at the values of the last window
+func recordEvaluation(strategy string, result *EvaluationResult) {
+	strategyMetricsMutex.Lock()
+	defer strategyMetricsMutex.Unlock()
+	metrics, exists := evaluationMetrics[strategy]
+	if !exists {
+		metrics = &strategyMetrics{}
+		evaluationMetrics[strategy] = metrics
+	}
+	metrics.result = result
+	if result == nil {
+		return
+	}
+	metrics.evaluated = time.Now().UTC()
+	if result.Error != "" {
+		metrics.errors++
+	} else if result.Signal && !result.Suppressed && !result.Duplicate {
+		metrics.signals++
+	}
+}
+
+func startMetricsServer() {
+	if metricsAddress == "" {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", handleMetrics)
+	go func () {
+		err := http.ListenAndServe(metricsAddress, mux)
+		if err != nil {
+			commons.Fatalf("Failed to serve metrics: %v", err)
+		}
+	}()
+	fmt.Fprintf(statusOutput, "Serving metrics on http://%s/metrics\n", metricsAddress)
+}
+
+func handleMetrics(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writer.Write([]byte(getMetrics()))
+}
+
+// Renders the metrics in the Prometheus text exposition format
+func getMetrics() string {
+	var builder strings.Builder
+	writeMetric := func (name string, labels []string, value float64) {
+		valueString := strconv.FormatFloat(value, 'f', -1, 64)
+		if len(labels) > 0 {
+			fmt.Fprintf(&builder, "%s{%s} %s\n", name, strings.Join(labels, ","), valueString)
+		} else {
+			fmt.Fprintf(&builder, "%s %s\n", name, valueString)
+		}
+	}
+	writeHeader := func (name string, metricType string, help string) {
+		fmt.Fprintf(&builder, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+	}
+	label := func (name string, value string) string {
+		replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+		return fmt.Sprintf("%s=\"%s\"", name, replacer.Replace(value))
+	}
+	getValue := func (value bool) float64 {
+		if value {
+			return 1
+		}
+		return 0
+	}
+	strategyMetricsMutex.Lock()
+	strategies := []string{}
+	for strategy := range evaluationMetrics {
+		strategies = append(strategies, strategy)
+	}
+	sort.Strings(strategies)
+	writeHeader("coinage_strategy_momentum_percent", "gauge", "Momentum of the strategy relative to the anchor of its current entry window.")
+	for _, strategy := range strategies {
+		result := evaluationMetrics[strategy].result
+		if result != nil && result.Momentum != nil {
+			writeMetric("coinage_strategy_momentum_percent", []string{label("strategy", strategy), label("currency", result.Currency)}, *result.Momentum)
+		}
+	}
+	writeHeader("coinage_strategy_condition_match", "gauge", "Whether a condition of the strategy matched in its latest evaluation.")
+	for _, strategy := range strategies {
+		result := evaluationMetrics[strategy].result
+		if result == nil || result.Error != "" {
+			continue
+		}
+		conditions := []conditionMetric{
+			{"weekday", result.WeekdayMatch},
+			{"time", result.TimeMatch},
+			{"gap", result.GapMatch},
+			{"price", result.PriceMatch},
+			{"momentum", result.MomentumMatch},
+			{"consensus", result.ConsensusMatch},
+			{"lookback", result.LookbackMatch},
+			{"timeframe", result.TimeframeMatch},
+			{"indicator", result.IndicatorMatch},
+		}
+		if result.ConditionMatch != nil {
+			conditions = append(conditions, conditionMetric{"conditions", *result.ConditionMatch})
+		}
+		if result.ExpressionMatch != nil {
+			conditions = append(conditions, conditionMetric{"expression", *result.ExpressionMatch})
+		}
+		for _, condition := range conditions {
+			writeMetric("coinage_strategy_condition_match", []string{label("strategy", strategy), label("condition", condition.name)}, getValue(condition.match))
+		}
+	}
+	writeHeader("coinage_strategy_signal", "gauge", "Whether all conditions of the strategy matched in its latest evaluation.")
+	for _, strategy := range strategies {
+		result := evaluationMetrics[strategy].result
+		if result != nil && result.Error == "" {
+			writeMetric("coinage_strategy_signal", []string{label("strategy", strategy)}, getValue(result.Signal && !result.Suppressed))
+		}
+	}
+	writeHeader("coinage_strategy_signals_total", "counter", "Number of signals emitted by the strategy.")
+	for _, strategy := range strategies {
+		writeMetric("coinage_strategy_signals_total", []string{label("strategy", strategy)}, float64(evaluationMetrics[strategy].signals))
+	}
+	writeHeader("coinage_strategy_errors_total", "counter", "Number of failed evaluations of the strategy.")
+	for _, strategy := range strategies {
+		writeMetric("coinage_strategy_errors_total", []string{label("strategy", strategy)}, float64(evaluationMetrics[strategy].errors))
+	}
+	writeHeader("coinage_strategy_last_evaluation_timestamp_seconds", "gauge", "UNIX time of the latest evaluation of the strategy.")
+	for _, strategy := range strategies {
+		evaluated := evaluationMetrics[strategy].evaluated
+		if !evaluated.IsZero() {
+			writeMetric("coinage_strategy_last_evaluation_timestamp_seconds", []string{label("strategy", strategy)}, float64(evaluated.Unix()))
+		}
+	}
+	strategyMetricsMutex.Unlock()
+	sourceMetricsMutex.Lock()
+	defer sourceMetricsMutex.Unlock()
+	providers := []string{}
+	for provider := range dataSourceMetrics {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	writeHeader("coinage_data_requests_total", "counter", "Number of requests to the data source.")
+	for _, provider := range providers {
+		writeMetric("coinage_data_requests_total", []string{label("provider", provider)}, float64(dataSourceMetrics[provider].requests))
+	}
+	writeHeader("coinage_data_request_failures_total", "counter", "Number of failed requests to the data source.")
+	for _, provider := range providers {
+		writeMetric("coinage_data_request_failures_total", []string{label("provider", provider)}, float64(dataSourceMetrics[provider].failures))
+	}
+	writeHeader("coinage_data_request_duration_seconds", "summary", "Latency of requests to the data source.")
+	for _, provider := range providers {
+		metrics := dataSourceMetrics[provider]
+		writeMetric("coinage_data_request_duration_seconds_sum", []string{label("provider", provider)}, metrics.totalLatency.Seconds())
+		writeMetric("coinage_data_request_duration_seconds_count", []string{label("provider", provider)}, float64(metrics.requests))
+	}
+	writeHeader("coinage_data_request_duration_max_seconds", "gauge", "Highest latency of a request to the data source.")
+	for _, provider := range providers {
+		writeMetric("coinage_data_request_duration_max_seconds", []string{label("provider", provider)}, dataSourceMetrics[provider].maxLatency.Seconds())
+	}
+	writeHeader("coinage_data_staleness_seconds", "gauge", "Highest observed age of the most recent candle of a symbol.")
+	for _, provider := range providers {
+		metrics := dataSourceMetrics[provider]
+		if metrics.stalenessSymbol != "" {
+			writeMetric("coinage_data_staleness_seconds", []string{label("provider", provider), label("symbol", metrics.stalenessSymbol)}, metrics.staleness.Seconds())
+		}
+	}
+	return builder.String()
+}
\ No newline at end of file
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Protective orders are placed relative to the actual fill price rather than the signal price
+func (s *Strategy) placeProtectiveOrders(executor Executor, fillPrice float64, quantity float64, result *EvaluationResult) error {
+	if s.StopLoss == nil && s.TakeProfit == nil {
+		return nil
+	}
+	filters, err := executor.GetSymbolFilters(s.Currency)
+	if err != nil {
+		return err
+	}
+	stopPrice := 0.0
+	takeProfitPrice := 0.0
+	if s.StopLoss != nil {
+		stopPrice, _ = strconv.ParseFloat(filters.formatPrice(s.getExitPrice(fillPrice, -*s.StopLoss)), 64)
+	}
+	if s.TakeProfit != nil {
+		takeProfitPrice, _ = strconv.ParseFloat(filters.formatPrice(s.getExitPrice(fillPrice, *s.TakeProfit)), 64)
+	}
+	err = s.placeExitOrders(executor, stopPrice, takeProfitPrice, quantity, "")
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		return nil
+	}
+	if stopPrice > 0 {
+		result.addMessage("Placed stop-loss at %.4f", stopPrice)
+	}
+	if takeProfitPrice > 0 {
+		result.addMessage("Placed take-profit at %.4f", takeProfitPrice)
+	}
+	return nil
+}
+
+// Prices of zero skip the respective order, the reason is recorded with the updated stop of the position
+func (s *Strategy) placeExitOrders(executor Executor, stopPrice float64, takeProfitPrice float64, quantity float64, reason string) error {
+	stopLoss := OrderRequest{
+		Symbol: s.Currency,
+		Buy: !s.Up,
+		Type: orderStopLoss,
+		Quantity: quantity,
+		ReduceOnly: true,
+	}
+	takeProfit := stopLoss
+	takeProfit.Type = orderTakeProfit
+	stopLoss.StopPrice = stopPrice
+	takeProfit.StopPrice = takeProfitPrice
+	// The prices are part of the client order IDs so that replaced stops don't reuse the IDs of the previous orders
+	now := time.Now()
+	stopLoss.ClientOrderID = getClientOrderID(s.Name, fmt.Sprintf("%s|%s", exitStopLoss, formatDecimal(stopPrice)), now)
+	takeProfit.ClientOrderID = getClientOrderID(s.Name, fmt.Sprintf("%s|%s", exitTakeProfit, formatDecimal(takeProfitPrice)), now)
+	// Separate protective orders on spot would lock the same balance twice, which is what OCO orders avoid
+	if stopPrice > 0 && takeProfitPrice > 0 && executor.SupportsOCO() {
+		orders, err := executor.PlaceOCO(stopLoss, takeProfit)
+		if err != nil {
+			return fmt.Errorf("failed to place OCO order: %v", err)
+		}
+		for _, order := range orders {
+			if order.Type == orderStopLoss {
+				s.recordProtectiveOrder(order, exitStopLoss, stopLoss.StopPrice, quantity)
+			} else {
+				s.recordProtectiveOrder(order, exitTakeProfit, takeProfit.StopPrice, quantity)
+			}
+		}
+	} else {
+		if stopPrice > 0 {
+			order, err := executor.PlaceOrder(stopLoss)
+			if err != nil {
+				return fmt.Errorf("failed to place stop-loss order: %v", err)
+			}
+			s.recordProtectiveOrder(order, exitStopLoss, stopLoss.StopPrice, quantity)
+		}
+		if takeProfitPrice > 0 {
+			order, err := executor.PlaceOrder(takeProfit)
+			if err != nil {
+				return fmt.Errorf("failed to place take-profit order: %v", err)
+			}
+			s.recordProtectiveOrder(order, exitTakeProfit, takeProfit.StopPrice, quantity)
+		}
+	}
+	if stopPrice > 0 && !dryRun {
+		appendEvent(event{
+			Type: eventPositionUpdated,
+			Strategy: s.Name,
+			Currency: s.Currency,
+			Up: s.Up,
+			Stop: stopPrice,
+			Reason: reason,
+		})
+	}
+	return nil
+}
+
+// Cancelling one leg of a spot OCO cancels the other one too, so both are replaced, and the previous orders remain in place if the replacement fails
+func (s *Strategy) replaceStop(executor Executor, p *position, stop float64, reason string) error {
+	previous := s.getProtectiveOrders()
+	previousStop := 0.0
+	takeProfitPrice := 0.0
+	for _, order := range previous {
+		if order.Reason == exitStopLoss {
+			previousStop = order.Price
+		} else if order.Reason == exitTakeProfit {
+			takeProfitPrice = order.Price
+		}
+	}
+	// Spot protective orders lock the balance of the position, so the replacements can only be placed once the previous orders are gone
+	if executor.SupportsOCO() {
+		err := s.cancelOrders(executor, previous)
+		if err != nil {
+			return err
+		}
+		err = s.placeExitOrders(executor, stop, takeProfitPrice, p.Quantity, reason)
+		if err != nil {
+			restoreErr := s.placeExitOrders(executor, previousStop, takeProfitPrice, p.Quantity, "")
+			if restoreErr != nil {
+				return fmt.Errorf("%v, failed to restore the previous orders: %v", err, restoreErr)
+			}
+			return err
+		}
+		return nil
+	}
+	err := s.placeExitOrders(executor, stop, takeProfitPrice, p.Quantity, reason)
+	if err != nil {
+		// Orders of a partially placed replacement are removed again so that only the previous ones remain
+		placed := map[string]event{}
+		for orderID, order := range s.getProtectiveOrders() {
+			_, exists := previous[orderID]
+			if !exists {
+				placed[orderID] = order
+			}
+		}
+		cancelErr := s.cancelOrders(executor, placed)
+		if cancelErr != nil {
+			return fmt.Errorf("%v, failed to cancel the partial replacement: %v", err, cancelErr)
+		}
+		return err
+	}
+	return s.cancelOrders(executor, previous)
+}
+
+// Protective orders are managed by the exchange so their fills have to be picked up on the next run
+func syncProtectiveOrders(filter string) {
+	for i := range configuration.Strategies {
+		strategy := &configuration.Strategies[i]
+		if !strategy.matchesFilter(filter) || strategy.Order == nil || strategy.getPosition() == nil {
+			continue
+		}
+		err := strategy.syncProtectiveOrders()
+		if err != nil {
+			fmt.Fprintf(statusOutput, "%s: failed to synchronize protective orders: %v\n", strategy.Name, err)
+		}
+	}
+}
+
+func (s *Strategy) getProtectiveOrders() map[string]event {
+	orders := map[string]event{}
+	for orderID, order := range getState().orders {
+		if order.Strategy == s.Name && (order.Reason == exitStopLoss || order.Reason == exitTakeProfit) {
+			orders[orderID] = order
+		}
+	}
+	return orders
+}
+
+func (s *Strategy) syncProtectiveOrders() error {
+	orders := s.getProtectiveOrders()
+	if len(orders) == 0 {
+		return nil
+	}
+	executor, err := s.newExecutor()
+	if err != nil {
+		return err
+	}
+	for orderID, order := range orders {
+		response, err := executor.GetOrder(s.Currency, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to query order %s: %v", orderID, err)
+		}
+		switch response.Status {
+		case orderStatusFilled:
+			p := s.getPosition()
+			fillPrice := response.AveragePrice
+			filledQuantity := response.ExecutedQuantity
+			appendEvent(event{
+				Type: eventFill,
+				Strategy: s.Name,
+				Currency: s.Currency,
+				Up: s.Up,
+				Buy: !s.Up,
+				OrderID: orderID,
+				Price: fillPrice,
+				Quantity: filledQuantity,
+			})
+			appendEvent(event{
+				Type: eventPositionClosed,
+				Strategy: s.Name,
+				Currency: s.Currency,
+				Up: s.Up,
+				Price: fillPrice,
+				Quantity: filledQuantity,
+				Reason: order.Reason,
+			})
+			// The other leg of a spot OCO is cancelled by the exchange, separate futures orders have to be cancelled here
+			err = s.cancelProtectiveOrders(executor)
+			if err != nil {
+				return err
+			}
+			if p != nil {
+				returns := s.getReturns(p.EntryPrice, fillPrice)
+				fmt.Fprintf(statusOutput, "%s: %s order filled at %.4f, returns %+.2f%%\n", s.Name, order.Reason, fillPrice, returns * percent)
+				s.sendExitNotification(p, fillPrice, order.Reason)
+			}
+			return nil
+		case orderStatusCancelled:
+			appendEvent(event{
+				Type: eventOrderCancelled,
+				Strategy: s.Name,
+				Currency: s.Currency,
+				Up: s.Up,
+				OrderID: orderID,
+				Reason: order.Reason,
+			})
+		}
+	}
+	return nil
+}
+
+// Exiting a spot position requires cancelling the protective orders first because they lock the balance
+func (s *Strategy) cancelProtectiveOrders(executor Executor) error {
+	return s.cancelOrders(executor, s.getProtectiveOrders())
+}
+
+func (s *Strategy) cancelOrders(executor Executor, orders map[string]event) error {
+	for orderID, order := range orders {
+		err := executor.CancelOrder(s.Currency, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to cancel order %s: %v", orderID, err)
+		}
+		if dryRun {
+			continue
+		}
+		appendEvent(event{
+			Type: eventOrderCancelled,
+			Strategy: s.Name,
+			Currency: s.Currency,
+			Up: s.Up,
+			OrderID: orderID,
+			Reason: order.Reason,
+		})
+	}
+	return nil
+}
+
+func (s *Strategy) recordProtectiveOrder(order Order, reason string, price float64, quantity float64) {
+	if dryRun {
+		return
+	}
+	appendEvent(event{
+		Type: eventOrder,
+		Strategy: s.Name,
+		Currency: s.Currency,
+		Up: s.Up,
+		Buy: !s.Up,
+		OrderID: order.ID,
+		ClientOrderID: order.ClientOrderID,
+		Price: price,
+		Quantity: quantity,
+		Reason: reason,
+	})
+}
\ No newline at end of file
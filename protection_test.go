@@ -0,0 +1,98 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReplaceStop(t *testing.T) {
+	entryTime := time.Date(2025, 3, 3, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		failType string
+		placed []string
+		cancelled []string
+		stop float64
+		fails bool
+	}{
+		{"replaced", "", []string{orderStopLoss, orderTakeProfit}, []string{"stop", "target"}, 98, false},
+		{"stop rejected", orderStopLoss, nil, nil, 95, true},
+		{"take-profit rejected", orderTakeProfit, []string{orderStopLoss}, []string{"1"}, 95, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func (t *testing.T) {
+			setupEventLog(t)
+			configuration = &Configuration{}
+			s := &Strategy{
+				Name: "trailing",
+				Currency: "BTCUSDT",
+				Up: true,
+			}
+			appendEvent(event{
+				Time: entryTime,
+				Type: eventPositionOpened,
+				Strategy: s.Name,
+				Currency: s.Currency,
+				Up: s.Up,
+				Price: 100,
+				Quantity: 1,
+				Stop: 95,
+			})
+			for _, order := range []event{
+				{OrderID: "stop", Price: 95, Reason: exitStopLoss},
+				{OrderID: "target", Price: 110, Reason: exitTakeProfit},
+			} {
+				order.Time = entryTime
+				order.Type = eventOrder
+				order.Strategy = s.Name
+				order.Currency = s.Currency
+				order.Up = s.Up
+				order.Quantity = 1
+				appendEvent(order)
+			}
+			executor := &fakeExecutor{
+				failType: test.failType,
+			}
+			err := s.replaceStop(executor, s.getPosition(), 98, exitTrailingStop)
+			if (err != nil) != test.fails {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// The previous orders may only be cancelled once their replacements have been placed
+			placed := []string{}
+			for _, request := range executor.placed {
+				placed = append(placed, request.Type)
+			}
+			if !slices.Equal(placed, test.placed) {
+				t.Errorf("expected the orders %v to be placed, got %v", test.placed, placed)
+			}
+			for i, call := range executor.calls {
+				if i < len(placed) != strings.HasPrefix(call, "place ") {
+					t.Errorf("expected the orders to be placed before any are cancelled, got the calls %v", executor.calls)
+					break
+				}
+			}
+			cancelled := slices.Sorted(slices.Values(executor.cancelled))
+			if !slices.Equal(cancelled, test.cancelled) {
+				t.Errorf("expected the orders %v to be cancelled, got %v", test.cancelled, cancelled)
+			}
+			if stop := s.getPosition().Stop; stop != test.stop {
+				t.Errorf("expected the position stop to be %.2f, got %.2f", test.stop, stop)
+			}
+			// Failed replacements leave the previous orders as the only protection
+			orders := []string{}
+			for orderID := range s.getProtectiveOrders() {
+				orders = append(orders, orderID)
+			}
+			slices.Sort(orders)
+			expected := []string{"1", "2"}
+			if test.fails {
+				expected = []string{"stop", "target"}
+			}
+			if !slices.Equal(orders, expected) {
+				t.Errorf("expected the protective orders %v, got %v", expected, orders)
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+// Crypto markets trade around the clock so a year consists of 365 full days of candles
+const candlesPerYear = 365 * 24 * candlesPerHour
+
+type RealizedVolatilityConfiguration struct {
+	Hours int `yaml:"hours"`
+	MinVolatility *float64 `yaml:"minVolatility"`
+	MaxVolatility *float64 `yaml:"maxVolatility"`
+}
+
+func (c *RealizedVolatilityConfiguration) validate(strategy string) {
+	if c.Hours <= 0 {
+		commons.Fatalf("Invalid realized volatility period for strategy %s", strategy)
+	}
+	if c.MinVolatility == nil && c.MaxVolatility == nil {
+		commons.Fatalf("Missing realized volatility thresholds for strategy %s", strategy)
+	}
+	if c.MinVolatility != nil && *c.MinVolatility < 0 || c.MaxVolatility != nil && *c.MaxVolatility <= 0 {
+		commons.Fatalf("Invalid realized volatility thresholds for strategy %s", strategy)
+	}
+	if c.MinVolatility != nil && c.MaxVolatility != nil && *c.MinVolatility >= *c.MaxVolatility {
+		commons.Fatalf("Minimum realized volatility must be below the maximum for strategy %s", strategy)
+	}
+}
+
+func (c *RealizedVolatilityConfiguration) getLookback() time.Duration {
+	return time.Duration(c.Hours) * time.Hour
+}
+
+// Annualized standard deviation of the log returns of the candles in the window in percent
+func (c *RealizedVolatilityConfiguration) getVolatility(records []ohlcRecord) (float64, bool, bool) {
+	count := c.Hours * candlesPerHour + 1
+	if len(records) < count {
+		return 0, false, false
+	}
+	candles := records[len(records) - count:]
+	returns := []float64{}
+	for i := 1; i < len(candles); i++ {
+		returns = append(returns, math.Log(candles[i].close / candles[i - 1].close))
+	}
+	volatility := getStandardDeviation(returns) * math.Sqrt(float64(candlesPerYear)) * percent
+	match := true
+	if c.MinVolatility != nil && volatility < *c.MinVolatility {
+		match = false
+	}
+	if c.MaxVolatility != nil && volatility > *c.MaxVolatility {
+		match = false
+	}
+	return volatility, match, true
+}
\ No newline at end of file
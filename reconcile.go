@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	reconcileReport = "report"
+	reconcileAdopt = "adopt"
+	reconcileClose = "close"
+	reconcileReason = "reconciled"
+	// Spot fees paid in the base asset make the balance slightly smaller than the filled quantity
+	reconcileTolerance = 0.01
+)
+
+var reconcileMode string
+
+func validateReconcileMode() {
+	if reconcileMode != reconcileReport && reconcileMode != reconcileAdopt && reconcileMode != reconcileClose {
+		commons.Fatalf("Unknown reconciliation mode: %s", reconcileMode)
+	}
+}
+
+// Orphaned exchange positions are only detected on futures since spot balances can't be attributed to strategies
+func reconcile(filter string) {
+	// Adopting orders and positions only modifies the event log, which a dry run must leave untouched
+	if dryRun && reconcileMode == reconcileAdopt {
+		reconcileMode = reconcileReport
+	}
+	keys, strategies := getAccounts(filter)
+	for _, key := range keys {
+		err := reconcileAccount(key, strategies[key])
+		if err != nil {
+			fmt.Fprintf(statusOutput, "Failed to reconcile %s %s account %s: %v\n", key.exchange, key.market, key.account, err)
+		}
+	}
+}
+
+func reconcileAccount(key accountKey, strategies []*Strategy) error {
+	executor, err := strategies[0].newExecutor()
+	if err != nil {
+		return err
+	}
+	defer func () {
+		for _, request := range executor.DryRunRequests() {
+			fmt.Fprintf(statusOutput, "Reconciliation: dry run: %s\n", request)
+		}
+	}()
+	err = reconcileOrders(executor, strategies)
+	if err != nil {
+		return err
+	}
+	symbols := []string{}
+	for _, strategy := range strategies {
+		if !slices.Contains(symbols, strategy.Currency) {
+			symbols = append(symbols, strategy.Currency)
+		}
+	}
+	for _, symbol := range symbols {
+		if key.market == marketFutures {
+			err = reconcileFuturesPosition(executor, symbol, strategies)
+		} else {
+			err = reconcileSpotPositions(executor, symbol, strategies)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Orders placed manually or by other software on the same account are left alone
+func reconcileOrders(executor Executor, strategies []*Strategy) error {
+	orders, err := executor.GetOpenOrders("")
+	if err != nil {
+		return fmt.Errorf("failed to retrieve open orders: %v", err)
+	}
+	symbols := []string{}
+	for _, strategy := range strategies {
+		symbols = append(symbols, strategy.Currency)
+	}
+	local := getState().orders
+	for _, order := range orders {
+		_, exists := local[order.ID]
+		if exists {
+			continue
+		}
+		if !strings.HasPrefix(order.ClientOrderID, clientOrderPrefix) || !slices.Contains(symbols, order.Symbol) {
+			fmt.Fprintf(statusOutput, "Reconciliation: ignoring %s order %s for %s, which wasn't placed by a strategy\n", order.Type, order.ID, order.Symbol)
+			continue
+		}
+		fmt.Fprintf(statusOutput, "Reconciliation: untracked %s order %s for %s\n", order.Type, order.ID, order.Symbol)
+		switch reconcileMode {
+		case reconcileAdopt:
+			exit := order.Type == orderStopLoss || order.Type == orderTakeProfit
+			strategy := getReconcileStrategy(strategies, order.Symbol, order.Buy != exit, exit)
+			if strategy == nil {
+				fmt.Fprintf(statusOutput, "Reconciliation: no unique strategy to adopt order %s\n", order.ID)
+				continue
+			}
+			reason := order.Type
+			if reason == orderStopLoss {
+				reason = exitStopLoss
+			} else if reason == orderTakeProfit {
+				reason = exitTakeProfit
+			}
+			appendEvent(event{
+				Type: eventOrder,
+				Strategy: strategy.Name,
+				Currency: strategy.Currency,
+				Up: strategy.Up,
+				Buy: order.Buy,
+				OrderID: order.ID,
+				ClientOrderID: order.ClientOrderID,
+				Price: order.Price,
+				Quantity: order.Quantity,
+				Reason: reason,
+			})
+			fmt.Fprintf(statusOutput, "Reconciliation: adopted order %s for strategy %s\n", order.ID, strategy.Name)
+		case reconcileClose:
+			err := executor.CancelOrder(order.Symbol, order.ID)
+			if err != nil {
+				return fmt.Errorf("failed to cancel order %s: %v", order.ID, err)
+			}
+			fmt.Fprintf(statusOutput, "Reconciliation: cancelled order %s\n", order.ID)
+		}
+	}
+	return nil
+}
+
+// Exit orders belong to strategies with an open position, everything else to ones without, and the match has to be unambiguous
+func getReconcileStrategy(strategies []*Strategy, symbol string, up bool, open bool) *Strategy {
+	var output *Strategy
+	for _, strategy := range strategies {
+		if strategy.Currency != symbol || strategy.Up != up || (strategy.getPosition() != nil) != open {
+			continue
+		}
+		if output != nil {
+			return nil
+		}
+		output = strategy
+	}
+	return output
+}
+
+func getLocalPositions(strategies []*Strategy, symbol string) []*position {
+	positions := []*position{}
+	for _, strategy := range strategies {
+		p := strategy.getPosition()
+		if strategy.Currency == symbol && p != nil {
+			positions = append(positions, p)
+		}
+	}
+	return positions
+}
+
+func reconcileFuturesPosition(executor Executor, symbol string, strategies []*Strategy) error {
+	exchangePosition, err := executor.GetPosition(symbol)
+	if err != nil {
+		return err
+	}
+	positions := getLocalPositions(strategies, symbol)
+	local := 0.0
+	for _, p := range positions {
+		local += getSignedQuantity(p)
+	}
+	if math.Abs(exchangePosition.Amount - local) <= reconcileTolerance * math.Abs(local) {
+		return nil
+	}
+	fmt.Fprintf(statusOutput, "Reconciliation: %s position on the exchange is %s but %s locally\n", symbol, formatDecimal(exchangePosition.Amount), formatDecimal(local))
+	if reconcileMode == reconcileReport {
+		return nil
+	}
+	if exchangePosition.Amount == 0 {
+		dropLocalPositions(positions)
+		return nil
+	}
+	if len(positions) > 0 {
+		fmt.Fprintf(statusOutput, "Reconciliation: %s positions differ in size, resolve them manually\n", symbol)
+		return nil
+	}
+	orphan := &position{
+		Currency: symbol,
+		Up: exchangePosition.Amount > 0,
+		EntryTime: time.Now().UTC(),
+		EntryPrice: exchangePosition.EntryPrice,
+		Quantity: math.Abs(exchangePosition.Amount),
+	}
+	if reconcileMode == reconcileAdopt {
+		strategy := getReconcileStrategy(strategies, symbol, orphan.Up, false)
+		if strategy == nil {
+			fmt.Fprintf(statusOutput, "Reconciliation: no unique strategy to adopt the %s position\n", symbol)
+			return nil
+		}
+		appendEvent(event{
+			Type: eventPositionOpened,
+			Strategy: strategy.Name,
+			Currency: symbol,
+			Up: orphan.Up,
+			Price: orphan.EntryPrice,
+			Quantity: orphan.Quantity,
+			Reason: reconcileReason,
+		})
+		fmt.Fprintf(statusOutput, "Reconciliation: adopted %s position for strategy %s\n", symbol, strategy.Name)
+		return nil
+	}
+	clientOrderID := getClientOrderID(symbol, reconcileReason, orphan.EntryTime.Truncate(time.Hour))
+	order, err := executor.ClosePosition(orphan, clientOrderID)
+	if err != nil {
+		return fmt.Errorf("failed to close orphaned %s position: %v", symbol, err)
+	}
+	fmt.Fprintf(statusOutput, "Reconciliation: closed orphaned %s position with order %s (%s)\n", symbol, order.ID, order.Status)
+	return nil
+}
+
+func reconcileSpotPositions(executor Executor, symbol string, strategies []*Strategy) error {
+	positions := getLocalPositions(strategies, symbol)
+	if len(positions) == 0 {
+		return nil
+	}
+	filters, err := executor.GetSymbolFilters(symbol)
+	if err != nil {
+		return err
+	}
+	balances, err := executor.GetBalances()
+	if err != nil {
+		return err
+	}
+	balance := 0.0
+	for _, b := range balances {
+		if b.Asset == filters.baseAsset {
+			balance = b.Free + b.Locked
+		}
+	}
+	local := 0.0
+	for _, p := range positions {
+		if p.Up {
+			local += p.Quantity
+		}
+	}
+	if balance >= local * (1.0 - reconcileTolerance) {
+		return nil
+	}
+	fmt.Fprintf(statusOutput, "Reconciliation: %s balance is %s but local positions require %s\n", filters.baseAsset, formatDecimal(balance), formatDecimal(local))
+	if reconcileMode != reconcileReport && balance == 0 {
+		dropLocalPositions(positions)
+	}
+	return nil
+}
+
+// Positions that no longer exist on the exchange are closed without an exit price so they don't count towards the P&L
+func dropLocalPositions(positions []*position) {
+	if dryRun {
+		return
+	}
+	for _, p := range positions {
+		appendEvent(event{
+			Type: eventPositionClosed,
+			Strategy: p.Strategy,
+			Currency: p.Currency,
+			Up: p.Up,
+			Quantity: p.Quantity,
+			Reason: reconcileReason,
+		})
+		fmt.Fprintf(statusOutput, "Reconciliation: removed local position of strategy %s\n", p.Strategy)
+	}
+}
\ No newline at end of file
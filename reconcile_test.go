@@ -0,0 +1,34 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestReconcileOrders(t *testing.T) {
+	setupEventLog(t)
+	configuration = &Configuration{}
+	reconcileMode = reconcileClose
+	t.Cleanup(func () {
+		reconcileMode = ""
+	})
+	strategies := []*Strategy{
+		{Name: "btc-up", Currency: "BTCUSDT", Up: true},
+	}
+	ownID := getClientOrderID("btc-up", "entry", time.Date(2025, 3, 3, 15, 0, 0, 0, time.UTC))
+	executor := &fakeExecutor{
+		openOrders: []Order{
+			{ID: "own", ClientOrderID: ownID, Symbol: "BTCUSDT", Type: orderLimit, Buy: true},
+			{ID: "manual", ClientOrderID: "web_3f2a9c", Symbol: "BTCUSDT", Type: orderLimit, Buy: true},
+			{ID: "unconfigured", ClientOrderID: ownID, Symbol: "ETHUSDT", Type: orderLimit, Buy: true},
+		},
+	}
+	err := reconcileOrders(executor, strategies)
+	if err != nil {
+		t.Fatalf("failed to reconcile orders: %v", err)
+	}
+	if !slices.Equal(executor.cancelled, []string{"own"}) {
+		t.Errorf("expected only the untracked order of the strategy to be cancelled, got %v", executor.cancelled)
+	}
+}
\ No newline at end of file
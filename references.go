@@ -0,0 +1,106 @@
+package main
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+type referenceSeries struct {
+	from time.Time
+	to time.Time
+	records []ohlcRecord
+}
+
+var (
+	referenceRecords = map[string]referenceSeries{}
+	referenceLock sync.Mutex
+)
+
+// Symbols whose candles are required in addition to those of the strategy to evaluate its conditions
+func (s *Strategy) getReferenceSymbols() []string {
+	symbols := []string{}
+	add := func (symbol string) {
+		if !slices.Contains(symbols, symbol) {
+			symbols = append(symbols, symbol)
+		}
+	}
+	if s.RelativeStrength != nil {
+		add(s.RelativeStrength.getSymbol())
+	}
+	for _, gate := range s.Gates {
+		add(gate.Symbol)
+	}
+	if s.Correlation != nil {
+		add(s.Correlation.getSymbol())
+	}
+	if s.Conditions != nil {
+		for _, symbol := range s.Conditions.getReferenceSymbols() {
+			add(symbol)
+		}
+	}
+	return symbols
+}
+
+// Live evaluations always download the latest candles of the reference symbols
+func (s *Strategy) loadLiveReferences() error {
+	for _, symbol := range s.getReferenceSymbols() {
+		records, err := s.loadRecords(symbol)
+		if err != nil {
+			return err
+		}
+		records, _ = filterRecords(records)
+		referenceLock.Lock()
+		referenceRecords[symbol] = referenceSeries{
+			records: records,
+		}
+		referenceLock.Unlock()
+	}
+	return nil
+}
+
+// Backtests of several strategies share the historical candles of a reference symbol as long as they cover the range
+func (s *Strategy) loadHistoricalReferences(from time.Time, to time.Time) {
+	for _, symbol := range s.getReferenceSymbols() {
+		referenceLock.Lock()
+		series, exists := referenceRecords[symbol]
+		if exists && !series.from.After(from) && !series.to.Before(to) {
+			referenceLock.Unlock()
+			continue
+		}
+		seriesFrom := from
+		seriesTo := to
+		if exists && !series.from.IsZero() {
+			if series.from.Before(from) {
+				seriesFrom = series.from
+			}
+			if series.to.After(to) {
+				seriesTo = series.to
+			}
+		}
+		records := loadHistoricalRecords(symbol, seriesFrom, seriesTo)
+		records, _ = filterRecords(records)
+		referenceRecords[symbol] = referenceSeries{
+			from: seriesFrom,
+			to: seriesTo,
+			records: records,
+		}
+		referenceLock.Unlock()
+	}
+}
+
+func getReferenceRecords(symbol string) []ohlcRecord {
+	referenceLock.Lock()
+	defer referenceLock.Unlock()
+	return referenceRecords[symbol].records
+}
+
+// The momentum of a reference symbol is measured over the same window as that of the strategy, which requires a candle with the same timestamp as the latest one
+func getReferenceMomentum(symbol string, latest time.Time, entryTime time.Time, offset time.Duration) (float64, bool) {
+	records := getReferenceRecords(symbol)
+	latestIndex := findRecord(records, latest)
+	if latestIndex >= len(records) || !records[latestIndex].timestamp.Equal(latest) {
+		return 0, false
+	}
+	return getAnchoredMomentum(records, latestIndex, entryTime, offset)
+}
\ No newline at end of file
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	defaultBenchmark = "BTCUSDT"
+)
+
+type RelativeStrengthConfiguration struct {
+	Symbol string `yaml:"symbol"`
+	Offset int `yaml:"offset"`
+	GreaterThan *float64 `yaml:"greaterThan"`
+	LessThan *float64 `yaml:"lessThan"`
+}
+
+func (c *RelativeStrengthConfiguration) validate(strategy string) {
+	if c.Offset < 0 {
+		commons.Fatalf("Invalid relative strength offset for strategy %s", strategy)
+	}
+	if c.GreaterThan == nil && c.LessThan == nil {
+		commons.Fatalf("Missing relative strength constraint for strategy %s", strategy)
+	}
+}
+
+func (c *RelativeStrengthConfiguration) getSymbol() string {
+	if c.Symbol == "" {
+		return defaultBenchmark
+	}
+	return c.Symbol
+}
+
+// Defaults to the momentum window of the strategy
+func (c *RelativeStrengthConfiguration) getOffset(s *Strategy) time.Duration {
+	if c.Offset == 0 {
+		return s.Offset.Duration
+	}
+	return time.Duration(c.Offset) * time.Hour
+}
+
+// The relative strength is the difference between the momentum of the currency and that of the benchmark in percentage points
+func (c *RelativeStrengthConfiguration) getRelativeStrength(s *Strategy, records []ohlcRecord, latestIndex int, entryTime time.Time) (float64, bool, bool) {
+	offset := c.getOffset(s)
+	momentum, ok := getAnchoredMomentum(records, latestIndex, entryTime, offset)
+	if !ok {
+		return 0, false, false
+	}
+	benchmarkMomentum, ok := getReferenceMomentum(c.getSymbol(), records[latestIndex].timestamp, entryTime, offset)
+	if !ok {
+		return 0, false, false
+	}
+	strength := momentum - benchmarkMomentum
+	match := true
+	if c.GreaterThan != nil {
+		match = match && strength > *c.GreaterThan
+	}
+	if c.LessThan != nil {
+		match = match && strength < *c.LessThan
+	}
+	return strength, match, true
+}
\ No newline at end of file
@@ -0,0 +1,476 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/encratite/commons"
+	"github.com/fatih/color"
+)
+
+const (
+	formatConsole = "console"
+	formatJSON = "json"
+	formatMarkdown = "markdown"
+	formatCSV = "csv"
+	formatWebhook = "webhook"
+	suppressedByLossLimit = "daily loss limit"
+	suppressedByLiquidity = "insufficient liquidity"
+	suppressedByRiskGuard = "risk guard"
+	suppressedByFunding = "funding rate"
+	suppressedByOpenInterest = "open interest"
+)
+
+type EvaluationResult struct {
+	Strategy string `json:"strategy"`
+	Currency string `json:"currency"`
+	Weekdays []string `json:"weekdays,omitempty"`
+	Times []string `json:"times,omitempty"`
+	Schedule string `json:"schedule,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+	Offset string `json:"offset,omitempty"`
+	MomentumType string `json:"momentumType,omitempty"`
+	GreaterThan *float64 `json:"greaterThan,omitempty"`
+	LessThan *float64 `json:"lessThan,omitempty"`
+	Up bool `json:"up"`
+	Anomalies []string `json:"anomalies,omitempty"`
+	Gaps []string `json:"gaps,omitempty"`
+	GapMatch bool `json:"gapMatch"`
+	CurrentPrice float64 `json:"currentPrice,omitempty"`
+	PriceAbove *float64 `json:"priceAbove,omitempty"`
+	PriceBelow *float64 `json:"priceBelow,omitempty"`
+	PriceMatch bool `json:"priceMatch"`
+	Sparkline []float64 `json:"sparkline,omitempty"`
+	MomentumPrice *float64 `json:"momentumPrice,omitempty"`
+	MomentumTime *time.Time `json:"momentumTime,omitempty"`
+	Time time.Time `json:"time"`
+	EntryTime time.Time `json:"entryTime"`
+	WeekdayMatch bool `json:"weekdayMatch"`
+	TimeMatch bool `json:"timeMatch"`
+	Momentum *float64 `json:"momentum,omitempty"`
+	MomentumMatch bool `json:"momentumMatch"`
+	Lookbacks []lookbackResult `json:"lookbacks,omitempty"`
+	LookbackMatch bool `json:"lookbackMatch"`
+	Timeframes []timeframeResult `json:"timeframes,omitempty"`
+	TimeframeMatch bool `json:"timeframeMatch"`
+	ConsensusAgreements *int `json:"consensusAgreements,omitempty"`
+	ConsensusHorizons int `json:"consensusHorizons,omitempty"`
+	ConsensusMatch bool `json:"consensusMatch"`
+	RSI *float64 `json:"rsi,omitempty"`
+	RSIMatch bool `json:"rsiMatch,omitempty"`
+	MovingAverages []movingAverageResult `json:"movingAverages,omitempty"`
+	MACD *macdResult `json:"macd,omitempty"`
+	Bollinger *bollingerResult `json:"bollinger,omitempty"`
+	ATR *float64 `json:"atr,omitempty"`
+	ATRMatch bool `json:"atrMatch,omitempty"`
+	VolumeRatio *float64 `json:"volumeRatio,omitempty"`
+	VolumeMatch bool `json:"volumeMatch,omitempty"`
+	Breakout *breakoutResult `json:"breakout,omitempty"`
+	ZScore *float64 `json:"zScore,omitempty"`
+	ZScoreMatch bool `json:"zScoreMatch,omitempty"`
+	RelativeStrength *float64 `json:"relativeStrength,omitempty"`
+	RelativeStrengthMatch bool `json:"relativeStrengthMatch,omitempty"`
+	WeekendGap *float64 `json:"weekendGap,omitempty"`
+	WeekendGapMatch bool `json:"weekendGapMatch,omitempty"`
+	Donchian *float64 `json:"donchian,omitempty"`
+	DonchianMatch bool `json:"donchianMatch,omitempty"`
+	EMASlope *float64 `json:"emaSlope,omitempty"`
+	EMASlopeMatch bool `json:"emaSlopeMatch,omitempty"`
+	Gates []gateResult `json:"gates,omitempty"`
+	VWAPDeviation *float64 `json:"vwapDeviation,omitempty"`
+	VWAPMatch bool `json:"vwapMatch,omitempty"`
+	Pattern *string `json:"pattern,omitempty"`
+	Score *float64 `json:"score,omitempty"`
+	ScoreMatch bool `json:"scoreMatch,omitempty"`
+	PercentileRank *float64 `json:"percentileRank,omitempty"`
+	PercentileMatch bool `json:"percentileMatch,omitempty"`
+	RealizedVolatility *float64 `json:"realizedVolatility,omitempty"`
+	RealizedVolatilityMatch bool `json:"realizedVolatilityMatch,omitempty"`
+	Correlation *float64 `json:"correlation,omitempty"`
+	CorrelationMatch bool `json:"correlationMatch,omitempty"`
+	IndicatorMatch bool `json:"indicatorMatch"`
+	ConditionMatch *bool `json:"conditionMatch,omitempty"`
+	ExpressionMatch *bool `json:"expressionMatch,omitempty"`
+	Signal bool `json:"signal"`
+	Suppressed bool `json:"suppressed"`
+	Duplicate bool `json:"duplicate,omitempty"`
+	SuppressedBy string `json:"suppressedBy,omitempty"`
+	LiquidityMatch bool `json:"liquidityMatch"`
+	QuoteVolume *float64 `json:"quoteVolume,omitempty"`
+	TradeCount *int64 `json:"tradeCount,omitempty"`
+	FundingRate *float64 `json:"fundingRate,omitempty"`
+	FundingMatch bool `json:"fundingMatch"`
+	OpenInterestChange *float64 `json:"openInterestChange,omitempty"`
+	OpenInterestMatch bool `json:"openInterestMatch"`
+	Position *position `json:"position,omitempty"`
+	Costs *float64 `json:"costs,omitempty"`
+	BreakEvenTrigger *float64 `json:"breakEvenTrigger,omitempty"`
+	BreakEvenStop *float64 `json:"breakEvenStop,omitempty"`
+	Messages []string `json:"messages,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type Renderer interface {
+	Render(result EvaluationResult)
+	Close()
+}
+
+type consoleRenderer struct {}
+
+type jsonRenderer struct {
+	results []EvaluationResult
+}
+
+type markdownRenderer struct {
+	results []EvaluationResult
+}
+
+type webhookRenderer struct {
+	url string
+	results []EvaluationResult
+}
+
+type webhookPayload struct {
+	Text string `json:"text"`
+	Results []EvaluationResult `json:"results"`
+}
+
+var statusOutput io.Writer = os.Stdout
+
+// Quiet mode only renders strategies whose conditions all match and discards all status output
+var quietMode bool
+
+// Machine-readable formats keep standard output clean by writing status information to standard error
+func newRenderer(format string, webhookURL string) Renderer {
+	switch format {
+	case formatConsole:
+		return &consoleRenderer{}
+	case formatJSON:
+		statusOutput = os.Stderr
+		return &jsonRenderer{}
+	case formatMarkdown:
+		statusOutput = os.Stderr
+		return &markdownRenderer{}
+	case formatCSV:
+		statusOutput = os.Stderr
+		return &csvRenderer{}
+	case formatWebhook:
+		if webhookURL == "" {
+			commons.Fatalf("Missing webhook URL")
+		}
+		return &webhookRenderer{
+			url: webhookURL,
+		}
+	default:
+		commons.Fatalf("Unknown output format: %s", format)
+		return nil
+	}
+}
+
+func (r *EvaluationResult) addMessage(format string, arguments ...any) {
+	r.Messages = append(r.Messages, fmt.Sprintf(format, arguments...))
+}
+
+// Results that weren't produced by an evaluation fall back to the entry window at the start of the next hour
+func (r *EvaluationResult) getEntryWindow() time.Time {
+	if r.EntryTime.IsZero() {
+		return getEntryWindow(r.Time)
+	}
+	return r.EntryTime
+}
+
+func (r *EvaluationResult) getSide() string {
+	if r.Up {
+		return "Up"
+	} else {
+		return "Down"
+	}
+}
+
+func (consoleRenderer) Render(result EvaluationResult) {
+	blue := color.New(color.FgBlue).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	fmt.Printf("%s:\n", result.Strategy)
+	if result.Error != "" {
+		fmt.Printf("\t%s\n\n", red(fmt.Sprintf("Error: %s", result.Error)))
+		return
+	}
+	fmt.Printf("\tCurrency: %s\n", blue(result.Currency))
+	if result.Schedule != "" {
+		fmt.Printf("\tSchedule: %s\n", result.Schedule)
+	} else {
+		fmt.Printf("\tWeekdays: %s\n", strings.Join(result.Weekdays, ", "))
+		fmt.Printf("\tTimes: %s\n", strings.Join(result.Times, ", "))
+	}
+	if result.Timezone != "" && result.Timezone != time.UTC.String() {
+		fmt.Printf("\tTimezone: %s\n", result.Timezone)
+	}
+	fmt.Printf("\tMomentum offset: %s\n", result.Offset)
+	if result.MomentumType != "" {
+		fmt.Printf("\tMomentum type: %s\n", result.MomentumType)
+	}
+	if result.GreaterThan != nil {
+		fmt.Printf("\tGreater than: %.2f%%\n", *result.GreaterThan)
+	}
+	if result.LessThan != nil {
+		fmt.Printf("\tLess than: %.2f%%\n", *result.LessThan)
+	}
+	var sideString string
+	if result.Up {
+		sideString = green(result.getSide())
+	} else {
+		sideString = red(result.getSide())
+	}
+	fmt.Printf("\tSide: %s\n", sideString)
+	if len(result.Anomalies) > 0 {
+		fmt.Printf("\tFiltered candles: %s\n", red(strings.Join(result.Anomalies, ", ")))
+	}
+	if len(result.Gaps) > 0 {
+		fmt.Printf("\tMissing candles in momentum window: %s (%s)\n", red(strings.Join(result.Gaps, ", ")), formatBool(result.GapMatch))
+	}
+	fmt.Printf("\tCurrent price: %.4f\n", result.CurrentPrice)
+	if result.PriceAbove != nil {
+		fmt.Printf("\tPrice above: %.4f (%s)\n", *result.PriceAbove, formatBool(result.CurrentPrice > *result.PriceAbove))
+	}
+	if result.PriceBelow != nil {
+		fmt.Printf("\tPrice below: %.4f (%s)\n", *result.PriceBelow, formatBool(result.CurrentPrice < *result.PriceBelow))
+	}
+	if result.MomentumPrice != nil {
+		fmt.Printf("\tMomentum price: %.4f\n", *result.MomentumPrice)
+		fmt.Printf("\tMomentum time: %s UTC\n", commons.GetTimeString(*result.MomentumTime))
+	} else {
+		fmt.Printf("\tMomentum price: %s\n", red("missing"))
+	}
+	fmt.Printf("\tCurrent weekday: %s (%s)\n", result.Time.Weekday(), formatBool(result.WeekdayMatch))
+	fmt.Printf("\tCurrent time of day: %02d:%02d UTC (%s)\n", result.Time.Hour(), result.Time.Minute(), formatBool(result.TimeMatch))
+	momentum := math.NaN()
+	if result.Momentum != nil {
+		momentum = *result.Momentum
+	}
+	fmt.Printf("\tCurrent momentum: %+.2f%% (%s)\n", momentum, formatBool(result.MomentumMatch))
+	for _, lookback := range result.Lookbacks {
+		lookbackMomentum := math.NaN()
+		if lookback.Momentum != nil {
+			lookbackMomentum = *lookback.Momentum
+		}
+		fmt.Printf("\t%s momentum: %+.2f%% (%s)\n", lookback.Offset, lookbackMomentum, formatBool(lookback.Match))
+	}
+	for _, timeframe := range result.Timeframes {
+		timeframeMomentum := math.NaN()
+		if timeframe.Momentum != nil {
+			timeframeMomentum = *timeframe.Momentum
+		}
+		fmt.Printf("\t%d x %s momentum: %+.2f%% (%s)\n", timeframe.Offset, timeframe.Interval, timeframeMomentum, formatBool(timeframe.Match))
+	}
+	if result.ConsensusAgreements != nil {
+		fmt.Printf("\tConsensus: %d/%d horizons (%s)\n", *result.ConsensusAgreements, result.ConsensusHorizons, formatBool(result.ConsensusMatch))
+	}
+	if result.RSI != nil {
+		fmt.Printf("\tRSI: %.2f (%s)\n", *result.RSI, formatBool(result.RSIMatch))
+	}
+	for _, movingAverage := range result.MovingAverages {
+		fmt.Printf("\tMoving averages %s: %.4f vs. %.4f (%s)\n", movingAverage.Name, movingAverage.Fast, movingAverage.Slow, formatBool(movingAverage.Match))
+	}
+	if result.MACD != nil {
+		fmt.Printf("\tMACD: line %.4f, signal %.4f, histogram %+.4f (%s)\n", result.MACD.Line, result.MACD.Signal, result.MACD.Histogram, formatBool(result.MACD.Match))
+	}
+	if result.Bollinger != nil {
+		fmt.Printf("\tBollinger Bands: %.4f - %.4f, bandwidth %.2f%% (%s)\n", result.Bollinger.Lower, result.Bollinger.Upper, result.Bollinger.Bandwidth, formatBool(result.Bollinger.Match))
+	}
+	if result.ATR != nil {
+		fmt.Printf("\tATR: %.2f%% (%s)\n", *result.ATR, formatBool(result.ATRMatch))
+	}
+	if result.VolumeRatio != nil {
+		fmt.Printf("\tVolume: %.2fx trailing average (%s)\n", *result.VolumeRatio, formatBool(result.VolumeMatch))
+	}
+	if result.Breakout != nil {
+		fmt.Printf("\tBreakout level: %.4f (%s)\n", result.Breakout.Level, formatBool(result.Breakout.Match))
+	}
+	if result.ZScore != nil {
+		fmt.Printf("\tZ-score: %+.2f (%s)\n", *result.ZScore, formatBool(result.ZScoreMatch))
+	}
+	if result.RelativeStrength != nil {
+		fmt.Printf("\tRelative strength: %+.2f%% (%s)\n", *result.RelativeStrength, formatBool(result.RelativeStrengthMatch))
+	}
+	if result.WeekendGap != nil {
+		fmt.Printf("\tWeekend gap: %+.2f%% (%s)\n", *result.WeekendGap, formatBool(result.WeekendGapMatch))
+	}
+	if result.Donchian != nil {
+		fmt.Printf("\tDonchian channel position: %.2f%% (%s)\n", *result.Donchian, formatBool(result.DonchianMatch))
+	}
+	if result.EMASlope != nil {
+		fmt.Printf("\tEMA slope: %+.2f%% (%s)\n", *result.EMASlope, formatBool(result.EMASlopeMatch))
+	}
+	for _, gate := range result.Gates {
+		gateMomentum := math.NaN()
+		if gate.Momentum != nil {
+			gateMomentum = *gate.Momentum
+		}
+		fmt.Printf("\t%s %s momentum: %+.2f%% (%s)\n", gate.Symbol, gate.Offset, gateMomentum, formatBool(gate.Match))
+	}
+	if result.VWAPDeviation != nil {
+		fmt.Printf("\tVWAP deviation: %+.2f%% (%s)\n", *result.VWAPDeviation, formatBool(result.VWAPMatch))
+	}
+	if result.Pattern != nil {
+		if *result.Pattern != "" {
+			fmt.Printf("\tCandle pattern: %s (%s)\n", *result.Pattern, formatBool(true))
+		} else {
+			fmt.Printf("\tCandle pattern: none (%s)\n", formatBool(false))
+		}
+	}
+	if result.Score != nil {
+		fmt.Printf("\tMomentum score: %+.2f (%s)\n", *result.Score, formatBool(result.ScoreMatch))
+	}
+	if result.PercentileRank != nil {
+		fmt.Printf("\tMomentum percentile rank: %.1f%% (%s)\n", *result.PercentileRank, formatBool(result.PercentileMatch))
+	}
+	if result.RealizedVolatility != nil {
+		fmt.Printf("\tRealized volatility: %.1f%% (%s)\n", *result.RealizedVolatility, formatBool(result.RealizedVolatilityMatch))
+	}
+	if result.Correlation != nil {
+		fmt.Printf("\tCorrelation: %+.2f (%s)\n", *result.Correlation, formatBool(result.CorrelationMatch))
+	}
+	if result.ConditionMatch != nil {
+		fmt.Printf("\tCondition tree: %s\n", formatBool(*result.ConditionMatch))
+	}
+	if result.ExpressionMatch != nil {
+		fmt.Printf("\tExpression: %s\n", formatBool(*result.ExpressionMatch))
+	}
+	if result.QuoteVolume != nil {
+		fmt.Printf("\t24h quote volume: %.0f (%s)\n", *result.QuoteVolume, formatBool(result.LiquidityMatch))
+		fmt.Printf("\t24h trades: %d\n", *result.TradeCount)
+	}
+	if result.FundingRate != nil {
+		fmt.Printf("\tFunding rate: %+.4f%% (%s)\n", *result.FundingRate, formatBool(result.FundingMatch))
+	}
+	if result.OpenInterestChange != nil {
+		fmt.Printf("\tOpen interest change: %+.2f%% (%s)\n", *result.OpenInterestChange, formatBool(result.OpenInterestMatch))
+	}
+	if result.Position != nil {
+		fmt.Printf("\tOpen position: %s at %.4f since %s UTC\n", formatDecimal(result.Position.Quantity), result.Position.EntryPrice, commons.GetTimeString(result.Position.EntryTime))
+	}
+	if result.Suppressed {
+		fmt.Printf("\n\tAll conditions match, %s\n", yellow(fmt.Sprintf("signal suppressed by %s", result.SuppressedBy)))
+	} else if result.Signal && result.Position != nil {
+		fmt.Printf("\n\tAll conditions match, %s\n", yellow("position already open"))
+	} else if result.Signal {
+		fmt.Printf("\n\tAll conditions match, open \"%s\" position\n", sideString)
+	}
+	if result.Costs != nil {
+		fmt.Printf("\tEstimated round-trip costs: %.2f%%\n", *result.Costs)
+	}
+	if result.BreakEvenStop != nil {
+		fmt.Printf("\tMove stop to %.4f once price reaches %.4f\n", *result.BreakEvenStop, *result.BreakEvenTrigger)
+	}
+	for _, message := range result.Messages {
+		fmt.Printf("\t%s\n", message)
+	}
+	if len(result.Sparkline) > 0 {
+		fmt.Printf("\tLast %dh: %s (%.4f - %.4f)\n", sparklineHours, formatSparkline(result.Sparkline), slices.Min(result.Sparkline), slices.Max(result.Sparkline))
+	}
+	fmt.Printf("\n")
+}
+
+func (consoleRenderer) Close() {}
+
+func (r *jsonRenderer) Render(result EvaluationResult) {
+	r.results = append(r.results, result)
+}
+
+func (r *jsonRenderer) Close() {
+	data, err := json.MarshalIndent(r.results, "", "\t")
+	if err != nil {
+		commons.Fatalf("Failed to serialize evaluation results: %v", err)
+	}
+	fmt.Printf("%s\n", data)
+}
+
+func (r *markdownRenderer) Render(result EvaluationResult) {
+	r.results = append(r.results, result)
+}
+
+func (r *markdownRenderer) Close() {
+	fmt.Print(getMarkdown(r.results))
+}
+
+func getMarkdown(results []EvaluationResult) string {
+	var builder strings.Builder
+	builder.WriteString("| Strategy | Currency | Side | Price | Momentum | Weekday | Time | Momentum match | Signal |\n")
+	builder.WriteString("|---|---|---|---|---|---|---|---|---|\n")
+	formatMatch := func (match bool) string {
+		if match {
+			return "yes"
+		} else {
+			return "no"
+		}
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			fmt.Fprintf(&builder, "| %s | %s | | | | | | | error: %s |\n", result.Strategy, result.Currency, result.Error)
+			continue
+		}
+		momentum := "missing"
+		if result.Momentum != nil {
+			momentum = fmt.Sprintf("%+.2f%%", *result.Momentum)
+		}
+		signal := formatMatch(result.Signal)
+		if result.Suppressed {
+			signal = "suppressed"
+		} else if result.Signal && result.Position != nil {
+			signal = "position open"
+		}
+		fmt.Fprintf(
+			&builder,
+			"| %s | %s | %s | %.4f | %s | %s | %s | %s | %s |\n",
+			result.Strategy,
+			result.Currency,
+			result.getSide(),
+			result.CurrentPrice,
+			momentum,
+			formatMatch(result.WeekdayMatch),
+			formatMatch(result.TimeMatch),
+			formatMatch(result.MomentumMatch),
+			signal,
+		)
+	}
+	for _, result := range results {
+		for _, message := range result.Messages {
+			fmt.Fprintf(&builder, "\n* %s: %s", result.Strategy, message)
+		}
+	}
+	builder.WriteString("\n")
+	return builder.String()
+}
+
+func (r *webhookRenderer) Render(result EvaluationResult) {
+	r.results = append(r.results, result)
+}
+
+func (r *webhookRenderer) Close() {
+	payload := webhookPayload{
+		Text: getMarkdown(r.results),
+		Results: r.results,
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		commons.Fatalf("Failed to serialize webhook payload: %v", err)
+	}
+	response, err := http.Post(r.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		commons.Fatalf("Failed to post evaluation results to webhook: %v", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		commons.Fatalf("Webhook returned status %d", response.StatusCode)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	chartWidth = 800
+	chartHeight = 200
+	reportTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Backtest report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { padding: 0.25em 0.75em; border: 1px solid #ddd; text-align: right; }
+th { background-color: #f4f4f4; }
+svg { border: 1px solid #ddd; margin-bottom: 1em; }
+polyline { fill: none; stroke-width: 1.5; }
+.equity { stroke: #2060c0; }
+.drawdown { stroke: #c03030; }
+.axis { font-size: 10px; fill: #666; }
+</style>
+</head>
+<body>
+<h1>Backtest report</h1>
+<p>Period: {{.From}} - {{.To}}</p>
+{{range .Strategies}}
+<h2>{{.Name}} ({{.Currency}})</h2>
+<table>
+{{range .Metrics}}<tr><th>{{.Name}}</th><td>{{.Value}}</td></tr>
+{{end}}</table>
+<h3>Equity curve</h3>
+<svg width="{{$.Width}}" height="{{$.Height}}" viewBox="0 0 {{$.Width}} {{$.Height}}">
+<polyline class="equity" points="{{.Equity.Points}}"/>
+<text class="axis" x="4" y="12">{{.Equity.Maximum}}</text>
+<text class="axis" x="4" y="{{$.Height}}">{{.Equity.Minimum}}</text>
+</svg>
+<h3>Drawdown</h3>
+<svg width="{{$.Width}}" height="{{$.Height}}" viewBox="0 0 {{$.Width}} {{$.Height}}">
+<polyline class="drawdown" points="{{.Drawdown.Points}}"/>
+<text class="axis" x="4" y="12">{{.Drawdown.Maximum}}</text>
+<text class="axis" x="4" y="{{$.Height}}">{{.Drawdown.Minimum}}</text>
+</svg>
+<h3>Monthly returns</h3>
+<table>
+<tr><th>Year</th>{{range $.Months}}<th>{{.}}</th>{{end}}</tr>
+{{range .MonthlyReturns}}<tr><th>{{.Year}}</th>{{range .Months}}<td style="{{.Style}}">{{.Value}}</td>{{end}}</tr>
+{{end}}</table>
+<h3>Trades</h3>
+<table>
+<tr><th>Entry time</th><th>Exit time</th><th>Entry price</th><th>Exit price</th><th>Exit</th><th>Return</th></tr>
+{{range .Trades}}<tr><td>{{.EntryTime}}</td><td>{{.ExitTime}}</td><td>{{.EntryPrice}}</td><td>{{.ExitPrice}}</td><td>{{.ExitReason}}</td><td>{{.Returns}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`
+)
+
+type reportData struct {
+	From string
+	To string
+	Width int
+	Height int
+	Months []string
+	Strategies []reportStrategy
+}
+
+type reportStrategy struct {
+	Name string
+	Currency string
+	Metrics []reportMetric
+	Equity reportChart
+	Drawdown reportChart
+	MonthlyReturns []reportYear
+	Trades []reportTrade
+}
+
+type reportMetric struct {
+	Name string
+	Value string
+}
+
+type reportChart struct {
+	Points string
+	Minimum string
+	Maximum string
+}
+
+type reportYear struct {
+	Year int
+	Months []reportMonth
+}
+
+type reportMonth struct {
+	Value string
+	Style template.CSS
+}
+
+type reportTrade struct {
+	EntryTime string
+	ExitTime string
+	EntryPrice string
+	ExitPrice string
+	ExitReason string
+	Returns string
+}
+
+func writeReport(path string, results []backtestResult, from time.Time, to time.Time) {
+	data := reportData{
+		From: from.Format(time.DateOnly),
+		To: to.Format(time.DateOnly),
+		Width: chartWidth,
+		Height: chartHeight,
+	}
+	for month := time.January; month <= time.December; month++ {
+		data.Months = append(data.Months, month.String()[:3])
+	}
+	for i := range results {
+		data.Strategies = append(data.Strategies, results[i].getReportStrategy())
+	}
+	parsedTemplate := template.Must(template.New("report").Parse(reportTemplate))
+	file, err := os.Create(path)
+	if err != nil {
+		commons.Fatalf("Failed to create report %s: %v", path, err)
+	}
+	defer file.Close()
+	err = parsedTemplate.Execute(file, data)
+	if err != nil {
+		commons.Fatalf("Failed to write report %s: %v", path, err)
+	}
+	fmt.Printf("Wrote report to %s\n", path)
+}
+
+func (r *backtestResult) getEquitySamples() []equitySample {
+	equity := 1.0
+	samples := []equitySample{
+		{
+			timestamp: r.from,
+			equity: equity,
+		},
+	}
+	for _, trade := range r.trades {
+		equity *= 1.0 + trade.returns
+		sample := equitySample{
+			timestamp: trade.exitTime,
+			equity: equity,
+		}
+		samples = append(samples, sample)
+	}
+	return samples
+}
+
+func (r *backtestResult) getReportStrategy() reportStrategy {
+	formatPercentage := func (value float64) string {
+		return fmt.Sprintf("%+.2f%%", value * percent)
+	}
+	metrics := []reportMetric{
+		{"Trades", fmt.Sprintf("%d", len(r.trades))},
+		{"Win rate", formatPercentage(r.winRate())},
+		{"Mean return", formatPercentage(r.meanReturn())},
+		{"Total return", formatPercentage(r.totalReturn())},
+		{"Max drawdown", formatPercentage(r.maxDrawdown())},
+		{"Sharpe ratio", fmt.Sprintf("%.2f", r.sharpeRatio())},
+	}
+	samples := r.getEquitySamples()
+	equity := []float64{}
+	drawdown := []float64{}
+	peak := 0.0
+	for _, sample := range samples {
+		peak = math.Max(peak, sample.equity)
+		equity = append(equity, sample.equity)
+		drawdown = append(drawdown, sample.equity / peak - 1.0)
+	}
+	trades := []reportTrade{}
+	for _, trade := range r.trades {
+		row := reportTrade{
+			EntryTime: commons.GetTimeString(trade.entryTime),
+			ExitTime: commons.GetTimeString(trade.exitTime),
+			EntryPrice: fmt.Sprintf("%.4f", trade.entryPrice),
+			ExitPrice: fmt.Sprintf("%.4f", trade.exitPrice),
+			ExitReason: trade.exitReason,
+			Returns: formatPercentage(trade.returns),
+		}
+		trades = append(trades, row)
+	}
+	return reportStrategy{
+		Name: r.strategy.Name,
+		Currency: r.strategy.Currency,
+		Metrics: metrics,
+		Equity: getReportChart(samples, equity, r.from, r.to, "%.3f"),
+		Drawdown: getReportChart(samples, drawdown, r.from, r.to, "%.3f"),
+		MonthlyReturns: r.getMonthlyReturns(),
+		Trades: trades,
+	}
+}
+
+func getReportChart(samples []equitySample, values []float64, from time.Time, to time.Time, format string) reportChart {
+	minimum := math.Inf(1)
+	maximum := math.Inf(-1)
+	for _, value := range values {
+		minimum = math.Min(minimum, value)
+		maximum = math.Max(maximum, value)
+	}
+	valueRange := maximum - minimum
+	if valueRange == 0 {
+		valueRange = 1.0
+	}
+	timeRange := to.Sub(from).Seconds()
+	points := []string{}
+	for i, sample := range samples {
+		x := sample.timestamp.Sub(from).Seconds() / timeRange * chartWidth
+		x = math.Min(math.Max(x, 0), chartWidth)
+		y := chartHeight - (values[i] - minimum) / valueRange * chartHeight
+		points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+	}
+	return reportChart{
+		Points: strings.Join(points, " "),
+		Minimum: fmt.Sprintf(format, minimum),
+		Maximum: fmt.Sprintf(format, maximum),
+	}
+}
+
+// Trades are attributed to the month in which they were closed
+func (r *backtestResult) getMonthlyReturns() []reportYear {
+	years := []reportYear{}
+	for year := r.from.Year(); year <= r.to.Year(); year++ {
+		yearRow := reportYear{
+			Year: year,
+		}
+		for month := time.January; month <= time.December; month++ {
+			equity := 1.0
+			count := 0
+			for _, trade := range r.trades {
+				if trade.exitTime.Year() == year && trade.exitTime.Month() == month {
+					equity *= 1.0 + trade.returns
+					count++
+				}
+			}
+			cell := reportMonth{}
+			if count > 0 {
+				returns := equity - 1.0
+				cell.Value = fmt.Sprintf("%+.1f%%", returns * percent)
+				cell.Style = getHeatmapStyle(returns)
+			}
+			yearRow.Months = append(yearRow.Months, cell)
+		}
+		years = append(years, yearRow)
+	}
+	return years
+}
+
+func getHeatmapStyle(returns float64) template.CSS {
+	saturation := 0.1
+	intensity := math.Min(math.Abs(returns) / saturation, 1.0)
+	alpha := 0.15 + 0.75 * intensity
+	if returns >= 0 {
+		return template.CSS(fmt.Sprintf("background-color: rgba(40, 160, 60, %.2f)", alpha))
+	} else {
+		return template.CSS(fmt.Sprintf("background-color: rgba(200, 50, 50, %.2f)", alpha))
+	}
+}
\ No newline at end of file
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	defaultKillSwitchFile = "killSwitch"
+)
+
+type dailyPnL map[time.Time]float64
+
+type RiskConfiguration struct {
+	// Loss across all strategies within a trading day in the quote currency, unlike the percentage of the strategies
+	DailyLossAmount *float64 `yaml:"dailyLossAmount"`
+	KillSwitch string `yaml:"killSwitch"`
+}
+
+var (
+	killSwitch bool
+	suspensionReason string
+)
+
+func newDailyPnL() dailyPnL {
+	return dailyPnL{}
+}
+
+// Trading days start at the configured rollover hour rather than at midnight UTC
+func getTradingDay(timestamp time.Time) time.Time {
+	rollover := time.Duration(configuration.RolloverHour) * time.Hour
+	shifted := timestamp.UTC().Add(-rollover)
+	day := time.Date(shifted.Year(), shifted.Month(), shifted.Day(), 0, 0, 0, 0, time.UTC)
+	return day.Add(rollover)
+}
+
+func (d dailyPnL) add(timestamp time.Time, returns float64) {
+	day := getTradingDay(timestamp)
+	d[day] += returns
+}
+
+func (d dailyPnL) get(timestamp time.Time) float64 {
+	day := getTradingDay(timestamp)
+	return d[day]
+}
+
+func (s *Strategy) isMuted(pnl dailyPnL, timestamp time.Time) bool {
+	if s.DailyLossPercent == nil {
+		return false
+	}
+	return pnl.get(timestamp) * percent <= -*s.DailyLossPercent
+}
+
+func (c *RiskConfiguration) validate() {
+	if c.DailyLossAmount != nil && *c.DailyLossAmount <= 0 {
+		commons.Fatalf("Invalid global daily loss limit")
+	}
+}
+
+func getKillSwitchPath() string {
+	if configuration.Risk != nil && configuration.Risk.KillSwitch != "" {
+		return configuration.Risk.KillSwitch
+	}
+	return filepath.Join(dataDirectory, defaultKillSwitchFile)
+}
+
+// The guard is evaluated once per run so that all strategies see the same decision
+func checkRiskGuard() {
+	suspensionReason = getSuspensionReason()
+	if suspensionReason != "" {
+		fmt.Fprintf(statusOutput, "Trading suspended: %s\n\n", suspensionReason)
+	}
+}
+
+func getSuspensionReason() string {
+	if killSwitch {
+		return "kill switch flag set"
+	}
+	path := getKillSwitchPath()
+	_, err := os.Stat(path)
+	if err == nil {
+		return fmt.Sprintf("kill switch file %s present", path)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Sprintf("unable to check kill switch file %s: %v", path, err)
+	}
+	if configuration.Risk == nil || configuration.Risk.DailyLossAmount == nil {
+		return ""
+	}
+	pnl, err := getGlobalDailyPnL()
+	if err != nil {
+		return fmt.Sprintf("unable to determine daily P&L: %v", err)
+	}
+	limit := *configuration.Risk.DailyLossAmount
+	if pnl <= -limit {
+		return fmt.Sprintf("daily P&L of %+.2f exceeds loss limit of %.2f", pnl, limit)
+	}
+	return ""
+}
+
+// Open positions contribute their entire unrealized P&L, even if they were opened on a previous trading day
+func getGlobalDailyPnL() (float64, error) {
+	state := getState()
+	today := getTradingDay(time.Now().UTC())
+	pnl := 0.0
+	for _, closed := range state.closed {
+		if closed.ExitPrice > 0 && getTradingDay(closed.ExitTime).Equal(today) {
+			pnl += closed.getPnL(closed.ExitPrice)
+		}
+	}
+	for _, p := range state.positions {
+		price, err := getLastPrice(p.Currency)
+		if err != nil {
+			return 0, err
+		}
+		pnl += p.getPnL(price)
+	}
+	return pnl, nil
+}
+
+func getLastPrice(symbol string) (float64, error) {
+	ticker, err := getTicker(symbol)
+	if err != nil {
+		return 0, err
+	}
+	price, err := strconv.ParseFloat(ticker.LastPrice, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid last price: %s", ticker.LastPrice)
+	}
+	return price, nil
+}
+
+func (p *position) getPnL(price float64) float64 {
+	if p.Up {
+		return (price - p.EntryPrice) * p.Quantity
+	} else {
+		return (p.EntryPrice - price) * p.Quantity
+	}
+}
\ No newline at end of file
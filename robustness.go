@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/encratite/commons"
+	"github.com/fatih/color"
+)
+
+func runRobustness(arguments []string) {
+	flags := flag.NewFlagSet("robustness", flag.ExitOnError)
+	strategyFilter := flags.String("strategy", "", "Restrict the robustness test to strategies whose names match this filter")
+	fromString := flags.String("from", "", "Start date of the backtests (YYYY-MM-DD)")
+	toString := flags.String("to", "", "End date of the backtests (YYYY-MM-DD), defaults to today")
+	candles := flags.Int("candles", 2, "Maximum number of candles by which entry times are shifted in either direction")
+	addBacktestFlags(flags)
+	flags.Parse(arguments)
+	from, to := parseDateRange(*fromString, *toString)
+	if *candles < 1 || time.Duration(*candles) * candleInterval >= time.Hour {
+		commons.Fatalf("Invalid number of candles: %d", *candles)
+	}
+	loadConfiguration()
+	fmt.Printf("\n")
+	for _, result := range backtestStrategies(*strategyFilter, from, to) {
+		printRobustness(result, *candles)
+	}
+}
+
+func printRobustness(baseline backtestResult, candles int) {
+	strategy := baseline.strategy
+	fmt.Printf("%s:\n", strategy.Name)
+	fmt.Printf("\t%-8s %8s %14s %14s %8s\n", "Shift", "Trades", "Mean return", "Total return", "Sharpe")
+	totalReturns := []float64{}
+	for i := -candles; i <= candles; i++ {
+		shift := time.Duration(i) * candleInterval
+		result := baseline
+		if i != 0 {
+			result = strategy.backtestShifted(baseline.records, baseline.from, baseline.to, shift)
+		}
+		shiftString := fmt.Sprintf("%+dm", int(shift.Minutes()))
+		fmt.Printf(
+			"\t%-8s %8d %13.2f%% %13.2f%% %8.2f\n",
+			shiftString,
+			len(result.trades),
+			result.meanReturn() * percent,
+			result.totalReturn() * percent,
+			result.sharpeRatio(),
+		)
+		totalReturns = append(totalReturns, result.totalReturn())
+	}
+	minimum := math.Inf(1)
+	maximum := math.Inf(-1)
+	for _, totalReturn := range totalReturns {
+		minimum = math.Min(minimum, totalReturn)
+		maximum = math.Max(maximum, totalReturn)
+	}
+	fmt.Printf("\tTotal return range: %+.2f%% to %+.2f%%\n", minimum * percent, maximum * percent)
+	fmt.Printf("\tTotal return deviation: %.2f%%\n", getStandardDeviation(totalReturns) * percent)
+	baselineReturn := baseline.totalReturn()
+	if baselineReturn > 0 && minimum <= 0 {
+		red := color.New(color.FgRed).SprintFunc()
+		fmt.Printf("\t%s\n", red("Returns depend on exact candle alignment"))
+	}
+	fmt.Printf("\n")
+}
\ No newline at end of file
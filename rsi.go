@@ -0,0 +1,61 @@
+package main
+
+import (
+	"github.com/encratite/commons"
+)
+
+type RSIConfiguration struct {
+	Period int `yaml:"period"`
+	Overbought *float64 `yaml:"overbought"`
+	Oversold *float64 `yaml:"oversold"`
+}
+
+func (c *RSIConfiguration) validate(strategy string) {
+	if c.Period <= 0 {
+		commons.Fatalf("Invalid RSI period for strategy %s", strategy)
+	}
+	if c.Overbought == nil && c.Oversold == nil {
+		commons.Fatalf("Missing RSI thresholds for strategy %s", strategy)
+	}
+	if c.Overbought != nil && (*c.Overbought <= 0 || *c.Overbought >= percent) {
+		commons.Fatalf("Invalid overbought RSI threshold for strategy %s", strategy)
+	}
+	if c.Oversold != nil && (*c.Oversold <= 0 || *c.Oversold >= percent) {
+		commons.Fatalf("Invalid oversold RSI threshold for strategy %s", strategy)
+	}
+	if c.Overbought != nil && c.Oversold != nil && *c.Oversold >= *c.Overbought {
+		commons.Fatalf("Oversold RSI threshold must be below the overbought threshold for strategy %s", strategy)
+	}
+}
+
+// Entries require the RSI to be below the overbought threshold and above the oversold threshold
+func (c *RSIConfiguration) matches(rsi float64) bool {
+	if c.Overbought != nil && rsi >= *c.Overbought {
+		return false
+	}
+	if c.Oversold != nil && rsi <= *c.Oversold {
+		return false
+	}
+	return true
+}
+
+// Uses simple averages of the gains and losses rather than Wilder's smoothing so that the value only depends on the last period bars
+func getRSI(bars []ohlcRecord, period int) (float64, bool) {
+	if len(bars) < period + 1 {
+		return 0, false
+	}
+	gains := 0.0
+	losses := 0.0
+	for i := len(bars) - period; i < len(bars); i++ {
+		change := bars[i].close - bars[i - 1].close
+		if change > 0 {
+			gains += change
+		} else {
+			losses -= change
+		}
+	}
+	if gains + losses == 0 {
+		return percent / 2.0, true
+	}
+	return percent * gains / (gains + losses), true
+}
\ No newline at end of file
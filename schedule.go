@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+// Standard five field cron expression consisting of the minute, hour, day of month, month and weekday
+type cronSchedule struct {
+	minutes []bool
+	hours []bool
+	days []bool
+	months []bool
+	weekdays []bool
+	anyDay bool
+	anyWeekday bool
+}
+
+func (s *Strategy) compileSchedule() {
+	if s.Schedule == "" {
+		return
+	}
+	if len(s.Weekdays) > 0 || len(s.Times) > 0 || len(s.Sessions) > 0 || len(s.MarketTimes) > 0 || len(s.TimeRanges) > 0 {
+		commons.Fatalf("Strategy %s must specify either a schedule or weekdays and times", s.Name)
+	}
+	schedule, err := parseCronSchedule(s.Schedule)
+	if err != nil {
+		commons.Fatalf("Invalid schedule for strategy %s: %v", s.Name, err)
+	}
+	for minute, enabled := range schedule.minutes {
+		if enabled && time.Duration(minute) * time.Minute % candleInterval != 0 {
+			commons.Fatalf("The minutes of the schedule of strategy %s must be multiples of %d", s.Name, int(candleInterval.Minutes()))
+		}
+	}
+	s.schedule = schedule
+}
+
+func parseCronSchedule(expression string) (*cronSchedule, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected five fields but got %d", len(fields))
+	}
+	schedule := &cronSchedule{
+		anyDay: fields[2] == "*",
+		anyWeekday: fields[4] == "*",
+	}
+	var err error
+	schedule.minutes, err = parseCronField(fields[0], 0, 59)
+	if err != nil {
+		return nil, err
+	}
+	schedule.hours, err = parseCronField(fields[1], 0, 23)
+	if err != nil {
+		return nil, err
+	}
+	schedule.days, err = parseCronField(fields[2], 1, 31)
+	if err != nil {
+		return nil, err
+	}
+	schedule.months, err = parseCronField(fields[3], 1, 12)
+	if err != nil {
+		return nil, err
+	}
+	schedule.weekdays, err = parseCronField(fields[4], 0, 7)
+	if err != nil {
+		return nil, err
+	}
+	// Both 0 and 7 refer to Sunday
+	if schedule.weekdays[7] {
+		schedule.weekdays[0] = true
+	}
+	return schedule, nil
+}
+
+// Fields are comma-separated lists of values, ranges and wildcards with optional steps, e.g. "*/4" or "1-5"
+func parseCronField(field string, minimum int, maximum int) ([]bool, error) {
+	values := make([]bool, maximum + 1)
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		rangeString, stepString, hasStep := strings.Cut(part, "/")
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepString)
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step: %s", part)
+			}
+		}
+		from := minimum
+		to := maximum
+		if rangeString != "*" {
+			fromString, toString, isRange := strings.Cut(rangeString, "-")
+			var err error
+			from, err = strconv.Atoi(fromString)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value: %s", part)
+			}
+			to = from
+			if isRange {
+				to, err = strconv.Atoi(toString)
+				if err != nil {
+					return nil, fmt.Errorf("invalid range: %s", part)
+				}
+			} else if hasStep {
+				to = maximum
+			}
+		}
+		if from < minimum || to > maximum || from > to {
+			return nil, fmt.Errorf("value out of range: %s", part)
+		}
+		for value := from; value <= to; value += step {
+			values[value] = true
+		}
+	}
+	return values, nil
+}
+
+// Like in cron, a day matches either of the day of month and weekday fields if both of them are restricted
+func (c *cronSchedule) matchesDay(day time.Time) bool {
+	if !c.months[day.Month()] {
+		return false
+	}
+	dayMatch := c.days[day.Day()]
+	weekdayMatch := c.weekdays[day.Weekday()]
+	switch {
+	case c.anyDay && c.anyWeekday:
+		return true
+	case c.anyDay:
+		return weekdayMatch
+	case c.anyWeekday:
+		return dayMatch
+	default:
+		return dayMatch || weekdayMatch
+	}
+}
+
+func (c *cronSchedule) getTimesOfDay() []time.Duration {
+	times := []time.Duration{}
+	for hour, hourEnabled := range c.hours {
+		for minute, minuteEnabled := range c.minutes {
+			if hourEnabled && minuteEnabled {
+				times = append(times, time.Duration(hour) * time.Hour + time.Duration(minute) * time.Minute)
+			}
+		}
+	}
+	return times
+}
\ No newline at end of file
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func getEnabled(values []bool) []int {
+	enabled := []int{}
+	for value, isEnabled := range values {
+		if isEnabled {
+			enabled = append(enabled, value)
+		}
+	}
+	return enabled
+}
+
+func TestParseCronField(t *testing.T) {
+	tests := []struct {
+		field string
+		minimum int
+		maximum int
+		expected []int
+	}{
+		{"*", 0, 6, []int{0, 1, 2, 3, 4, 5, 6}},
+		{"5", 0, 59, []int{5}},
+		{"1-5", 0, 7, []int{1, 2, 3, 4, 5}},
+		{"*/15", 0, 59, []int{0, 15, 30, 45}},
+		{"10/20", 0, 59, []int{10, 30, 50}},
+		{"8-18/4", 0, 23, []int{8, 12, 16}},
+		{"1,15,28", 1, 31, []int{1, 15, 28}},
+		{"0-2,20-22", 0, 23, []int{0, 1, 2, 20, 21, 22}},
+		{"1-3,*/6", 1, 12, []int{1, 2, 3, 7}},
+	}
+	for _, test := range tests {
+		values, err := parseCronField(test.field, test.minimum, test.maximum)
+		if err != nil {
+			t.Errorf("failed to parse %q: %v", test.field, err)
+			continue
+		}
+		enabled := getEnabled(values)
+		if !slices.Equal(enabled, test.expected) {
+			t.Errorf("expected %q to enable %v, got %v", test.field, test.expected, enabled)
+		}
+	}
+}
+
+func TestParseCronScheduleInvalid(t *testing.T) {
+	expressions := []string{
+		"",
+		"0 12 * *",
+		"0 12 * * * *",
+		"60 12 * * *",
+		"0 24 * * *",
+		"0 12 0 * *",
+		"0 12 32 * *",
+		"0 12 * 13 *",
+		"0 12 * * 8",
+		"0 12 * * 5-1",
+		"*/0 12 * * *",
+		"*/x 12 * * *",
+		"a 12 * * *",
+		"0 1-x * * *",
+		"0 12 * * 1,",
+		"-5 12 * * *",
+	}
+	for _, expression := range expressions {
+		_, err := parseCronSchedule(expression)
+		if err == nil {
+			t.Errorf("expected %q to be rejected", expression)
+		}
+	}
+}
+
+func TestCronScheduleMatchesDay(t *testing.T) {
+	// 2025-03-03 is a Monday
+	monday := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expression string
+		day time.Time
+		match bool
+	}{
+		{"0 12 * * *", monday, true},
+		{"0 12 * * 1", monday, true},
+		{"0 12 * * 2-5", monday, false},
+		{"0 12 3 * *", monday, true},
+		{"0 12 4 * *", monday, false},
+		// Either field matches if both of them are restricted
+		{"0 12 4 * 1", monday, true},
+		{"0 12 3 * 5", monday, true},
+		{"0 12 4 * 5", monday, false},
+		{"0 12 * 4 *", monday, false},
+		{"0 12 * 3 1", monday, true},
+		// Sunday may be specified as either 0 or 7
+		{"0 12 * * 7", monday.AddDate(0, 0, 6), true},
+		{"0 12 * * 0", monday.AddDate(0, 0, 6), true},
+		{"0 12 * * 1-5", monday.AddDate(0, 0, 6), false},
+	}
+	for _, test := range tests {
+		schedule, err := parseCronSchedule(test.expression)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", test.expression, err)
+		}
+		match := schedule.matchesDay(test.day)
+		if match != test.match {
+			t.Errorf("expected %q to match %s: %t", test.expression, test.day.Format(time.DateOnly), test.match)
+		}
+	}
+}
+
+func TestScheduleNextEntry(t *testing.T) {
+	monday := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expression string
+		now time.Time
+		expected time.Time
+	}{
+		{"0 12 * * *", monday.Add(11 * time.Hour), monday.Add(12 * time.Hour)},
+		{"0 12 * * *", monday.Add(12 * time.Hour), monday.AddDate(0, 0, 1).Add(12 * time.Hour)},
+		{"*/30 9-10 * * 1-5", monday.Add(9 * time.Hour + 10 * time.Minute), monday.Add(9 * time.Hour + 30 * time.Minute)},
+		{"*/30 9-10 * * 1-5", monday.Add(10 * time.Hour + 30 * time.Minute), monday.AddDate(0, 0, 1).Add(9 * time.Hour)},
+		{"0 8 * * 6", monday, monday.AddDate(0, 0, 5).Add(8 * time.Hour)},
+		{"15 0 1 * *", monday, time.Date(2025, 4, 1, 0, 15, 0, 0, time.UTC)},
+		{"0 12 15 * 5", monday, time.Date(2025, 3, 7, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		s := &Strategy{
+			Name: "schedule",
+			Schedule: test.expression,
+		}
+		s.compileSchedule()
+		entryTime, exists := s.getNextEntry(test.now)
+		if !exists || !entryTime.Equal(test.expected) {
+			t.Errorf("expected the next entry of %q after %s to be %s, got %s", test.expression, test.now, test.expected, entryTime)
+		}
+	}
+}
\ No newline at end of file
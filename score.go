@@ -0,0 +1,60 @@
+package main
+
+import (
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+type ScoreConfiguration struct {
+	Horizons []ScoreHorizon `yaml:"horizons"`
+	GreaterThan *float64 `yaml:"greaterThan"`
+	LessThan *float64 `yaml:"lessThan"`
+}
+
+type ScoreHorizon struct {
+	Offset int `yaml:"offset"`
+	Weight float64 `yaml:"weight"`
+}
+
+func (c *ScoreConfiguration) validate(strategy string) {
+	if len(c.Horizons) == 0 {
+		commons.Fatalf("Missing momentum score horizons for strategy %s", strategy)
+	}
+	for _, horizon := range c.Horizons {
+		if horizon.Offset <= 0 {
+			commons.Fatalf("Invalid momentum score horizon for strategy %s: %d", strategy, horizon.Offset)
+		}
+	}
+	if c.GreaterThan == nil && c.LessThan == nil {
+		commons.Fatalf("Missing momentum score threshold for strategy %s", strategy)
+	}
+}
+
+func (c *ScoreConfiguration) getLookback() time.Duration {
+	hours := 0
+	for _, horizon := range c.Horizons {
+		hours = max(hours, horizon.Offset)
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// The score is the weighted sum of the rates of change in percent over each of the horizons
+func (c *ScoreConfiguration) getScore(records []ohlcRecord, latestIndex int, entryTime time.Time) (float64, bool, bool) {
+	score := 0.0
+	for _, horizon := range c.Horizons {
+		momentum, ok := getAnchoredMomentum(records, latestIndex, entryTime, time.Duration(horizon.Offset) * time.Hour)
+		if !ok {
+			return 0, false, false
+		}
+		score += horizon.Weight * momentum
+	}
+	match := true
+	if c.GreaterThan != nil {
+		match = match && score > *c.GreaterThan
+	}
+	if c.LessThan != nil {
+		match = match && score < *c.LessThan
+	}
+	return score, match, true
+}
\ No newline at end of file
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	defaultServeAddress = "127.0.0.1:8080"
+	recentSignalLimit = 20
+)
+
+type serveStrategy struct {
+	Name string `json:"name"`
+	Currency string `json:"currency"`
+	Side string `json:"side"`
+	NextEntry *time.Time `json:"nextEntry,omitempty"`
+	Momentum *float64 `json:"momentum,omitempty"`
+	MomentumMatch bool `json:"momentumMatch"`
+	GreaterThan *float64 `json:"greaterThan,omitempty"`
+	LessThan *float64 `json:"lessThan,omitempty"`
+	Signal bool `json:"signal"`
+	Suppressed bool `json:"suppressed"`
+	Error string `json:"error,omitempty"`
+}
+
+type serveStatus struct {
+	Time time.Time `json:"time"`
+	Updated *time.Time `json:"updated,omitempty"`
+	Strategies []serveStrategy `json:"strategies"`
+	Signals []event `json:"signals"`
+	Positions []position `json:"positions"`
+}
+
+type server struct {
+	filter string
+	lock sync.Mutex
+	rows []dashboardRow
+	updated time.Time
+}
+
+func runServe(arguments []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	address := flags.String("address", defaultServeAddress, "Address the web interface listens on")
+	strategyFilter := flags.String("strategy", "", "Restrict the web interface to strategies whose names match this filter")
+	refresh := flags.Duration("refresh", defaultDashboardRefresh, "Interval between evaluations of the strategies")
+	flags.Parse(arguments)
+	if *refresh <= 0 {
+		commons.Fatalf("Invalid refresh interval: %s", *refresh)
+	}
+	loadConfiguration()
+	statusOutput = io.Discard
+	s := &server{
+		filter: *strategyFilter,
+	}
+	// The rows are previewed like on the dashboard so that the web interface never records signals or triggers hooks and notifications
+	go func () {
+		for {
+			rows := getDashboardRows(s.filter)
+			s.lock.Lock()
+			s.rows = rows
+			s.updated = time.Now().UTC()
+			s.lock.Unlock()
+			time.Sleep(*refresh)
+		}
+	}()
+	http.HandleFunc("/", s.handleIndex)
+	http.HandleFunc("/api/status", s.handleStatus)
+	fmt.Printf("Serving web interface on http://%s\n", *address)
+	err := http.ListenAndServe(*address, nil)
+	if err != nil {
+		commons.Fatalf("Failed to serve web interface: %v", err)
+	}
+}
+
+func (s *server) handleIndex(writer http.ResponseWriter, request *http.Request) {
+	if request.URL.Path != "/" {
+		http.NotFound(writer, request)
+		return
+	}
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	io.WriteString(writer, serveIndex)
+}
+
+func (s *server) handleStatus(writer http.ResponseWriter, request *http.Request) {
+	data, err := json.Marshal(s.getStatus())
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Write(data)
+}
+
+// Signals and positions are read from the event log on every request so that fills of other processes show up as well
+func (s *server) getStatus() serveStatus {
+	now := time.Now().UTC()
+	s.lock.Lock()
+	rows := s.rows
+	updated := s.updated
+	s.lock.Unlock()
+	status := serveStatus{
+		Time: now,
+		Strategies: []serveStrategy{},
+		Signals: []event{},
+		Positions: []position{},
+	}
+	if !updated.IsZero() {
+		status.Updated = &updated
+	}
+	for _, row := range rows {
+		status.Strategies = append(status.Strategies, row.getServeStrategy(now))
+	}
+	events := loadEvents()
+	for i := len(events) - 1; i >= 0 && len(status.Signals) < recentSignalLimit; i-- {
+		e := events[i]
+		if e.Type == eventSignal && s.matchesFilter(e.Strategy) {
+			status.Signals = append(status.Signals, e)
+		}
+	}
+	state := replayEvents(events, time.Time{})
+	for _, p := range state.positions {
+		if s.matchesFilter(p.Strategy) {
+			status.Positions = append(status.Positions, *p)
+		}
+	}
+	sort.Slice(status.Positions, func (i, j int) bool {
+		return status.Positions[i].Strategy < status.Positions[j].Strategy
+	})
+	return status
+}
+
+func (s *server) matchesFilter(strategy string) bool {
+	return s.filter == "" || strings.Contains(strategy, s.filter)
+}
+
+func (r *dashboardRow) getServeStrategy(now time.Time) serveStrategy {
+	s := r.strategy
+	output := serveStrategy{
+		Name: s.Name,
+		Currency: s.Currency,
+		Side: "Down",
+		Momentum: r.momentum,
+		GreaterThan: s.GreaterThan,
+		LessThan: s.LessThan,
+		Signal: r.signal,
+		Suppressed: r.suppressed,
+	}
+	if s.Up {
+		output.Side = "Up"
+	}
+	entryTime := r.entryTime
+	if !entryTime.IsZero() && !entryTime.After(now) {
+		entryTime, _ = s.getNextEntry(now)
+	}
+	if !entryTime.IsZero() {
+		output.NextEntry = &entryTime
+	}
+	if r.momentum != nil {
+		output.MomentumMatch = s.getMomentumMatch(*r.momentum)
+	}
+	if r.err != nil {
+		output.Error = r.err.Error()
+	}
+	return output
+}
+
+const serveIndex = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>coinage</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { padding: 4px 12px; text-align: left; border-bottom: 1px solid #ddd; }
+th { background: #f4f4f4; }
+tr.signal td { background: #c8f7c5; font-weight: bold; }
+.match { color: #1a7f37; }
+.mismatch { color: #cf222e; }
+.suppressed { color: #9a6700; }
+.error { color: #cf222e; }
+#updated { color: #666; }
+</style>
+</head>
+<body>
+<h1>coinage</h1>
+<p id="updated">Loading...</p>
+<h2>Strategies</h2>
+<table>
+<thead><tr><th>Strategy</th><th>Currency</th><th>Side</th><th>Next entry</th><th>In</th><th>Momentum</th><th>Thresholds</th><th>Signal</th></tr></thead>
+<tbody id="strategies"></tbody>
+</table>
+<h2>Open positions</h2>
+<table>
+<thead><tr><th>Strategy</th><th>Currency</th><th>Side</th><th>Quantity</th><th>Entry price</th><th>Since</th></tr></thead>
+<tbody id="positions"></tbody>
+</table>
+<h2>Recent signals</h2>
+<table>
+<thead><tr><th>Time</th><th>Strategy</th><th>Currency</th><th>Side</th><th>Price</th><th>Momentum</th><th>Reason</th></tr></thead>
+<tbody id="signals"></tbody>
+</table>
+<script>
+let latest = null;
+
+function cell(text, className) {
+	const td = document.createElement("td");
+	td.textContent = text;
+	if (className) {
+		td.className = className;
+	}
+	return td;
+}
+
+function formatPercent(value) {
+	return (value >= 0 ? "+" : "") + value.toFixed(2) + "%";
+}
+
+function formatTime(value) {
+	return new Date(value).toISOString().replace("T", " ").substring(0, 19) + " UTC";
+}
+
+function formatCountdown(milliseconds) {
+	const seconds = Math.max(Math.floor(milliseconds / 1000), 0);
+	const days = Math.floor(seconds / 86400);
+	const pad = value => String(value).padStart(2, "0");
+	const hours = pad(Math.floor(seconds / 3600) % 24);
+	const minutes = pad(Math.floor(seconds / 60) % 60);
+	if (days > 0) {
+		return days + "d " + hours + "h " + minutes + "m";
+	}
+	return hours + ":" + minutes + ":" + pad(seconds % 60);
+}
+
+function replaceRows(id, rows) {
+	const body = document.getElementById(id);
+	body.replaceChildren(...rows);
+}
+
+function render() {
+	if (latest === null) {
+		return;
+	}
+	const now = Date.now();
+	document.getElementById("updated").textContent = latest.updated ? "Updated at " + formatTime(latest.updated) : "Evaluating strategies...";
+	replaceRows("strategies", latest.strategies.map(strategy => {
+		const tr = document.createElement("tr");
+		if (strategy.signal) {
+			tr.className = "signal";
+		}
+		const thresholds = [];
+		if (strategy.greaterThan !== undefined) {
+			thresholds.push("> " + formatPercent(strategy.greaterThan));
+		}
+		if (strategy.lessThan !== undefined) {
+			thresholds.push("< " + formatPercent(strategy.lessThan));
+		}
+		let signal = "";
+		let signalClass = "";
+		if (strategy.error) {
+			signal = "error: " + strategy.error;
+			signalClass = "error";
+		} else if (strategy.signal) {
+			signal = "SIGNAL";
+		} else if (strategy.suppressed) {
+			signal = "suppressed";
+			signalClass = "suppressed";
+		}
+		tr.append(
+			cell(strategy.name),
+			cell(strategy.currency),
+			cell(strategy.side),
+			cell(strategy.nextEntry ? formatTime(strategy.nextEntry) : "none"),
+			cell(strategy.nextEntry ? formatCountdown(new Date(strategy.nextEntry) - now) : ""),
+			cell(strategy.momentum !== undefined ? formatPercent(strategy.momentum) : "", strategy.momentum === undefined ? "" : (strategy.momentumMatch ? "match" : "mismatch")),
+			cell(thresholds.join(", ")),
+			cell(signal, signalClass)
+		);
+		return tr;
+	}));
+	replaceRows("positions", latest.positions.map(position => {
+		const tr = document.createElement("tr");
+		tr.append(
+			cell(position.strategy),
+			cell(position.currency),
+			cell(position.up ? "Up" : "Down"),
+			cell(String(position.quantity)),
+			cell(position.entryPrice.toFixed(4)),
+			cell(formatTime(position.entryTime))
+		);
+		return tr;
+	}));
+	replaceRows("signals", latest.signals.map(signal => {
+		const tr = document.createElement("tr");
+		tr.append(
+			cell(formatTime(signal.time)),
+			cell(signal.strategy),
+			cell(signal.currency),
+			cell(signal.up ? "Up" : "Down"),
+			cell(signal.price ? signal.price.toFixed(4) : ""),
+			cell(signal.momentum !== undefined ? formatPercent(signal.momentum) : ""),
+			cell(signal.reason || "")
+		);
+		return tr;
+	}));
+}
+
+async function poll() {
+	try {
+		const response = await fetch("/api/status");
+		if (response.ok) {
+			latest = await response.json();
+			render();
+		}
+	} catch (error) {
+		document.getElementById("updated").textContent = "Failed to fetch status: " + error;
+	}
+}
+
+poll();
+setInterval(poll, 5000);
+setInterval(render, 1000);
+</script>
+</body>
+</html>
+`
\ No newline at end of file
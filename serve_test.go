@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestServeStatusIsReadOnly(t *testing.T) {
+	setupEventLog(t)
+	configuration = &Configuration{}
+	signalTime := time.Date(2025, 3, 3, 14, 0, 0, 0, time.UTC)
+	appendEvent(event{
+		Time: signalTime,
+		Type: eventSignal,
+		Strategy: "btc-up",
+		Currency: "BTCUSDT",
+		Up: true,
+		Price: 100,
+		EntryTime: signalTime,
+	})
+	appendEvent(event{
+		Time: signalTime,
+		Type: eventPositionOpened,
+		Strategy: "btc-up",
+		Currency: "BTCUSDT",
+		Up: true,
+		Price: 100,
+		Quantity: 1,
+	})
+	appendEvent(event{
+		Time: signalTime,
+		Type: eventSignal,
+		Strategy: "eth-down",
+		Currency: "ETHUSDT",
+		EntryTime: signalTime,
+	})
+	path := filepath.Join(dataDirectory, eventsFile)
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read event log: %v", err)
+	}
+	s := &server{
+		filter: "btc",
+		rows: []dashboardRow{
+			{strategy: &Strategy{Name: "btc-up", Currency: "BTCUSDT", Up: true}, signal: true},
+		},
+		updated: signalTime,
+	}
+	// Repeated polls must not change the event log
+	for range 3 {
+		status := s.getStatus()
+		if len(status.Strategies) != 1 || !status.Strategies[0].Signal {
+			t.Fatalf("unexpected strategies: %+v", status.Strategies)
+		}
+		if len(status.Signals) != 1 || status.Signals[0].Strategy != "btc-up" {
+			t.Fatalf("unexpected signals: %+v", status.Signals)
+		}
+		if len(status.Positions) != 1 || status.Positions[0].Quantity != 1 {
+			t.Fatalf("unexpected positions: %+v", status.Positions)
+		}
+	}
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read event log: %v", err)
+	}
+	if string(before) != string(after) {
+		t.Errorf("polling the status modified the event log")
+	}
+}
\ No newline at end of file
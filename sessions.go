@@ -0,0 +1,69 @@
+package main
+
+import (
+	"slices"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+type SessionConfiguration struct {
+	Start int `yaml:"start"`
+	End int `yaml:"end"`
+}
+
+var defaultSessions = map[string]SessionConfiguration{
+	"asia": {Start: 0, End: 8},
+	"europe": {Start: 7, End: 16},
+	"us": {Start: 13, End: 21},
+}
+
+// Sessions from the configuration override the default ones with the same name
+func (c *Configuration) getSessions() map[string]SessionConfiguration {
+	sessions := map[string]SessionConfiguration{}
+	for name, session := range defaultSessions {
+		sessions[name] = session
+	}
+	for name, session := range c.Sessions {
+		if session.Start < 0 || session.Start > 23 || session.End < 0 || session.End > 24 || session.Start == session.End {
+			commons.Fatalf("Invalid UTC range for session %s", name)
+		}
+		sessions[name] = session
+	}
+	return sessions
+}
+
+// Sessions cover the entry times from their start up to but not including their end and wrap around midnight if the start follows the end
+func (c *SessionConfiguration) getHours() []int {
+	hours := []int{}
+	for hour := c.Start; hour != c.End; hour = (hour + 1) % 24 {
+		hours = append(hours, hour)
+		if c.End == 24 && hour == 23 {
+			break
+		}
+	}
+	return hours
+}
+
+// The entry times of the sessions of a strategy are added to its explicit times
+func (s *Strategy) normalizeSessions(sessions map[string]SessionConfiguration) {
+	hours := []int{}
+	for _, t := range s.Times {
+		hours = append(hours, int(t.Hours()))
+	}
+	for _, name := range s.Sessions {
+		session, exists := sessions[name]
+		if !exists {
+			commons.Fatalf("Unknown session in strategy %s: %s", s.Name, name)
+		}
+		for _, hour := range session.getHours() {
+			if !slices.Contains(hours, hour) {
+				hours = append(hours, hour)
+				s.Times = append(s.Times, commons.SerializableDuration{Duration: time.Duration(hour) * time.Hour})
+			}
+		}
+	}
+	slices.SortFunc(s.Times, func (a, b commons.SerializableDuration) int {
+		return int(a.Duration - b.Duration)
+	})
+}
\ No newline at end of file
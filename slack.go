@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"text/template"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	slackThreadsFile = "slackThreads.json"
+	slackPostMessageURL = "https://slack.com/api/chat.postMessage"
+)
+
+type SlackConfiguration struct {
+	WebhookURL string `yaml:"webhookUrl"`
+	Token string `yaml:"token"`
+	Channel string `yaml:"channel"`
+	ThreadExits bool `yaml:"threadExits"`
+	Template string `yaml:"template"`
+	template *template.Template
+}
+
+type slackMessage struct {
+	Channel string `json:"channel,omitempty"`
+	Text string `json:"text"`
+	ThreadTimestamp string `json:"thread_ts,omitempty"`
+}
+
+type slackResponse struct {
+	OK bool `json:"ok"`
+	Error string `json:"error"`
+	Timestamp string `json:"ts"`
+}
+
+func (c *SlackConfiguration) validate() {
+	if (c.WebhookURL == "") == (c.Token == "") {
+		commons.Fatalf("Slack notifications require either an incoming webhook URL or a bot token")
+	}
+	if c.Token != "" && c.Channel == "" {
+		commons.Fatalf("Slack notifications with a bot token require a channel")
+	}
+	if c.ThreadExits && c.Token == "" {
+		commons.Fatalf("Threading Slack exit notifications requires a bot token")
+	}
+	c.template = parseTemplate("Slack", c.Template)
+}
+
+func (c *SlackConfiguration) getName() string {
+	return "Slack"
+}
+
+func (c *SlackConfiguration) notifiesErrors() bool {
+	return false
+}
+
+// Incoming webhooks don't return the timestamp of the message, which is why threads are only supported with bot tokens
+func (c *SlackConfiguration) send(n notification) error {
+	text, err := n.format(c.template, n.getCompactText)
+	if err != nil {
+		return err
+	}
+	message := slackMessage{
+		Text: text,
+	}
+	if c.WebhookURL != "" {
+		data, err := json.Marshal(message)
+		if err != nil {
+			return err
+		}
+		return postHook(c.WebhookURL, data)
+	}
+	message.Channel = c.Channel
+	threads := map[string]string{}
+	threadsPath := filepath.Join(dataDirectory, slackThreadsFile)
+	if c.ThreadExits {
+		readJSON(threadsPath, &threads)
+		if n.Exit != nil || n.Stop != nil {
+			message.ThreadTimestamp = threads[n.Result.Strategy]
+		}
+	}
+	timestamp, err := c.postMessage(message)
+	if err != nil {
+		return err
+	}
+	if !c.ThreadExits || n.NearMiss || n.Stop != nil || n.Result.Suppressed || n.Result.Position != nil {
+		return nil
+	}
+	if n.Exit != nil {
+		delete(threads, n.Result.Strategy)
+	} else {
+		threads[n.Result.Strategy] = timestamp
+	}
+	writeJSON(threadsPath, threads)
+	return nil
+}
+
+func (c *SlackConfiguration) postMessage(message slackMessage) (string, error) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return "", err
+	}
+	request, err := http.NewRequest(http.MethodPost, slackPostMessageURL, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	request.Header.Set("Authorization", "Bearer " + c.Token)
+	client := http.Client{
+		Timeout: hookTimeout,
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	var output slackResponse
+	err = json.NewDecoder(response.Body).Decode(&output)
+	if err != nil {
+		return "", fmt.Errorf("invalid response: %v", err)
+	}
+	if !output.OK {
+		return "", fmt.Errorf("Slack API error: %s", output.Error)
+	}
+	return output.Timestamp, nil
+}
\ No newline at end of file
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	slippageFile = "slippage.json"
+)
+
+var (
+	calibratedSlippage map[string]float64
+	calibratedSlippageOnce sync.Once
+)
+
+func runCalibrate(arguments []string) {
+	flags := flag.NewFlagSet("calibrate", flag.ExitOnError)
+	minExecutions := flags.Int("minExecutions", 10, "Minimum number of recorded executions required to calibrate a currency")
+	flags.Parse(arguments)
+	loadConfiguration()
+	fills := loadFills()
+	slippage := map[string][]float64{}
+	for _, fill := range fills {
+		slippage[fill.Currency] = append(slippage[fill.Currency], fill.getSlippage())
+	}
+	currencies := []string{}
+	for currency := range slippage {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	calibrated := loadCalibratedSlippage()
+	fmt.Printf("\n")
+	for _, currency := range currencies {
+		values := slippage[currency]
+		fmt.Printf("%s:\n", currency)
+		fmt.Printf("\tExecutions: %d\n", len(values))
+		if len(values) < *minExecutions {
+			fmt.Printf("\tNot enough executions, keeping %.3f%%\n\n", getSlippage(currency))
+			continue
+		}
+		mean := getMean(values)
+		fmt.Printf("\tMean slippage: %.3f%%\n", mean)
+		fmt.Printf("\tStandard deviation: %.3f%%\n", getStandardDeviation(values))
+		calibrated[currency] = max(mean, 0)
+		fmt.Printf("\tCalibrated slippage: %.3f%%\n\n", calibrated[currency])
+	}
+	writeJSON(filepath.Join(dataDirectory, slippageFile), calibrated)
+}
+
+// Adverse slippage in percent, negative values mean the fill was better than the price at the time of the signal
+func (e *event) getSlippage() float64 {
+	slippage := (e.Price / e.SignalPrice - 1.0) * percent
+	if e.Buy {
+		return slippage
+	} else {
+		return -slippage
+	}
+}
+
+func loadFills() []event {
+	fills := []event{}
+	for _, fill := range loadState().fills {
+		if fill.SignalPrice > 0 {
+			fills = append(fills, fill)
+		}
+	}
+	if len(fills) == 0 {
+		commons.Fatalf("No recorded fills found in the event log")
+	}
+	return fills
+}
+
+func loadCalibratedSlippage() map[string]float64 {
+	calibrated := map[string]float64{}
+	readJSON(filepath.Join(dataDirectory, slippageFile), &calibrated)
+	return calibrated
+}
+
+// Calibrated values take precedence over the slippage from the configuration file
+func getSlippage(currency string) float64 {
+	calibratedSlippageOnce.Do(func () {
+		calibratedSlippage = loadCalibratedSlippage()
+	})
+	slippage, exists := calibratedSlippage[currency]
+	if exists {
+		return slippage
+	}
+	return configuration.Slippage
+}
+
+func (s *Strategy) getSlippageFill(price float64, entry bool) float64 {
+	slippage := getSlippage(s.Currency) / percent
+	if s.Up == entry {
+		return price * (1.0 + slippage)
+	} else {
+		return price * (1.0 - slippage)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	// A single GSM-7 segment, longer messages are split and billed as multiple messages
+	defaultSMSLength = 160
+)
+
+type SMSConfiguration struct {
+	AccountSID string `yaml:"accountSid"`
+	AuthToken string `yaml:"authToken"`
+	From string `yaml:"from"`
+	To []string `yaml:"to"`
+	MaxLength int `yaml:"maxLength"`
+	Template string `yaml:"template"`
+	template *template.Template
+}
+
+func (c *SMSConfiguration) validate() {
+	if c.AccountSID == "" || c.AuthToken == "" || c.From == "" {
+		commons.Fatalf("SMS notifications require a Twilio account SID, auth token and sender number")
+	}
+	if len(c.To) == 0 {
+		commons.Fatalf("SMS notifications require at least one recipient")
+	}
+	if c.MaxLength < 0 {
+		commons.Fatalf("Invalid maximum SMS length: %d", c.MaxLength)
+	}
+	c.template = parseTemplate("SMS", c.Template)
+}
+
+func (c *SMSConfiguration) getName() string {
+	return "SMS"
+}
+
+func (c *SMSConfiguration) notifiesErrors() bool {
+	return false
+}
+
+// The most important information comes first so that truncation only affects the details
+func (n *notification) getSMSText() string {
+	r := n.Result
+	side := "UP"
+	if !r.Up {
+		side = "DOWN"
+	}
+	if n.Exit != nil {
+		return fmt.Sprintf("EXIT %s %s %.4f %+.2f%% %s %s", r.Currency, side, n.Exit.Price, n.Exit.Returns * percent, n.Exit.Reason, r.Strategy)
+	}
+	if n.Stop != nil {
+		return fmt.Sprintf("STOP %s %s %.4f %s %s", r.Currency, side, n.Stop.Price, n.Stop.Reason, r.Strategy)
+	}
+	kind := "SIGNAL"
+	if n.NearMiss {
+		kind = "NEAR"
+	}
+	text := fmt.Sprintf("%s %s %s %.4f", kind, r.Currency, side, r.CurrentPrice)
+	if r.Momentum != nil {
+		text += fmt.Sprintf(" m%+.2f%%", *r.Momentum)
+	}
+	if r.Suppressed {
+		text += " SUPPRESSED"
+	}
+	return text + " " + r.Strategy
+}
+
+func (c *SMSConfiguration) send(n notification) error {
+	text, err := n.format(c.template, n.getSMSText)
+	if err != nil {
+		return err
+	}
+	maxLength := c.MaxLength
+	if maxLength == 0 {
+		maxLength = defaultSMSLength
+	}
+	runes := []rune(text)
+	if len(runes) > maxLength {
+		text = string(runes[:maxLength])
+	}
+	requestURL := fmt.Sprintf("https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json", c.AccountSID)
+	client := http.Client{
+		Timeout: hookTimeout,
+	}
+	for _, recipient := range c.To {
+		form := url.Values{}
+		form.Set("From", c.From)
+		form.Set("To", recipient)
+		form.Set("Body", text)
+		request, err := http.NewRequest(http.MethodPost, requestURL, strings.NewReader(form.Encode()))
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.SetBasicAuth(c.AccountSID, c.AuthToken)
+		response, err := client.Do(request)
+		if err != nil {
+			return err
+		}
+		response.Body.Close()
+		if response.StatusCode < 200 || response.StatusCode >= 300 {
+			return fmt.Errorf("Twilio returned status %d for %s", response.StatusCode, recipient)
+		}
+	}
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	defaultSparklineHours = 24
+	sparklineWidth = 48
+)
+
+var (
+	sparklineHours int
+	sparklineLevels = []rune("▁▂▃▄▅▆▇█")
+)
+
+func validateSparklineHours() {
+	if sparklineHours < 0 || sparklineHours >= candleLimit / candlesPerHour {
+		commons.Fatalf("Invalid number of sparkline hours: %d", sparklineHours)
+	}
+}
+
+// Samples the closes of the last hours into at most sparklineWidth points, each being the last close of its bucket
+func getSparklineCloses(records []ohlcRecord) []float64 {
+	if sparklineHours == 0 || len(records) == 0 {
+		return nil
+	}
+	latest := records[len(records) - 1].timestamp
+	window := records[findRecord(records, latest.Add(-time.Duration(sparklineHours) * time.Hour)):]
+	points := min(len(window), sparklineWidth)
+	closes := make([]float64, points)
+	for i := range closes {
+		closes[i] = window[(i + 1) * len(window) / points - 1].close
+	}
+	return closes
+}
+
+func formatSparkline(closes []float64) string {
+	low := slices.Min(closes)
+	high := slices.Max(closes)
+	var builder strings.Builder
+	for _, close := range closes {
+		level := 0
+		if high > low {
+			level = int((close - low) / (high - low) * float64(len(sparklineLevels) - 1) + 0.5)
+		}
+		builder.WriteRune(sparklineLevels[level])
+	}
+	return builder.String()
+}
\ No newline at end of file
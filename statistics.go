@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+
+	"github.com/fatih/color"
+)
+
+const (
+	bootstrapSamples = 10000
+	confidenceLevel = 0.95
+	significanceLevel = 0.05
+)
+
+type bootstrapResult struct {
+	lower float64
+	upper float64
+	pValue float64
+}
+
+func getMean(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+func getStandardDeviation(values []float64) float64 {
+	mean := getMean(values)
+	sum := 0.0
+	for _, value := range values {
+		sum += math.Pow(value - mean, 2.0)
+	}
+	return math.Sqrt(sum / float64(len(values) - 1))
+}
+
+// Percentile bootstrap of the mean, with the p-value obtained by resampling returns shifted to a mean of zero
+func getBootstrap(returns []float64) bootstrapResult {
+	mean := getMean(returns)
+	means := make([]float64, bootstrapSamples)
+	extreme := 0
+	for i := range means {
+		sum := 0.0
+		for range returns {
+			sum += returns[rand.IntN(len(returns))]
+		}
+		sampleMean := sum / float64(len(returns))
+		means[i] = sampleMean
+		if math.Abs(sampleMean - mean) >= math.Abs(mean) {
+			extreme++
+		}
+	}
+	sort.Float64s(means)
+	tail := (1.0 - confidenceLevel) / 2.0
+	return bootstrapResult{
+		lower: getPercentile(means, tail),
+		upper: getPercentile(means, 1.0 - tail),
+		pValue: float64(extreme) / float64(bootstrapSamples),
+	}
+}
+
+func getPercentile(sortedValues []float64, p float64) float64 {
+	index := int(math.Round(p * float64(len(sortedValues) - 1)))
+	return sortedValues[index]
+}
+
+func (b *bootstrapResult) print() {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	fmt.Printf("\tMean return %.0f%% CI: %+.2f%% to %+.2f%%\n", confidenceLevel * percent, b.lower * percent, b.upper * percent)
+	pValueString := fmt.Sprintf("%.4f", b.pValue)
+	if b.pValue < significanceLevel {
+		pValueString = green(pValueString)
+	} else {
+		pValueString = red(pValueString + " (not significant)")
+	}
+	fmt.Printf("\tBootstrap p-value: %s\n", pValueString)
+}
\ No newline at end of file
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Returns the trigger price and the break-even stop price, which covers fees on entry and exit
+func (s *Strategy) getBreakEven(entry float64) (float64, float64) {
+	fees := 2.0 * configuration.Fees / percent
+	move := *s.BreakEven / percent
+	if s.Up {
+		return entry * (1.0 + move), entry * (1.0 + fees)
+	} else {
+		return entry * (1.0 - move), entry * (1.0 - fees)
+	}
+}
+
+func (s *Strategy) breakEvenTriggered(entry float64, record ohlcRecord) bool {
+	if s.BreakEven == nil {
+		return false
+	}
+	trigger, _ := s.getBreakEven(entry)
+	if s.Up {
+		return record.high >= trigger
+	} else {
+		return record.low <= trigger
+	}
+}
+
+func updateBreakEvenStops(filter string) {
+	for i := range configuration.Strategies {
+		strategy := &configuration.Strategies[i]
+		if !strategy.matchesFilter(filter) || strategy.BreakEven == nil || strategy.Order == nil {
+			continue
+		}
+		p := strategy.getPosition()
+		if p == nil {
+			continue
+		}
+		err := strategy.updateBreakEvenStop(p)
+		if err != nil {
+			fmt.Fprintf(statusOutput, "%s: failed to move stop to break-even: %v\n", strategy.Name, err)
+		}
+	}
+}
+
+func (s *Strategy) updateBreakEvenStop(p *position) error {
+	records, err := loadRecords(s.Currency)
+	if err != nil {
+		return err
+	}
+	records, _ = filterRecords(records)
+	if len(records) == 0 {
+		return fmt.Errorf("no candles available for %s", s.Currency)
+	}
+	executor, err := s.newExecutor()
+	if err != nil {
+		return err
+	}
+	defer func () {
+		for _, request := range executor.DryRunRequests() {
+			fmt.Fprintf(statusOutput, "%s: dry run: %s\n", s.Name, request)
+		}
+	}()
+	return s.moveStopToBreakEven(executor, p, records)
+}
+
+// The stop is only moved once since the position stop is at break-even afterwards and stops are never loosened
+func (s *Strategy) moveStopToBreakEven(executor Executor, p *position, records []ohlcRecord) error {
+	triggered := false
+	for _, record := range records {
+		if !record.timestamp.Before(p.EntryTime.Truncate(candleInterval)) && s.breakEvenTriggered(p.EntryPrice, record) {
+			triggered = true
+			break
+		}
+	}
+	if !triggered {
+		return nil
+	}
+	filters, err := executor.GetSymbolFilters(s.Currency)
+	if err != nil {
+		return err
+	}
+	_, stop := s.getBreakEven(p.EntryPrice)
+	stop, _ = strconv.ParseFloat(filters.formatPrice(stop), 64)
+	latest := records[len(records) - 1].close
+	if s.Up && (stop <= p.Stop || stop >= latest) {
+		return nil
+	}
+	if !s.Up && ((p.Stop > 0 && stop >= p.Stop) || stop <= latest) {
+		return nil
+	}
+	previous := p.Stop
+	err = s.replaceStop(executor, p, stop, exitBreakEven)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		return nil
+	}
+	fmt.Fprintf(statusOutput, "%s: moved stop from %.4f to break-even at %.4f\n", s.Name, previous, stop)
+	s.sendStopNotification(previous, stop, exitBreakEven)
+	return nil
+}
\ No newline at end of file
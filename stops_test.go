@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Records the requests of the strategy instead of submitting them to an exchange
+type fakeExecutor struct {
+	filters symbolFilters
+	balances map[string]float64
+	orders map[string]Order
+	openOrders []Order
+	placed []OrderRequest
+	cancelled []string
+	calls []string
+	failType string
+	// Orders are filled at this price immediately if it is set
+	fillPrice float64
+	nextID int
+}
+
+func (e *fakeExecutor) Configure(symbol string, order *OrderConfiguration) error {
+	return nil
+}
+
+func (e *fakeExecutor) GetSymbolFilters(symbol string) (symbolFilters, error) {
+	return e.filters, nil
+}
+
+func (e *fakeExecutor) GetBalance(asset string) (float64, error) {
+	return e.balances[asset], nil
+}
+
+func (e *fakeExecutor) GetBalances() ([]Balance, error) {
+	return nil, nil
+}
+
+func (e *fakeExecutor) GetOpenOrders(symbol string) ([]Order, error) {
+	return e.openOrders, nil
+}
+
+func (e *fakeExecutor) GetOrder(symbol string, orderID string) (Order, error) {
+	order, exists := e.orders[orderID]
+	if !exists {
+		return Order{}, fmt.Errorf("unknown order %s", orderID)
+	}
+	return order, nil
+}
+
+func (e *fakeExecutor) FindOrder(symbol string, clientOrderID string) (*Order, error) {
+	for _, order := range e.orders {
+		if order.ClientOrderID == clientOrderID {
+			return &order, nil
+		}
+	}
+	return nil, nil
+}
+
+func (e *fakeExecutor) PlaceOrder(request OrderRequest) (Order, error) {
+	if request.Type == e.failType {
+		return Order{}, fmt.Errorf("rejected %s order", request.Type)
+	}
+	e.calls = append(e.calls, "place " + request.Type)
+	e.placed = append(e.placed, request)
+	e.nextID++
+	order := Order{
+		ID: fmt.Sprintf("%d", e.nextID),
+		ClientOrderID: request.ClientOrderID,
+		Symbol: request.Symbol,
+		Type: request.Type,
+		Status: orderStatusNew,
+		Buy: request.Buy,
+		Price: request.Price,
+		Quantity: request.Quantity,
+	}
+	if e.fillPrice > 0 {
+		order.Status = orderStatusFilled
+		order.ExecutedQuantity = request.Quantity
+		order.AveragePrice = e.fillPrice
+	}
+	if e.orders == nil {
+		e.orders = map[string]Order{}
+	}
+	e.orders[order.ID] = order
+	return order, nil
+}
+
+func (e *fakeExecutor) PlaceOCO(stopLoss OrderRequest, takeProfit OrderRequest) ([]Order, error) {
+	return nil, fmt.Errorf("OCO orders are not supported")
+}
+
+func (e *fakeExecutor) CancelOrder(symbol string, orderID string) error {
+	e.calls = append(e.calls, "cancel " + orderID)
+	e.cancelled = append(e.cancelled, orderID)
+	return nil
+}
+
+func (e *fakeExecutor) ClosePosition(p *position, clientOrderID string) (Order, error) {
+	return e.PlaceOrder(OrderRequest{
+		Symbol: p.Currency,
+		ClientOrderID: clientOrderID,
+		Buy: !p.Up,
+		Type: orderMarket,
+		Quantity: p.Quantity,
+		ReduceOnly: true,
+	})
+}
+
+func (e *fakeExecutor) GetPosition(symbol string) (ExchangePosition, error) {
+	return ExchangePosition{}, nil
+}
+
+func (e *fakeExecutor) SupportsOCO() bool {
+	return false
+}
+
+func (e *fakeExecutor) DryRunRequests() []string {
+	return nil
+}
+
+// Runs the test in an empty data directory with a fresh engine state
+func setupEventLog(t *testing.T) {
+	t.Chdir(t.TempDir())
+	currentState = nil
+	t.Cleanup(func () {
+		currentState = nil
+		if journal != nil {
+			journal.Close()
+			journal = nil
+		}
+		journalOnce = sync.Once{}
+	})
+}
+
+func getBreakEvenStrategy() *Strategy {
+	breakEven := 1.0
+	configuration = &Configuration{
+		Fees: 0.1,
+	}
+	return &Strategy{
+		Name: "break-even",
+		Currency: "BTCUSDT",
+		Up: true,
+		BreakEven: &breakEven,
+		Order: &OrderConfiguration{},
+	}
+}
+
+func TestMoveStopToBreakEven(t *testing.T) {
+	entryTime := time.Date(2025, 3, 3, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		high float64
+		close float64
+		moves int
+	}{
+		{"triggered", 101.5, 101.2, 1},
+		{"not triggered", 100.9, 100.5, 0},
+		{"retraced below break-even", 101.5, 100.1, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func (t *testing.T) {
+			setupEventLog(t)
+			s := getBreakEvenStrategy()
+			appendEvent(event{
+				Time: entryTime,
+				Type: eventPositionOpened,
+				Strategy: s.Name,
+				Currency: s.Currency,
+				Up: s.Up,
+				Price: 100,
+				Quantity: 1,
+			})
+			appendEvent(event{
+				Time: entryTime,
+				Type: eventOrder,
+				Strategy: s.Name,
+				Currency: s.Currency,
+				Up: s.Up,
+				OrderID: "stop",
+				Price: 95,
+				Quantity: 1,
+				Reason: exitStopLoss,
+			})
+			appendEvent(event{
+				Time: entryTime,
+				Type: eventPositionUpdated,
+				Strategy: s.Name,
+				Currency: s.Currency,
+				Up: s.Up,
+				Stop: 95,
+			})
+			records := []ohlcRecord{
+				{timestamp: entryTime, open: 100, high: 100.2, low: 99.8, close: 100},
+				{timestamp: entryTime.Add(candleInterval), open: 100, high: test.high, low: 99.9, close: test.close},
+			}
+			executor := &fakeExecutor{
+				filters: symbolFilters{tickSize: "0.01"},
+			}
+			// The second update must not move the stop again
+			for range 2 {
+				err := s.moveStopToBreakEven(executor, s.getPosition(), records)
+				if err != nil {
+					t.Fatalf("failed to move stop: %v", err)
+				}
+			}
+			if len(executor.placed) != test.moves {
+				t.Fatalf("expected %d stop orders, got %d", test.moves, len(executor.placed))
+			}
+			updates := 0
+			for _, e := range loadEvents() {
+				if e.Type == eventPositionUpdated && e.Reason == exitBreakEven {
+					updates++
+				}
+			}
+			if updates != test.moves {
+				t.Fatalf("expected %d break-even updates, got %d", test.moves, updates)
+			}
+			if test.moves == 0 {
+				return
+			}
+			if executor.placed[0].Type != orderStopLoss || executor.placed[0].StopPrice != 100.2 {
+				t.Errorf("unexpected stop order: %+v", executor.placed[0])
+			}
+			if len(executor.cancelled) != 1 || executor.cancelled[0] != "stop" {
+				t.Errorf("expected the previous stop to be cancelled once, got %v", executor.cancelled)
+			}
+			if stop := s.getPosition().Stop; stop != 100.2 {
+				t.Errorf("expected the position stop to be 100.2, got %.4f", stop)
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	dataDirectory = "data"
+)
+
+func readJSON(path string, output any) bool {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false
+	}
+	if err != nil {
+		commons.Fatalf("Failed to read %s: %v", path, err)
+	}
+	err = json.Unmarshal(data, output)
+	if err != nil {
+		commons.Fatalf("Failed to parse %s: %v", path, err)
+	}
+	return true
+}
+
+func writeJSON(path string, value any) {
+	data, err := json.MarshalIndent(value, "", "\t")
+	if err != nil {
+		commons.Fatalf("Failed to serialize %s: %v", path, err)
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		commons.Fatalf("Failed to create directory for %s: %v", path, err)
+	}
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		commons.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
\ No newline at end of file
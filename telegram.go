@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"text/template"
+
+	"github.com/encratite/commons"
+)
+
+type TelegramConfiguration struct {
+	Token string `yaml:"token"`
+	ChatID string `yaml:"chatId"`
+	Template string `yaml:"template"`
+	template *template.Template
+}
+
+type telegramMessage struct {
+	ChatID string `json:"chat_id"`
+	Text string `json:"text"`
+}
+
+func (c *TelegramConfiguration) validate() {
+	if c.Token == "" || c.ChatID == "" {
+		commons.Fatalf("Telegram notifications require a bot token and a chat ID")
+	}
+	c.template = parseTemplate("Telegram", c.Template)
+}
+
+func (c *TelegramConfiguration) getName() string {
+	return "Telegram"
+}
+
+func (c *TelegramConfiguration) notifiesErrors() bool {
+	return false
+}
+
+func (c *TelegramConfiguration) send(n notification) error {
+	text, err := n.format(c.template, n.getText)
+	if err != nil {
+		return err
+	}
+	message := telegramMessage{
+		ChatID: c.ChatID,
+		Text: text,
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	// Errors would otherwise contain the URL and therefore the bot token
+	requestURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", c.Token)
+	err = postHook(requestURL, data)
+	var urlError *url.Error
+	if errors.As(err, &urlError) {
+		return urlError.Err
+	}
+	return err
+}
\ No newline at end of file
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+// Intervals supported by the Binance kline endpoints that line up with the daily cache files
+var binanceIntervals = map[string]time.Duration{
+	"5m": 5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h": time.Hour,
+	"2h": 2 * time.Hour,
+	"4h": 4 * time.Hour,
+	"6h": 6 * time.Hour,
+	"8h": 8 * time.Hour,
+	"12h": 12 * time.Hour,
+	"1d": 24 * time.Hour,
+}
+
+type TimeframeConfiguration struct {
+	Interval string `yaml:"interval"`
+	Offset int `yaml:"offset"`
+	GreaterThan *float64 `yaml:"greaterThan"`
+	LessThan *float64 `yaml:"lessThan"`
+}
+
+type timeframeResult struct {
+	Interval string `json:"interval"`
+	Offset int `json:"offset"`
+	Momentum *float64 `json:"momentum,omitempty"`
+	Match bool `json:"match"`
+}
+
+var (
+	timeframeRecords = map[string]referenceSeries{}
+	timeframeLock sync.Mutex
+)
+
+func getIntervalDuration(interval string) time.Duration {
+	return binanceIntervals[interval]
+}
+
+func (c *TimeframeConfiguration) validate(strategy string) {
+	_, exists := binanceIntervals[c.Interval]
+	if !exists {
+		commons.Fatalf("Invalid timeframe interval for strategy %s: %s", strategy, c.Interval)
+	}
+	if c.Offset <= 0 || c.Offset >= candleLimit {
+		commons.Fatalf("Invalid timeframe offset for strategy %s", strategy)
+	}
+	if c.GreaterThan == nil && c.LessThan == nil {
+		commons.Fatalf("Missing timeframe momentum constraint for strategy %s", strategy)
+	}
+}
+
+func (c *TimeframeConfiguration) matches(momentum float64) bool {
+	match := true
+	if c.GreaterThan != nil {
+		match = match && momentum > *c.GreaterThan
+	}
+	if c.LessThan != nil {
+		match = match && momentum < *c.LessThan
+	}
+	return match
+}
+
+func (c *TimeframeConfiguration) getLookback() time.Duration {
+	return time.Duration(c.Offset + 1) * getIntervalDuration(c.Interval)
+}
+
+func getTimeframeKey(currency string, interval string) string {
+	return currency + "/" + interval
+}
+
+// Each timeframe is downloaded as a separate candle series since the 5m candles only cover a few days
+func (s *Strategy) loadLiveTimeframes() error {
+	for _, timeframe := range s.Timeframes {
+		parameters := map[string]string{
+			"symbol": s.Currency,
+			"interval": timeframe.Interval,
+			"limit": strconv.Itoa(candleLimit),
+			"endTime": getCandleEndTime(),
+		}
+		records, err := downloadRecords(parameters)
+		if err != nil {
+			return err
+		}
+		timeframeLock.Lock()
+		timeframeRecords[getTimeframeKey(s.Currency, timeframe.Interval)] = referenceSeries{
+			records: records,
+		}
+		timeframeLock.Unlock()
+	}
+	return nil
+}
+
+func (s *Strategy) loadHistoricalTimeframes(from time.Time, to time.Time) {
+	for _, timeframe := range s.Timeframes {
+		key := getTimeframeKey(s.Currency, timeframe.Interval)
+		seriesFrom := from.Add(-timeframe.getLookback())
+		timeframeLock.Lock()
+		series, exists := timeframeRecords[key]
+		if exists && !series.from.IsZero() && !series.from.After(seriesFrom) && !series.to.Before(to) {
+			timeframeLock.Unlock()
+			continue
+		}
+		timeframeLock.Unlock()
+		records := loadHistoricalInterval(s.Currency, timeframe.Interval, seriesFrom, to)
+		timeframeLock.Lock()
+		timeframeRecords[key] = referenceSeries{
+			from: seriesFrom,
+			to: to,
+			records: records,
+		}
+		timeframeLock.Unlock()
+	}
+}
+
+// Only bars that have closed by the entry time are used, the momentum is measured from the open of the bar offset bars before the latest one
+func (c *TimeframeConfiguration) getMomentum(currency string, entryTime time.Time) (float64, bool) {
+	timeframeLock.Lock()
+	records := timeframeRecords[getTimeframeKey(currency, c.Interval)].records
+	timeframeLock.Unlock()
+	interval := getIntervalDuration(c.Interval)
+	latestIndex := findRecord(records, entryTime.Add(-interval).Add(time.Nanosecond)) - 1
+	anchorIndex := latestIndex - c.Offset + 1
+	if anchorIndex < 0 {
+		return 0, false
+	}
+	latest := records[latestIndex]
+	if entryTime.Sub(latest.timestamp) > 2 * interval {
+		return 0, false
+	}
+	return (latest.close / records[anchorIndex].open - 1.0) * percent, true
+}
+
+func (s *Strategy) getTimeframeMatch(entryTime time.Time, result *EvaluationResult) bool {
+	match := true
+	for _, timeframe := range s.Timeframes {
+		timeframeMatch := false
+		output := timeframeResult{
+			Interval: timeframe.Interval,
+			Offset: timeframe.Offset,
+		}
+		momentum, ok := timeframe.getMomentum(s.Currency, entryTime)
+		if ok {
+			timeframeMatch = timeframe.matches(momentum)
+			output.Momentum = &momentum
+		}
+		output.Match = timeframeMatch
+		match = match && timeframeMatch
+		if result != nil {
+			result.Timeframes = append(result.Timeframes, output)
+		}
+	}
+	return match
+}
\ No newline at end of file
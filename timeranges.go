@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+// Ranges cover the entry times from their start up to but not including their end and wrap around midnight if the start follows the end
+type TimeRangeConfiguration struct {
+	From commons.SerializableDuration `yaml:"from"`
+	To commons.SerializableDuration `yaml:"to"`
+	IntervalMinutes int `yaml:"intervalMinutes"`
+}
+
+func (c *TimeRangeConfiguration) validate(strategy string) {
+	isValid := func (t time.Duration) bool {
+		return t >= 0 && t <= 24 * time.Hour && t % candleInterval == 0
+	}
+	if !isValid(c.From.Duration) || !isValid(c.To.Duration) || c.From.Duration == c.To.Duration {
+		commons.Fatalf("Invalid time range for strategy %s", strategy)
+	}
+	if c.IntervalMinutes < 0 || time.Duration(c.IntervalMinutes) * time.Minute % candleInterval != 0 {
+		commons.Fatalf("Invalid time range interval for strategy %s", strategy)
+	}
+}
+
+func (c *TimeRangeConfiguration) getInterval() time.Duration {
+	if c.IntervalMinutes == 0 {
+		return time.Hour
+	}
+	return time.Duration(c.IntervalMinutes) * time.Minute
+}
+
+func (c *TimeRangeConfiguration) getLength() time.Duration {
+	if c.From.Duration > c.To.Duration {
+		return c.To.Duration + 24 * time.Hour - c.From.Duration
+	}
+	return c.To.Duration - c.From.Duration
+}
+
+func (c *TimeRangeConfiguration) getTimesOfDay() []time.Duration {
+	times := []time.Duration{}
+	end := c.From.Duration + c.getLength()
+	for t := c.From.Duration; t < end; t += c.getInterval() {
+		times = append(times, t % (24 * time.Hour))
+	}
+	return times
+}
+
+// Returns the start of the range containing the local time, which may be on the previous day for ranges that wrap around midnight
+func (c *TimeRangeConfiguration) getStart(local time.Time) (time.Time, bool) {
+	timeOfDay := time.Duration(local.Hour()) * time.Hour + time.Duration(local.Minute()) * time.Minute
+	day := local
+	switch {
+	case c.From.Duration < c.To.Duration:
+		if timeOfDay < c.From.Duration || timeOfDay >= c.To.Duration {
+			return time.Time{}, false
+		}
+	case timeOfDay >= c.From.Duration:
+	case timeOfDay < c.To.Duration:
+		day = local.AddDate(0, 0, -1)
+	default:
+		return time.Time{}, false
+	}
+	from := c.From.Duration
+	return time.Date(day.Year(), day.Month(), day.Day(), int(from.Hours()), int(from.Minutes()) % 60, 0, 0, local.Location()).UTC(), true
+}
+
+func (c *TimeRangeConfiguration) String() string {
+	return fmt.Sprintf("%s-%s", commons.GetTimeOfDayString(c.From.Duration), commons.GetTimeOfDayString(c.To.Duration))
+}
+
+// Entries within a time range share the momentum anchor of the entry at the start of the range
+func (s *Strategy) getAnchorTime(entryTime time.Time) time.Time {
+	local := entryTime.In(s.getLocation())
+	for i := range s.TimeRanges {
+		start, ok := s.TimeRanges[i].getStart(local)
+		if ok {
+			return start.Add(-s.Offset.Duration)
+		}
+	}
+	return entryTime.Add(-s.Offset.Duration)
+}
\ No newline at end of file
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"slices"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const defaultTolerance = time.Hour
+
+// Weekdays and times are interpreted in the timezone of the strategy, which defaults to the global one and then to UTC
+func (s *Strategy) normalizeTimezone(defaultTimezone string) {
+	name := s.Timezone
+	if name == "" {
+		name = defaultTimezone
+	}
+	if name == "" {
+		return
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		commons.Fatalf("Invalid timezone for strategy %s: %s", s.Name, name)
+	}
+	s.location = location
+}
+
+func (s *Strategy) getLocation() *time.Location {
+	if s.location == nil {
+		return time.UTC
+	}
+	return s.location
+}
+
+func (s *Strategy) isEntryDay(day time.Time) bool {
+	return s.isTradingDay(day) && s.matchesCalendar(day)
+}
+
+// Entry times are resolved on the local calendar day so that they follow the DST transitions of the timezone
+func (s *Strategy) getEntryTimes(day time.Time) []time.Time {
+	if !s.isEntryDay(day) {
+		return nil
+	}
+	entryTimes := []time.Time{}
+	for _, t := range s.getTimesOfDay() {
+		entryTime := time.Date(day.Year(), day.Month(), day.Day(), int(t.Hours()), int(t.Minutes()) % 60, 0, 0, s.getLocation()).UTC()
+		if !slices.ContainsFunc(entryTimes, entryTime.Equal) {
+			entryTimes = append(entryTimes, entryTime)
+		}
+	}
+	for i := range s.MarketTimes {
+		entryTime := s.MarketTimes[i].getEntryTime(day)
+		if !slices.ContainsFunc(entryTimes, entryTime.Equal) {
+			entryTimes = append(entryTimes, entryTime)
+		}
+	}
+	slices.SortFunc(entryTimes, time.Time.Compare)
+	return entryTimes
+}
+
+func (s *Strategy) getTimesOfDay() []time.Duration {
+	if s.schedule != nil {
+		return s.schedule.getTimesOfDay()
+	}
+	times := []time.Duration{}
+	for _, t := range s.Times {
+		times = append(times, t.Duration)
+	}
+	for i := range s.TimeRanges {
+		times = append(times, s.TimeRanges[i].getTimesOfDay()...)
+	}
+	return times
+}
+
+func (s *Strategy) getTolerance() time.Duration {
+	if s.ToleranceMinutes == 0 {
+		return defaultTolerance
+	}
+	return time.Duration(s.ToleranceMinutes) * time.Minute
+}
+
+// An entry is pending if the current time falls within the tolerance window preceding it
+func (s *Strategy) getPendingEntry(now time.Time) (time.Time, bool) {
+	// Entry times of exchanges in other timezones may fall on the adjacent day
+	for _, day := range s.getLocalDays(now.AddDate(0, 0, -1), now.Add(s.getTolerance()).AddDate(0, 0, 1)) {
+		for _, entryTime := range s.getEntryTimes(day) {
+			if now.Before(entryTime) && !now.Before(entryTime.Add(-s.getTolerance())) {
+				return entryTime, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func isTimeOfDay(t time.Time, timeOfDay time.Duration) bool {
+	return t.Hour() == int(timeOfDay.Hours()) && t.Minute() == int(timeOfDay.Minutes()) % 60
+}
+
+// Local calendar days overlapping the UTC range
+func (s *Strategy) getLocalDays(from time.Time, to time.Time) []time.Time {
+	location := s.getLocation()
+	local := from.In(location)
+	days := []time.Time{}
+	for day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location); day.Before(to); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
\ No newline at end of file
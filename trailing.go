@@ -0,0 +1,168 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	defaultATRHours = 14
+	defaultManageInterval = time.Minute
+	exitTrailingStop = "trailing-stop"
+)
+
+type TrailingConfiguration struct {
+	Percentage *float64 `yaml:"percentage"`
+	ATR *float64 `yaml:"atr"`
+	ATRHours int `yaml:"atrHours"`
+}
+
+func (c *TrailingConfiguration) validate(strategy string) {
+	if (c.Percentage == nil) == (c.ATR == nil) {
+		commons.Fatalf("Trailing stop of strategy %s requires either a percentage or an ATR multiple", strategy)
+	}
+	if c.Percentage != nil && (*c.Percentage <= 0 || *c.Percentage >= percent) {
+		commons.Fatalf("Invalid trailing stop percentage for strategy %s", strategy)
+	}
+	if c.ATR != nil && *c.ATR <= 0 {
+		commons.Fatalf("Invalid trailing stop ATR multiple for strategy %s", strategy)
+	}
+	if c.ATRHours < 0 || c.ATRHours >= candleLimit / candlesPerHour {
+		commons.Fatalf("Invalid trailing stop ATR period for strategy %s", strategy)
+	}
+}
+
+func (c *TrailingConfiguration) getATRHours() int {
+	if c.ATRHours == 0 {
+		return defaultATRHours
+	}
+	return c.ATRHours
+}
+
+func runManage(arguments []string) {
+	flags := flag.NewFlagSet("manage", flag.ExitOnError)
+	strategyFilter := flags.String("strategy", "", "Only manage positions of strategies whose names match this filter")
+	interval := flags.Duration("interval", defaultManageInterval, "Interval between updates of the trailing and break-even stops")
+	flags.BoolVar(&dryRun, "dry-run", false, "Print the orders that would be placed without submitting them")
+	flags.Parse(arguments)
+	if *interval <= 0 {
+		commons.Fatalf("Invalid interval: %s", *interval)
+	}
+	loadConfiguration()
+	for {
+		checkRiskGuard()
+		syncEntryOrders(*strategyFilter)
+		syncProtectiveOrders(*strategyFilter)
+		if suspensionReason == "" {
+			updateTrailingStops(*strategyFilter)
+			updateBreakEvenStops(*strategyFilter)
+		}
+		next := time.Now().Truncate(*interval).Add(*interval)
+		time.Sleep(time.Until(next))
+	}
+}
+
+func updateTrailingStops(filter string) {
+	for i := range configuration.Strategies {
+		strategy := &configuration.Strategies[i]
+		if !strategy.matchesFilter(filter) || strategy.Trailing == nil || strategy.Order == nil {
+			continue
+		}
+		p := strategy.getPosition()
+		if p == nil {
+			continue
+		}
+		err := strategy.updateTrailingStop(p)
+		if err != nil {
+			fmt.Fprintf(statusOutput, "%s: failed to update trailing stop: %v\n", strategy.Name, err)
+		}
+	}
+}
+
+// Stops only ever move in the direction of the position, they are never loosened when the price retraces
+func (s *Strategy) updateTrailingStop(p *position) error {
+	records, err := loadRecords(s.Currency)
+	if err != nil {
+		return err
+	}
+	records, _ = filterRecords(records)
+	if len(records) == 0 {
+		return fmt.Errorf("no candles available for %s", s.Currency)
+	}
+	extreme := math.NaN()
+	for _, record := range records {
+		if record.timestamp.Before(p.EntryTime.Truncate(candleInterval)) {
+			continue
+		}
+		if s.Up && (math.IsNaN(extreme) || record.high > extreme) {
+			extreme = record.high
+		} else if !s.Up && (math.IsNaN(extreme) || record.low < extreme) {
+			extreme = record.low
+		}
+	}
+	if math.IsNaN(extreme) {
+		return nil
+	}
+	var distance float64
+	if s.Trailing.Percentage != nil {
+		distance = extreme * *s.Trailing.Percentage / percent
+	} else {
+		atr, err := getATR(records, s.Trailing.getATRHours())
+		if err != nil {
+			return err
+		}
+		distance = *s.Trailing.ATR * atr
+	}
+	executor, err := s.newExecutor()
+	if err != nil {
+		return err
+	}
+	defer func () {
+		for _, request := range executor.DryRunRequests() {
+			fmt.Fprintf(statusOutput, "%s: dry run: %s\n", s.Name, request)
+		}
+	}()
+	filters, err := executor.GetSymbolFilters(s.Currency)
+	if err != nil {
+		return err
+	}
+	stop := extreme - distance
+	if !s.Up {
+		stop = extreme + distance
+	}
+	stop, _ = strconv.ParseFloat(filters.formatPrice(stop), 64)
+	latest := records[len(records) - 1].close
+	if s.Up && (stop <= p.Stop || stop >= latest) {
+		return nil
+	}
+	if !s.Up && ((p.Stop > 0 && stop >= p.Stop) || stop <= latest) {
+		return nil
+	}
+	previous := p.Stop
+	err = s.replaceStop(executor, p, stop, exitTrailingStop)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(statusOutput, "%s: moved trailing stop from %.4f to %.4f\n", s.Name, previous, stop)
+	return nil
+}
+
+// The average true range is calculated from hourly bars aggregated from the candles
+func getATR(records []ohlcRecord, hours int) (float64, error) {
+	bars := getHourlyBars(records)
+	if len(bars) < hours + 1 {
+		return 0, fmt.Errorf("not enough candles to calculate the ATR over %d hours", hours)
+	}
+	total := 0.0
+	for i := len(bars) - hours; i < len(bars); i++ {
+		previousClose := bars[i - 1].close
+		trueRange := math.Max(bars[i].high - bars[i].low, math.Max(math.Abs(bars[i].high - previousClose), math.Abs(bars[i].low - previousClose)))
+		total += trueRange
+	}
+	return total / float64(hours), nil
+}
\ No newline at end of file
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+type VolatilityConfiguration struct {
+	Hours int `yaml:"hours"`
+	MinATR *float64 `yaml:"minAtr"`
+	MaxATR *float64 `yaml:"maxAtr"`
+}
+
+func (c *VolatilityConfiguration) validate(strategy string) {
+	if c.Hours < 0 {
+		commons.Fatalf("Invalid ATR period for strategy %s", strategy)
+	}
+	if c.MinATR == nil && c.MaxATR == nil {
+		commons.Fatalf("Missing ATR thresholds for strategy %s", strategy)
+	}
+	if c.MinATR != nil && *c.MinATR < 0 || c.MaxATR != nil && *c.MaxATR <= 0 {
+		commons.Fatalf("Invalid ATR thresholds for strategy %s", strategy)
+	}
+	if c.MinATR != nil && c.MaxATR != nil && *c.MinATR >= *c.MaxATR {
+		commons.Fatalf("Minimum ATR must be below the maximum ATR for strategy %s", strategy)
+	}
+}
+
+func (c *VolatilityConfiguration) getHours() int {
+	if c.Hours == 0 {
+		return defaultATRHours
+	}
+	return c.Hours
+}
+
+func (c *VolatilityConfiguration) getLookback() time.Duration {
+	return time.Duration(c.getHours() + 1) * time.Hour
+}
+
+// The ATR is expressed as a percentage of the latest close so that the thresholds work across currencies
+func (c *VolatilityConfiguration) getATR(records []ohlcRecord) (float64, bool, bool) {
+	atr, err := getATR(records, c.getHours())
+	if err != nil {
+		return 0, false, false
+	}
+	atr = atr / records[len(records) - 1].close * percent
+	match := true
+	if c.MinATR != nil && atr < *c.MinATR {
+		match = false
+	}
+	if c.MaxATR != nil && atr > *c.MaxATR {
+		match = false
+	}
+	return atr, match, true
+}
\ No newline at end of file
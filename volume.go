@@ -0,0 +1,71 @@
+package main
+
+import (
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	defaultVolumeAverageHours = 24
+)
+
+type VolumeConfiguration struct {
+	Hours int `yaml:"hours"`
+	AverageHours int `yaml:"averageHours"`
+	Multiple float64 `yaml:"multiple"`
+}
+
+func (c *VolumeConfiguration) validate(strategy string) {
+	if c.Hours < 0 || c.AverageHours < 0 {
+		commons.Fatalf("Invalid volume window for strategy %s", strategy)
+	}
+	if c.Multiple <= 0 {
+		commons.Fatalf("Invalid volume multiple for strategy %s", strategy)
+	}
+}
+
+// The volume is measured over the momentum window of the strategy unless specified otherwise
+func (c *VolumeConfiguration) getHours(s *Strategy) int {
+	if c.Hours == 0 {
+		return s.Offset.getHours()
+	}
+	return c.Hours
+}
+
+func (c *VolumeConfiguration) getAverageHours() int {
+	if c.AverageHours == 0 {
+		return defaultVolumeAverageHours
+	}
+	return c.AverageHours
+}
+
+func (c *VolumeConfiguration) getLookback(s *Strategy) time.Duration {
+	return time.Duration(c.getHours(s) + c.getAverageHours() + 1) * time.Hour
+}
+
+// Compares the volume of the window to the average volume of windows of the same length in the preceding hours
+func (c *VolumeConfiguration) getRatio(s *Strategy, records []ohlcRecord) (float64, bool) {
+	hours := time.Duration(c.getHours(s)) * time.Hour
+	averageHours := time.Duration(c.getAverageHours()) * time.Hour
+	end := records[len(records) - 1].timestamp.Add(candleInterval)
+	windowStart := end.Add(-hours)
+	averageStart := windowStart.Add(-averageHours)
+	if records[0].timestamp.After(averageStart) {
+		return 0, false
+	}
+	volume := 0.0
+	averageVolume := 0.0
+	for _, record := range records {
+		if !record.timestamp.Before(windowStart) {
+			volume += record.volume
+		} else if !record.timestamp.Before(averageStart) {
+			averageVolume += record.volume
+		}
+	}
+	averageVolume *= float64(hours) / float64(averageHours)
+	if averageVolume == 0 {
+		return 0, false
+	}
+	return volume / averageVolume, true
+}
\ No newline at end of file
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+type VWAPConfiguration struct {
+	Hours int `yaml:"hours"`
+	GreaterThan *float64 `yaml:"greaterThan"`
+	LessThan *float64 `yaml:"lessThan"`
+}
+
+func (c *VWAPConfiguration) validate(strategy string) {
+	if c.Hours < 0 {
+		commons.Fatalf("Invalid VWAP window for strategy %s", strategy)
+	}
+	if c.GreaterThan == nil && c.LessThan == nil {
+		commons.Fatalf("Missing VWAP deviation constraint for strategy %s", strategy)
+	}
+}
+
+func (c *VWAPConfiguration) getLookback() time.Duration {
+	if c.Hours == 0 {
+		return 25 * time.Hour
+	}
+	return time.Duration(c.Hours + 1) * time.Hour
+}
+
+// Without a rolling window the VWAP is anchored to the start of the UTC day like a session VWAP
+func (c *VWAPConfiguration) getDeviation(records []ohlcRecord) (float64, bool, bool) {
+	latest := records[len(records) - 1]
+	start := latest.timestamp.Truncate(24 * time.Hour)
+	if c.Hours > 0 {
+		start = latest.timestamp.Add(candleInterval - time.Duration(c.Hours) * time.Hour)
+		if records[0].timestamp.After(start) {
+			return 0, false, false
+		}
+	}
+	turnover := 0.0
+	volume := 0.0
+	for _, record := range records[findRecord(records, start):] {
+		typicalPrice := (record.high + record.low + record.close) / 3.0
+		turnover += typicalPrice * record.volume
+		volume += record.volume
+	}
+	if volume == 0 {
+		return 0, false, false
+	}
+	deviation := (latest.close / (turnover / volume) - 1.0) * percent
+	match := true
+	if c.GreaterThan != nil {
+		match = match && deviation > *c.GreaterThan
+	}
+	if c.LessThan != nil {
+		match = match && deviation < *c.LessThan
+	}
+	return deviation, match, true
+}
\ No newline at end of file
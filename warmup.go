@@ -0,0 +1,48 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	candleLimit = 1000
+	// Limits the number of requests per evaluation to keep strategies with long lookbacks below the rate limits
+	maxCandlePages = 10
+	candlesPerHour = int(time.Hour / candleInterval)
+)
+
+func (s *Strategy) getLookbackHours() int {
+	hours := s.Offset.getHours()
+	if s.Consensus != nil {
+		hours = max(hours, slices.Max(s.Consensus.Horizons))
+	}
+	// The anchor of the last entry of a time range precedes it by the length of the range
+	for i := range s.TimeRanges {
+		hours = max(hours, int(math.Ceil((s.Offset.Duration + s.TimeRanges[i].getLength()).Hours())))
+	}
+	for _, lookback := range s.Momentum {
+		hours = max(hours, lookback.Offset.getHours())
+	}
+	hours = max(hours, s.getIndicatorLookbackHours())
+	if s.Conditions != nil {
+		hours = max(hours, int(math.Ceil(s.Conditions.getLookback(s).Hours())))
+	}
+	return hours
+}
+
+// Number of candles required before the latest one for all of the conditions of a strategy to be defined
+func (s *Strategy) getWarmUpCandles() int {
+	return (s.getLookbackHours() + 1) * candlesPerHour
+}
+
+func (s *Strategy) validateWarmUp() {
+	required := s.getWarmUpCandles()
+	available := maxCandlePages * candleLimit - 1
+	if required > available {
+		commons.Fatalf("Strategy %s requires %d candles for its warm-up period but only %d are available", s.Name, required, available)
+	}
+}
\ No newline at end of file
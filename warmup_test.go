@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+// Serves candles with a steadily rising price in the format of the kline endpoint, honoring the interval, the limit and the end time
+func serveCandles(t *testing.T, from time.Time, to time.Time) *atomic.Int64 {
+	requests := &atomic.Int64{}
+	server := httptest.NewServer(http.HandlerFunc(func (writer http.ResponseWriter, request *http.Request) {
+		requests.Add(1)
+		query := request.URL.Query()
+		interval := getIntervalDuration(query.Get("interval"))
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		endTime, _ := strconv.ParseInt(query.Get("endTime"), 10, 64)
+		end := time.UnixMilli(endTime).UTC()
+		if end.After(to) {
+			end = to
+		}
+		candles := [][]any{}
+		for timestamp := end.Truncate(interval); !timestamp.Before(from) && len(candles) < limit; timestamp = timestamp.Add(-interval) {
+			price := func (t time.Time) string {
+				return strconv.FormatFloat(100.0 + 0.01 * float64(t.Sub(from) / candleInterval), 'f', 2, 64)
+			}
+			close := timestamp.Add(interval)
+			candle := []any{timestamp.UnixMilli(), price(timestamp), price(close), price(timestamp), price(close), "10.0"}
+			candles = append([][]any{candle}, candles...)
+		}
+		json.NewEncoder(writer).Encode(candles)
+	}))
+	previous := klinesURL
+	klinesURL = server.URL
+	t.Cleanup(func () {
+		server.Close()
+		klinesURL = previous
+	})
+	return requests
+}
+
+func TestLongOffsetEvaluates(t *testing.T) {
+	setupEventLog(t)
+	now := time.Date(2025, 3, 5, 13, 58, 0, 0, time.UTC)
+	requests := serveCandles(t, now.AddDate(0, 0, -30), now)
+	greaterThan := 1.0
+	weekdays := []commons.SerializableWeekday{}
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		weekdays = append(weekdays, commons.SerializableWeekday{Weekday: weekday})
+	}
+	configuration = &Configuration{
+		Strategies: []Strategy{
+			{
+				Name: "weekly",
+				Currency: "BTCUSDT",
+				Offset: MomentumOffset{7 * 24 * time.Hour},
+				GreaterThan: &greaterThan,
+				Up: true,
+				Weekdays: weekdays,
+				Times: []commons.SerializableDuration{{Duration: 14 * time.Hour}},
+			},
+		},
+	}
+	configuration.validate()
+	evaluationTime = now
+	t.Cleanup(func () {
+		evaluationTime = time.Time{}
+	})
+	s := &configuration.Strategies[0]
+	result, err := s.evaluate()
+	if err != nil {
+		t.Fatalf("failed to evaluate strategy: %v", err)
+	}
+	if result == nil || result.Momentum == nil {
+		t.Fatalf("expected the momentum over 7 days to be available")
+	}
+	anchorTime := s.getAnchorTime(result.EntryTime)
+	if !result.MomentumTime.Equal(anchorTime) {
+		t.Errorf("expected the momentum anchor at %s, got %s", anchorTime, result.MomentumTime)
+	}
+	if !result.MomentumMatch || !result.Signal {
+		t.Errorf("expected a signal with momentum %+.2f%%", *result.Momentum)
+	}
+	if requests.Load() < 3 {
+		t.Errorf("expected the candles to be loaded in multiple pages, got %d requests", requests.Load())
+	}
+}
+
+func TestLongPercentileEvaluates(t *testing.T) {
+	setupEventLog(t)
+	now := time.Date(2025, 3, 5, 13, 58, 0, 0, time.UTC)
+	requests := serveCandles(t, now.AddDate(0, 0, -100), now)
+	greaterThan := 0.0
+	lessThan := 50.0
+	weekdays := []commons.SerializableWeekday{}
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		weekdays = append(weekdays, commons.SerializableWeekday{Weekday: weekday})
+	}
+	configuration = &Configuration{
+		Strategies: []Strategy{
+			{
+				Name: "percentile",
+				Currency: "BTCUSDT",
+				Offset: MomentumOffset{time.Hour},
+				GreaterThan: &greaterThan,
+				Up: true,
+				Weekdays: weekdays,
+				Times: []commons.SerializableDuration{{Duration: 14 * time.Hour}},
+				Percentile: &PercentileConfiguration{
+					Offset: 1,
+					Hours: 90 * 24,
+					LessThan: &lessThan,
+				},
+			},
+		},
+	}
+	configuration.validate()
+	evaluationTime = now
+	t.Cleanup(func () {
+		evaluationTime = time.Time{}
+		percentileRecords = map[string]referenceSeries{}
+	})
+	result, err := configuration.Strategies[0].evaluate()
+	if err != nil {
+		t.Fatalf("failed to evaluate strategy: %v", err)
+	}
+	if result == nil || result.PercentileRank == nil {
+		t.Fatalf("expected the percentile rank over 90 days to be available")
+	}
+	// The bar of the hour in progress covers fewer candles of the rising price than the closed ones, so it ranks lowest
+	if *result.PercentileRank != 0 || !result.PercentileMatch {
+		t.Errorf("expected a matching percentile rank of 0, got %.2f", *result.PercentileRank)
+	}
+	if requests.Load() < 3 {
+		t.Errorf("expected the hourly bars to be loaded in multiple pages, got %d requests", requests.Load())
+	}
+}
\ No newline at end of file
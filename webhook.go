@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	notificationSignal = "signal"
+	notificationNearMiss = "nearMiss"
+	notificationExit = "exit"
+	notificationStop = "stop"
+	notificationError = "error"
+)
+
+type WebhookConfiguration struct {
+	URL string `yaml:"url"`
+	Secret string `yaml:"secret"`
+}
+
+type signalDocument struct {
+	Type string `json:"type"`
+	Strategy string `json:"strategy"`
+	Symbol string `json:"symbol"`
+	Side string `json:"side"`
+	Price float64 `json:"price,omitempty"`
+	Momentum *float64 `json:"momentum,omitempty"`
+	Offset string `json:"offset,omitempty"`
+	GreaterThan *float64 `json:"greaterThan,omitempty"`
+	LessThan *float64 `json:"lessThan,omitempty"`
+	Suppressed bool `json:"suppressed,omitempty"`
+	SuppressedBy string `json:"suppressedBy,omitempty"`
+	Time time.Time `json:"time"`
+	MomentumTime *time.Time `json:"momentumTime,omitempty"`
+	EntryWindow *time.Time `json:"entryWindow,omitempty"`
+	Exit *exitNotification `json:"exit,omitempty"`
+	Stop *stopNotification `json:"stop,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+func (c *WebhookConfiguration) validate() {
+	if c.URL == "" {
+		commons.Fatalf("Webhook notifications require a URL")
+	}
+}
+
+func (c *WebhookConfiguration) getName() string {
+	return "Webhook"
+}
+
+func (c *WebhookConfiguration) notifiesErrors() bool {
+	return true
+}
+
+func (n *notification) getType() string {
+	if n.Result.Error != "" {
+		return notificationError
+	} else if n.NearMiss {
+		return notificationNearMiss
+	} else if n.Exit != nil {
+		return notificationExit
+	} else if n.Stop != nil {
+		return notificationStop
+	} else {
+		return notificationSignal
+	}
+}
+
+func (n *notification) getDocument() signalDocument {
+	r := n.Result
+	document := signalDocument{
+		Type: n.getType(),
+		Strategy: r.Strategy,
+		Symbol: r.Currency,
+		Side: r.getSide(),
+		Price: r.CurrentPrice,
+		Momentum: r.Momentum,
+		Offset: r.Offset,
+		GreaterThan: r.GreaterThan,
+		LessThan: r.LessThan,
+		Suppressed: r.Suppressed,
+		SuppressedBy: r.SuppressedBy,
+		Time: r.Time,
+		MomentumTime: r.MomentumTime,
+		Exit: n.Exit,
+		Stop: n.Stop,
+		Error: r.Error,
+	}
+	if document.Type == notificationSignal || document.Type == notificationNearMiss {
+		entryWindow := r.getEntryWindow()
+		document.EntryWindow = &entryWindow
+	}
+	return document
+}
+
+// The signature is an HMAC-SHA256 of the timestamp header, a dot and the body so that receivers can reject replayed requests
+func (c *WebhookConfiguration) send(n notification) error {
+	data, err := json.Marshal(n.getDocument())
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest(http.MethodPost, c.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if c.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(c.Secret))
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(data)
+		request.Header.Set("X-Coinage-Timestamp", timestamp)
+		request.Header.Set("X-Coinage-Signature", "sha256=" + hex.EncodeToString(mac.Sum(nil)))
+	}
+	client := http.Client{
+		Timeout: hookTimeout,
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("status %d", response.StatusCode)
+	}
+	return nil
+}
\ No newline at end of file
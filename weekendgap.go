@@ -0,0 +1,69 @@
+package main
+
+import (
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+const (
+	gapFridayClose = "fridayClose"
+	gapSundayOpen = "sundayOpen"
+	weekendGapHours = 72
+)
+
+type WeekendGapConfiguration struct {
+	Reference string `yaml:"reference"`
+	GreaterThan *float64 `yaml:"greaterThan"`
+	LessThan *float64 `yaml:"lessThan"`
+}
+
+func (c *WeekendGapConfiguration) validate(strategy string) {
+	if c.Reference != "" && c.Reference != gapFridayClose && c.Reference != gapSundayOpen {
+		commons.Fatalf("Unknown weekend gap reference for strategy %s: %s", strategy, c.Reference)
+	}
+	if c.GreaterThan == nil && c.LessThan == nil {
+		commons.Fatalf("Missing weekend gap constraint for strategy %s", strategy)
+	}
+}
+
+// The gap can only be determined until the end of the Monday following the weekend
+func (c *WeekendGapConfiguration) getLookback() time.Duration {
+	return (weekendGapHours + 1) * time.Hour
+}
+
+// Measures the change from the close on Friday or the open on Sunday of the most recent weekend to the latest close
+func (c *WeekendGapConfiguration) getGap(records []ohlcRecord) (float64, bool, bool) {
+	latest := records[len(records) - 1]
+	saturday := latest.timestamp.Truncate(24 * time.Hour)
+	for saturday.Weekday() != time.Saturday {
+		saturday = saturday.AddDate(0, 0, -1)
+	}
+	if latest.timestamp.Sub(saturday) >= weekendGapHours * time.Hour {
+		return 0, false, false
+	}
+	var reference float64
+	if c.Reference == gapSundayOpen {
+		sunday := saturday.AddDate(0, 0, 1)
+		index := findRecord(records, sunday)
+		if index >= len(records) - 1 || !records[index].timestamp.Before(sunday.Add(time.Hour)) {
+			return 0, false, false
+		}
+		reference = records[index].open
+	} else {
+		index := findRecord(records, saturday) - 1
+		if index < 0 || records[index].timestamp.Before(saturday.Add(-time.Hour)) {
+			return 0, false, false
+		}
+		reference = records[index].close
+	}
+	gap := (latest.close / reference - 1.0) * percent
+	match := true
+	if c.GreaterThan != nil {
+		match = match && gap > *c.GreaterThan
+	}
+	if c.LessThan != nil {
+		match = match && gap < *c.LessThan
+	}
+	return gap, match, true
+}
\ No newline at end of file
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"time"
+
+	"github.com/encratite/commons"
+)
+
+type ZScoreConfiguration struct {
+	Period int `yaml:"period"`
+	IntervalMinutes int `yaml:"intervalMinutes"`
+	GreaterThan *float64 `yaml:"greaterThan"`
+	LessThan *float64 `yaml:"lessThan"`
+}
+
+func (c *ZScoreConfiguration) validate(strategy string) {
+	if c.Period < 2 {
+		commons.Fatalf("Invalid z-score period for strategy %s", strategy)
+	}
+	if c.GreaterThan == nil && c.LessThan == nil {
+		commons.Fatalf("Missing z-score constraint for strategy %s", strategy)
+	}
+	validateIndicatorInterval(c.IntervalMinutes, "z-score", strategy)
+}
+
+func (c *ZScoreConfiguration) getLookback() time.Duration {
+	return time.Duration(c.Period + 1) * getIndicatorInterval(c.IntervalMinutes)
+}
+
+// Distance of the latest close from the rolling mean in standard deviations, with the latest bar being part of the window
+func (c *ZScoreConfiguration) getZScore(records []ohlcRecord) (float64, bool, bool) {
+	bars := getBars(records, getIndicatorInterval(c.IntervalMinutes))
+	if len(bars) < c.Period {
+		return 0, false, false
+	}
+	closes := []float64{}
+	for _, bar := range bars[len(bars) - c.Period:] {
+		closes = append(closes, bar.close)
+	}
+	mean, deviation := getMeanDeviation(closes)
+	if deviation == 0 {
+		return 0, false, false
+	}
+	zScore := (closes[len(closes) - 1] - mean) / deviation
+	match := true
+	if c.GreaterThan != nil {
+		match = match && zScore > *c.GreaterThan
+	}
+	if c.LessThan != nil {
+		match = match && zScore < *c.LessThan
+	}
+	return zScore, match, true
+}
\ No newline at end of file